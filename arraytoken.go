@@ -0,0 +1,83 @@
+package masker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultArrayToken is the path segment matching every array index, e.g. "$.items[].id",
+// when WithArrayToken isn't used.
+const DefaultArrayToken = "[]"
+
+// WithArrayToken sets the path segment matching every array index, e.g. "[*]" for paths
+// such as "$.items[*].id" in place of "$.items[].id", which keeps working. With WithRegexPaths,
+// the array indexes of the paths the regular expressions are matched against are collapsed
+// to token instead of "[]".
+// The token must be written in brackets and can't be mistaken for an index, a range or a quoted key,
+// so it can't hold digits alone, ':', quotes or brackets. An invalid token is returned as an error
+// wrapping ErrInvalidPath by every mask call. Paths compiled with CompilePaths keep using "[]".
+func WithArrayToken(token string) option {
+	return func(m *masker) {
+		m.arrayToken = token
+	}
+}
+
+// validateArrayToken checks that token can be told apart from the other bracket segments.
+func validateArrayToken(token string) error {
+	if token == DefaultArrayToken {
+		return nil
+	}
+	content, ok := strings.CutPrefix(token, "[")
+	if ok {
+		content, ok = strings.CutSuffix(content, "]")
+	}
+	if !ok || content == "" {
+		return withKind(ErrInvalidPath, fmt.Errorf("invalid array token %q: must be written in brackets, e.g. [*]", token))
+	}
+	if strings.ContainsAny(content, `[]:'"`) || strings.Trim(content, "0123456789") == "" {
+		return withKind(ErrInvalidPath, fmt.Errorf("invalid array token %q: conflicts with indexes, ranges or quoted keys", token))
+	}
+	return nil
+}
+
+// replaceArrayToken replaces the array token in path with "[]", leaving quoted keys as they are.
+func replaceArrayToken(path, token string) string {
+	if token == DefaultArrayToken || !strings.Contains(path, token) {
+		return path
+	}
+	var sb strings.Builder
+	var quote byte
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\' && i+1 < len(path):
+			sb.WriteByte(c)
+			i++
+			c = path[i]
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '\'' || c == '"') && i > 0 && path[i-1] == '[':
+			quote = c
+		case strings.HasPrefix(path[i:], token):
+			sb.WriteString(DefaultArrayToken)
+			i += len(token) - 1
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// newArrayTokenPathSet builds a pathSet from maskPaths written with the array token,
+// keeping the paths as they are written for the errors of WithStrictPaths.
+func newArrayTokenPathSet(maskPaths []string, token string) pathSet {
+	patterns := make([]pathPattern, 0, len(maskPaths))
+	for _, path := range maskPaths {
+		pattern := compilePattern(replaceArrayToken(path, token))
+		pattern.path = path
+		patterns = append(patterns, pattern)
+	}
+	return newPatternSet(patterns)
+}
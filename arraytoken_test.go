@@ -0,0 +1,152 @@
+package masker
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithArrayToken(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with array elements",
+			input:     `{"items":[{"id":1,"card":"4111"},{"id":2,"card":"5500"}]}`,
+			maskPaths: []string{"$.items[*].card"},
+			expected:  `{"items":[{"id":1,"card":"[REDACTED]"},{"id":2,"card":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with nested arrays",
+			input:     `{"matrix":[[1,2],[3]],"other":[4]}`,
+			maskPaths: []string{"$.matrix[*][*]"},
+			expected:  `{"matrix":[["[REDACTED]","[REDACTED]"],["[REDACTED]"]],"other":[4]}`,
+		},
+		{
+			name:      "Test with default token",
+			input:     `{"items":[1,2]}`,
+			maskPaths: []string{"$.items[]"},
+			expected:  `{"items":["[REDACTED]","[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with token in a quoted key",
+			input:     `{"a[*]":1,"a":[2]}`,
+			maskPaths: []string{"$['a[*]']"},
+			expected:  `{"a[*]":"[REDACTED]","a":[2]}`,
+		},
+		{
+			name:      "Test with path mask function",
+			input:     `{"items":[{"card":"4111"}]}`,
+			maskPaths: []string{"$.items[*].card"},
+			opts:      []option{WithMaskFuncForPath("$.items[*].card", func(field any) string { return "card" })},
+			expected:  `{"items":[{"card":"card"}]}`,
+		},
+		{
+			name:      "Test with conditional mask",
+			input:     `{"items":[{"type":"card","number":"4111"},{"type":"iban","number":"DE00"}]}`,
+			maskPaths: nil,
+			opts: []option{WithConditionalMask("$.items[*].number", func(node map[string]any) bool {
+				return node["type"] == "card"
+			})},
+			expected: `{"items":[{"type":"card","number":"[REDACTED]"},{"type":"iban","number":"DE00"}]}`,
+		},
+		{
+			name:      "Test with regex paths",
+			input:     `{"items":[{"id":1},{"id":2}]}`,
+			maskPaths: []string{`^\$\.items\[\*\]\.id$`},
+			opts:      []option{WithRegexPaths()},
+			expected:  `{"items":[{"id":"[REDACTED]"},{"id":"[REDACTED]"}]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			// the token is set after the other options, which must still use it
+			masker := NewMasker(tt.maskPaths, append(tt.opts, WithArrayToken("[*]"))...)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with call paths", func(t *testing.T) {
+		output, err := NewMasker(nil, WithArrayToken("[*]")).MaskWithPaths(`{"items":[1,2]}`, []string{"$.items[*]"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"items":["[REDACTED]","[REDACTED]"]}`, output)
+	})
+
+	t.Run("Test with strict paths", func(t *testing.T) {
+		_, err := NewMasker([]string{"$.items[*].card"}, WithArrayToken("[*]"), WithStrictPaths()).Mask(`{"other":[]}`)
+		assert.EqualError(t, err, "mask paths matched nothing: $.items[*].card")
+	})
+
+	t.Run("Test with invalid token", func(t *testing.T) {
+		_, err := NewMasker([]string{"$.a"}, WithArrayToken("*")).Mask(`{"a":1}`)
+		assert.True(t, errors.Is(err, ErrInvalidPath))
+	})
+}
+
+func TestWithArrayToken_optionPaths(t *testing.T) {
+	input := `{"Items":[{"Price":12.5,"Note":"call 555-1234","Kind":"card","Data":"x","Rows":"a,b"}]}`
+	opts := []option{
+		WithNumericBucket("$.items[*].price", 10),
+		WithRegexValueMask("$.items[*].note", regexp.MustCompile(`\d{3}-\d{4}`), "***"),
+		WithTypeTagRule("kind", "card", "data"),
+		WithCSVField("$.items[*].rows", []int{1}),
+		WithMaskFuncForPath("$.items[*].kind", func(field any) string {
+			return "kind"
+		}),
+	}
+	expected := `{"Items":[{"Price":10,"Note":"call ***","Kind":"kind","Data":"[REDACTED]","Rows":"a,[REDACTED]"}]}`
+
+	// the paths of the options are compiled once they are all applied, whatever their order
+	for _, opts := range [][]option{
+		append([]option{WithArrayToken("[*]"), WithFieldNameNormalizer(LowerCaseFieldName)}, opts...),
+		append(opts[:len(opts):len(opts)], WithArrayToken("[*]"), WithFieldNameNormalizer(LowerCaseFieldName)),
+	} {
+		masked, err := NewMasker([]string{"$.items[*].kind"}, opts...).Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, masked)
+	}
+}
+
+func TestValidateArrayToken(t *testing.T) {
+	testTable := []struct {
+		token     string
+		expectErr bool
+	}{
+		{token: "[]"},
+		{token: "[*]"},
+		{token: "[all]"},
+		{token: "*", expectErr: true},
+		{token: "[", expectErr: true},
+		{token: "[*", expectErr: true},
+		{token: "[1]", expectErr: true},
+		{token: "[1:2]", expectErr: true},
+		{token: "['a']", expectErr: true},
+		{token: "[[]]", expectErr: true},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.token, func(t *testing.T) {
+			err := validateArrayToken(tt.token)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
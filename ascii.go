@@ -0,0 +1,62 @@
+package masker
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// WithASCIIOnly makes the masked documents pure ASCII, the non-ASCII characters of their strings and keys
+// being escaped like "\u00e9" for "é", and those outside the Basic Multilingual Plane as UTF-16 surrogate pairs,
+// e.g. "\ud83d\ude00" for "😀". Bytes that aren't valid UTF-8 are escaped as "\ufffd", the replacement character.
+// It only changes how the masked documents are encoded, which decode to the same values,
+// and not which nodes are masked. It applies to Mask, MaskBytes and MaskReader among others,
+// including to the subtrees copied verbatim with WithRawPassthrough, but not to the values returned by MaskValue.
+func WithASCIIOnly() option {
+	return func(m *masker) {
+		m.isASCIIOnly = true
+	}
+}
+
+// encodeOutput returns the encoded JSON data as written to the output, escaped if WithASCIIOnly is used.
+func (m *masker) encodeOutput(data []byte) []byte {
+	if !m.isASCIIOnly {
+		return data
+	}
+	return escapeNonASCII(data)
+}
+
+// escapeNonASCII escapes the non-ASCII characters of the JSON data, which can only appear in its strings.
+// It returns data itself if it is ASCII.
+func escapeNonASCII(data []byte) []byte {
+	const hex = "0123456789abcdef"
+	i := 0
+	for i < len(data) && data[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(data) {
+		return data
+	}
+	escaped := make([]byte, i, len(data)+len(data)/2)
+	copy(escaped, data[:i])
+	writeRune := func(r rune) {
+		escaped = append(escaped, '\\', 'u', hex[r>>12&0xf], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+	}
+	for i < len(data) {
+		if data[i] < utf8.RuneSelf {
+			escaped = append(escaped, data[i])
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		if r > 0xffff {
+			r1, r2 := utf16.EncodeRune(r)
+			writeRune(r1)
+			writeRune(r2)
+		} else {
+			// invalid bytes are decoded as utf8.RuneError, the replacement character
+			writeRune(r)
+		}
+		i += size
+	}
+	return escaped
+}
@@ -0,0 +1,95 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithASCIIOnly(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with accented characters",
+			input:     `{"name":"José Müller","ssn":"123"}`,
+			maskPaths: []string{"$.ssn"},
+			expected:  `{"name":"Jos\u00e9 M\u00fcller","ssn":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with emoji",
+			input:     `{"status":"ok 😀","ssn":"123"}`,
+			maskPaths: []string{"$.ssn"},
+			expected:  `{"status":"ok \ud83d\ude00","ssn":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with keys and masked values",
+			input:     `{"città":"Roma","ssn":"x"}`,
+			maskPaths: []string{"$.ssn"},
+			opts:      []option{WithFixedMaskString("•••")},
+			expected:  `{"citt\u00e0":"Roma","ssn":"\u2022\u2022\u2022"}`,
+		},
+		{
+			name:      "Test with ASCII input",
+			input:     `{"name":"John","ssn":"123"}`,
+			maskPaths: []string{"$.ssn"},
+			expected:  `{"name":"John","ssn":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with indent",
+			input:     `{"name":"Zoë"}`,
+			maskPaths: []string{"$.ssn"},
+			opts:      []option{WithIndent("", " ")},
+			expected:  "{\n \"name\": \"Zo\\u00eb\"\n}",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]option{WithASCIIOnly()}, tt.opts...)
+			masker := NewMasker(tt.maskPaths, opts...)
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+
+			masked, err = NewMasker(tt.maskPaths, append(opts, WithRawPassthrough())...).Mask(tt.input)
+			assert.NoError(t, err)
+			var expected, actual any
+			assert.NoError(t, json.Unmarshal([]byte(tt.expected), &expected))
+			assert.NoError(t, json.Unmarshal([]byte(masked), &actual))
+			assert.Equal(t, expected, actual)
+			for _, r := range masked {
+				assert.Less(t, r, rune(128))
+			}
+		})
+	}
+
+	t.Run("Test with invalid UTF-8", func(t *testing.T) {
+		masked, err := NewMasker(nil, WithASCIIOnly(), WithRawPassthrough()).Mask("{\"name\":\"a\xffb\"}")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"a\ufffdb"}`, masked)
+	})
+
+	t.Run("Test with masking unchanged", func(t *testing.T) {
+		input := `{"user":{"name":"Élodie","email":"é@example.com"}}`
+		withASCII, err := NewMasker([]string{"$.user.email"}, WithASCIIOnly()).Mask(input)
+		assert.NoError(t, err)
+		without, err := NewMasker([]string{"$.user.email"}).Mask(input)
+		assert.NoError(t, err)
+		var a, b any
+		assert.NoError(t, json.Unmarshal([]byte(withASCII), &a))
+		assert.NoError(t, json.Unmarshal([]byte(without), &b))
+		assert.Equal(t, b, a)
+	})
+}
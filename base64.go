@@ -0,0 +1,62 @@
+package masker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// base64Encodings are the encodings tried in turn to decode the strings of WithBase64JSON.
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+}
+
+// WithBase64JSON makes the string values at paths be masked as the base64-encoded JSON documents
+// they hold, e.g. the payload of an envelope: the string is decoded, the JSON document is masked
+// with innerPaths and encoded back with the same base64 encoding, standard or URL-safe, padded or not.
+// Strings without padding whose length is a multiple of 4 are encoded back with padding.
+// innerPaths start from the root of the decoded document, e.g. "$.ssn" for the ssn member of the payload,
+// and the nodes they mask aren't reported by MaskWithReport, MaskWithVault or WithStrictPaths.
+// Strings that aren't base64-encoded JSON are kept as they are.
+// paths and innerPaths use the same syntax as the mask paths, regular expressions with WithRegexPaths.
+func WithBase64JSON(paths []string, innerPaths []string) option {
+	return func(m *masker) {
+		m.base64Paths, m.base64InnerPaths = paths, innerPaths
+	}
+}
+
+// isBase64JSON checks if the string at path holds a base64-encoded JSON document to mask, see WithBase64JSON.
+func (m *masker) isBase64JSON(path nodePath) bool {
+	return m.base64 != nil && m.base64.matches(path)
+}
+
+// maskBase64JSON masks the base64-encoded JSON document held by the string at path, returning it encoded.
+// Strings that aren't base64-encoded JSON are returned as they are.
+func (m *masker) maskBase64JSON(str string, state *maskState, path nodePath) (string, error) {
+	for _, encoding := range base64Encodings {
+		decoded, err := encoding.DecodeString(str)
+		if err != nil {
+			continue
+		}
+		value, err := decodeJSON(decoded, m.maxDepth, m.duplicates)
+		if err != nil {
+			break
+		}
+		inner := &maskState{ctx: state.ctx, maskPaths: m.base64Inner, matchers: m.matchers, isDeep: m.isDeepMask}
+		masked, err := m.maskWithPaths(reflect.ValueOf(value), inner, rootPath())
+		if err != nil {
+			return "", fmt.Errorf("failed to mask base64 JSON at path %s: %w", path, err)
+		}
+		if isDropped(masked) {
+			masked = nil
+		}
+		encoded, err := json.Marshal(masked)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal base64 JSON at path %s: %w", path, err)
+		}
+		return encoding.EncodeToString(encoded), nil
+	}
+	m.log("Keeping invalid base64 JSON", logActionKeep, path)
+	return str, nil
+}
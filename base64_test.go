@@ -0,0 +1,80 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBase64JSON(t *testing.T) {
+	payload := `{"ssn":"123","name":"John"}`
+	maskedPayload := `{"ssn":"[REDACTED]","name":"John"}`
+	// a payload whose encodings differ between the standard and URL-safe alphabets
+	urlPayload := `{"ssn":"???>>>"}`
+
+	testTable := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Test with standard encoding",
+			input:    `{"env":"` + base64.StdEncoding.EncodeToString([]byte(payload)) + `","ssn":"1"}`,
+			expected: `{"env":"` + base64.StdEncoding.EncodeToString([]byte(maskedPayload)) + `","ssn":"1"}`,
+		},
+		{
+			name:     "Test with unpadded encoding",
+			input:    `{"env":"` + base64.RawStdEncoding.EncodeToString([]byte(`{"ssn":"1234"}`)) + `"}`,
+			expected: `{"env":"` + base64.RawStdEncoding.EncodeToString([]byte(`{"ssn":"[REDACTED]"}`)) + `"}`,
+		},
+		{
+			name:     "Test with URL-safe encoding",
+			input:    `{"env":"` + base64.RawURLEncoding.EncodeToString([]byte(urlPayload)) + `"}`,
+			expected: `{"env":"` + base64.RawURLEncoding.EncodeToString([]byte(`{"ssn":"[REDACTED]"}`)) + `"}`,
+		},
+		{
+			name:     "Test with invalid base64",
+			input:    `{"env":"not base64!"}`,
+			expected: `{"env":"not base64!"}`,
+		},
+		{
+			name:     "Test with base64 that isn't JSON",
+			input:    `{"env":"` + base64.StdEncoding.EncodeToString([]byte("hello")) + `"}`,
+			expected: `{"env":"` + base64.StdEncoding.EncodeToString([]byte("hello")) + `"}`,
+		},
+		{
+			name:     "Test with non string value",
+			input:    `{"env":{"ssn":"123"}}`,
+			expected: `{"env":{"ssn":"123"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(nil, WithBase64JSON([]string{"$.env"}, []string{"$.ssn"}))
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		type envelope struct{ Data string }
+		encoded := base64.StdEncoding.EncodeToString([]byte(payload))
+		masked, err := NewMasker(nil, WithBase64JSON([]string{"$.Data"}, []string{"$.name"})).MaskValue(envelope{Data: encoded}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, envelope{Data: base64.StdEncoding.EncodeToString([]byte(`{"ssn":"123","name":"[REDACTED]"}`))}, masked)
+	})
+
+	t.Run("Test with invalid inner path", func(t *testing.T) {
+		_, err := NewMasker(nil, WithRegexPaths(), WithBase64JSON([]string{`^\$\.env$`}, []string{"("})).Mask(`{}`)
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+}
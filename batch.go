@@ -0,0 +1,67 @@
+package masker
+
+import "sync"
+
+// WithBatchConcurrency makes MaskBatch mask up to workers documents concurrently.
+// Documents are masked one after the other by default, or when workers is less than 2.
+func WithBatchConcurrency(workers int) option {
+	return func(m *masker) {
+		m.batchWorkers = workers
+	}
+}
+
+// MaskBatch masks every input JSON string independently with the provided maskPaths,
+// returning the masked documents and the errors at the same indexes as their inputs,
+// so an invalid document doesn't fail the whole batch: its masked document is empty
+// and its error is set, the errors of the valid documents being nil.
+// The paths are compiled once for the whole batch, and a configuration error, e.g. an invalid
+// regex path, is returned for every document. See WithBatchConcurrency to mask them concurrently.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+func (m *masker) MaskBatch(inputs []string, maskPaths []string) ([]string, []error) {
+	outputs := make([]string, len(inputs))
+	errs := make([]error, len(inputs))
+	template, err := m.newMaskState(maskPaths)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return outputs, errs
+	}
+	maskAt := func(i int) {
+		masked, err := m.mask([]byte(inputs[i]), template.clone())
+		outputs[i], errs[i] = string(masked), err
+	}
+
+	if m.batchWorkers < 2 {
+		for i := range inputs {
+			maskAt(i)
+		}
+		return outputs, errs
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < m.batchWorkers && w < len(inputs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				maskAt(i)
+			}
+		}()
+	}
+	for i := range inputs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return outputs, errs
+}
+
+// clone returns a new state with the same paths, for another mask call.
+func (s *maskState) clone() *maskState {
+	state := &maskState{ctx: s.ctx, maskPaths: s.maskPaths, matchers: s.matchers, isDeep: s.isDeep}
+	if s.matched != nil {
+		state.matched = make(map[string]bool)
+	}
+	return state
+}
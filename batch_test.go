@@ -0,0 +1,61 @@
+package masker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskBatch(t *testing.T) {
+	inputs := []string{
+		`{"ssn":"1","name":"a"}`,
+		`{"ssn":`,
+		`[{"ssn":"2"}]`,
+		`{"name":"b"}`,
+	}
+	expected := []string{`{"ssn":"[REDACTED]","name":"a"}`, "", `[{"ssn":"2"}]`, `{"name":"b"}`}
+
+	for _, workers := range []int{0, 3, 10} {
+		t.Run(fmt.Sprintf("Test with %d workers", workers), func(t *testing.T) {
+			outputs, errs := NewMasker([]string{"$.ssn"}, WithBatchConcurrency(workers)).MaskBatch(inputs, nil)
+			assert.Equal(t, expected, outputs)
+			assert.Len(t, errs, len(inputs))
+			assert.NoError(t, errs[0])
+			assert.ErrorIs(t, errs[1], ErrInvalidJSON)
+			assert.NoError(t, errs[2])
+			assert.NoError(t, errs[3])
+		})
+	}
+
+	t.Run("Test with many documents", func(t *testing.T) {
+		inputs := make([]string, 100)
+		for i := range inputs {
+			inputs[i] = fmt.Sprintf(`{"id":%d,"ssn":"%d"}`, i, i)
+		}
+		outputs, errs := NewMasker(nil, WithBatchConcurrency(4)).MaskBatch(inputs, []string{"$.ssn"})
+		for i := range inputs {
+			assert.NoError(t, errs[i])
+			assert.Equal(t, fmt.Sprintf(`{"id":%d,"ssn":"[REDACTED]"}`, i), outputs[i])
+		}
+	})
+
+	t.Run("Test with strict paths", func(t *testing.T) {
+		_, errs := NewMasker([]string{"$.ssn"}, WithStrictPaths()).MaskBatch([]string{`{"ssn":1}`, `{"name":1}`}, nil)
+		assert.NoError(t, errs[0])
+		assert.EqualError(t, errs[1], "mask paths matched nothing: $.ssn")
+	})
+
+	t.Run("Test with deep masked subtrees", func(t *testing.T) {
+		outputs, errs := NewMasker([]string{"$.user"}, WithDeepMaskSubtrees()).MaskBatch([]string{`{"user":{"a":1}}`}, nil)
+		assert.NoError(t, errs[0])
+		assert.Equal(t, `{"user":{"a":"[REDACTED]"}}`, outputs[0])
+	})
+
+	t.Run("Test with invalid paths", func(t *testing.T) {
+		outputs, errs := NewMasker(nil, WithRegexPaths()).MaskBatch([]string{`{}`, `{}`}, []string{"("})
+		assert.Equal(t, []string{"", ""}, outputs)
+		assert.ErrorIs(t, errs[0], ErrInvalidPath)
+		assert.ErrorIs(t, errs[1], ErrInvalidPath)
+	})
+}
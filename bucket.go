@@ -0,0 +1,101 @@
+package masker
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// numericBucket rounds the numbers at a path down to a multiple of a bucket size, see WithNumericBucket.
+type numericBucket struct {
+	pattern pathPattern
+	bucket  float64
+}
+
+// WithNumericBucket replaces the numbers at path with the multiple of bucket below them,
+// e.g. ages as 30 for 37 with a bucket of 10, so they can still be aggregated without being exact.
+// Numbers keep their type: json.Number for JSON documents, and the Go type of the numbers passed
+// to MaskValue, the bucketed value of an integer being truncated if bucket isn't an integer.
+// Negative numbers are rounded down as well, -37 becoming -40.
+// The numbers at path don't need to be matched by a mask path, which would mask them as a whole,
+// and aren't reported by MaskWithReport, MaskWithVault or WithStrictPaths. Other values at path are kept,
+// and a bucket that isn't positive leaves the numbers as they are.
+// path uses the path syntax, and the first WithNumericBucket whose path matches a number is used.
+func WithNumericBucket(path string, bucket float64) option {
+	return withOptionPath(path, func(m *masker, _ string, pattern pathPattern) {
+		m.buckets = append(m.buckets, numericBucket{pattern: pattern, bucket: bucket})
+	})
+}
+
+// numericBucket returns the bucket of the number at path, or nil, see WithNumericBucket.
+func (m *masker) numericBucket(input reflect.Value, path nodePath) *numericBucket {
+	if len(m.buckets) == 0 || !isNumber(input) {
+		return nil
+	}
+	path = normalizeKeys(path, m.normalizer)
+	for i := range m.buckets {
+		if m.buckets[i].pattern.match(path) {
+			return &m.buckets[i]
+		}
+	}
+	return nil
+}
+
+// isNumber checks if the value is a Go number or a json.Number.
+func isNumber(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return value.IsValid() && value.Type() == jsonNumberType
+}
+
+// bucketNumber returns the number at path rounded down to a multiple of the bucket of b.
+func (m *masker) bucketNumber(b *numericBucket, value reflect.Value, path nodePath) any {
+	m.log("Bucketing number", logActionMask, path)
+	return b.round(value)
+}
+
+// round returns the number rounded down to a multiple of the bucket, of the type of value.
+// json.Number values that aren't valid numbers are returned as they are.
+func (b *numericBucket) round(value reflect.Value) any {
+	if b.bucket <= 0 {
+		return value.Interface()
+	}
+	floor := func(f float64) float64 {
+		return math.Floor(f/b.bucket) * b.bucket
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if b.bucket == math.Trunc(b.bucket) && b.bucket < math.MaxInt64 {
+			// integer arithmetic keeps the precision of the integers floats can't represent
+			v, size := value.Int(), int64(b.bucket)
+			bucketed := v - v%size
+			if v%size < 0 {
+				bucketed -= size
+			}
+			return reflect.ValueOf(bucketed).Convert(value.Type()).Interface()
+		}
+		return reflect.ValueOf(int64(floor(float64(value.Int())))).Convert(value.Type()).Interface()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if b.bucket == math.Trunc(b.bucket) && b.bucket < math.MaxUint64 {
+			v, size := value.Uint(), uint64(b.bucket)
+			return reflect.ValueOf(v - v%size).Convert(value.Type()).Interface()
+		}
+		return reflect.ValueOf(uint64(floor(float64(value.Uint())))).Convert(value.Type()).Interface()
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(floor(value.Float())).Convert(value.Type()).Interface()
+	}
+	number := value.Interface().(json.Number)
+	if v, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+		return json.Number(strconv.FormatInt(b.round(reflect.ValueOf(v)).(int64), 10))
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return number
+	}
+	return json.Number(strconv.FormatFloat(floor(f), 'f', -1, 64))
+}
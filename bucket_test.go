@@ -0,0 +1,102 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithNumericBucket(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		path     string
+		bucket   float64
+		expected string
+	}{
+		{
+			name:     "Test with integer",
+			input:    `{"age":37,"name":"John"}`,
+			path:     "$.age",
+			bucket:   10,
+			expected: `{"age":30,"name":"John"}`,
+		},
+		{
+			name:     "Test with float",
+			input:    `{"users":[{"score":7.8},{"score":2.2}]}`,
+			path:     "$.users[].score",
+			bucket:   2.5,
+			expected: `{"users":[{"score":7.5},{"score":0}]}`,
+		},
+		{
+			name:     "Test with negative number",
+			input:    `{"balance":-37}`,
+			path:     "$.balance",
+			bucket:   10,
+			expected: `{"balance":-40}`,
+		},
+		{
+			name:     "Test with exponent",
+			input:    `{"salary":1.234e5}`,
+			path:     "$.salary",
+			bucket:   1000,
+			expected: `{"salary":123000}`,
+		},
+		{
+			name:     "Test with large integer",
+			input:    `{"id":9007199254740993}`,
+			path:     "$.id",
+			bucket:   10,
+			expected: `{"id":9007199254740990}`,
+		},
+		{
+			name:     "Test with non numeric values",
+			input:    `{"age":"37","tags":[37],"none":null}`,
+			path:     "$.*",
+			bucket:   10,
+			expected: `{"age":"37","tags":[37],"none":null}`,
+		},
+		{
+			name:     "Test with non positive bucket",
+			input:    `{"age":37}`,
+			path:     "$.age",
+			bucket:   0,
+			expected: `{"age":37}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(nil, WithNumericBucket(tt.path, tt.bucket))
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with masked path", func(t *testing.T) {
+		masked, err := NewMasker([]string{"$.age"}, WithNumericBucket("$.*", 10)).Mask(`{"age":37,"weight":81}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"age":"[REDACTED]","weight":80}`, masked)
+	})
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		type person struct {
+			Age    int
+			Height uint8
+			Weight float32
+			Score  json.Number
+		}
+		masker := NewMasker(nil, WithNumericBucket("$.*", 10))
+		masked, err := masker.MaskValue(person{Age: 37, Height: 183, Weight: 81.5, Score: "42"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, person{Age: 30, Height: 180, Weight: 80, Score: "40"}, masked)
+	})
+}
@@ -0,0 +1,132 @@
+package masker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaskerBuilder configures a masker rule by rule, as an alternative to passing the mask paths
+// and the options to NewMasker, and validates the rules when building it:
+//
+//	m, err := NewMaskerBuilder().
+//		AddPath("$.user.ssn", "$.cards[].number").
+//		MaskObjectKeys("$.accounts.*").
+//		Exclude("$.cards[0].number").
+//		WithFunc("$.user.email", maskEmail).
+//		Build()
+//
+// The methods can be called in any order and each returns the builder.
+type MaskerBuilder struct {
+	maskPaths  []string
+	keepPaths  []string
+	exclusions []string
+	opts       []option
+}
+
+// NewMaskerBuilder returns a builder for a masker without any rule.
+func NewMaskerBuilder() *MaskerBuilder {
+	return &MaskerBuilder{}
+}
+
+// AddPath adds mask paths, matched nodes being masked, see NewMasker.
+func (b *MaskerBuilder) AddPath(paths ...string) *MaskerBuilder {
+	b.maskPaths = append(b.maskPaths, paths...)
+	return b
+}
+
+// MaskObjectKeys adds mask paths masking the keys of the nodes they match instead of their values,
+// e.g. "$.accounts.*" masks the keys of accounts like the mask path "$.accounts.*~".
+// Unlike WithMaskKeys, which masks the values under some keys, it masks the keys themselves.
+func (b *MaskerBuilder) MaskObjectKeys(paths ...string) *MaskerBuilder {
+	for _, path := range paths {
+		if !strings.HasSuffix(path, keySuffix) {
+			path += keySuffix
+		}
+		b.maskPaths = append(b.maskPaths, path)
+	}
+	return b
+}
+
+// KeepOnly adds keep paths, every leaf value that isn't below one of them being masked, see WithKeepOnly.
+func (b *MaskerBuilder) KeepOnly(paths ...string) *MaskerBuilder {
+	b.keepPaths = append(b.keepPaths, paths...)
+	return b
+}
+
+// Exclude adds paths whose nodes aren't masked by the mask paths, e.g. "$.users[0].ssn" keeps the ssn
+// of the first user while "$.users[].ssn" masks the others, like the mask path "!$.users[0].ssn".
+func (b *MaskerBuilder) Exclude(paths ...string) *MaskerBuilder {
+	for _, path := range paths {
+		b.exclusions = append(b.exclusions, excludePrefix+strings.TrimPrefix(path, excludePrefix))
+	}
+	return b
+}
+
+// WithFunc masks the nodes matching path with maskFunc, adding path to the mask paths,
+// see WithMaskFuncForPath.
+func (b *MaskerBuilder) WithFunc(path string, maskFunc func(field any) string) *MaskerBuilder {
+	b.maskPaths = append(b.maskPaths, path)
+	b.opts = append(b.opts, WithMaskFuncForPath(path, maskFunc))
+	return b
+}
+
+// WithOptions adds options, e.g. WithFieldNameNormalizer or WithDropMaskedFields, applied in order
+// like the options of NewMasker.
+func (b *MaskerBuilder) WithOptions(opts ...option) *MaskerBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Build returns the masker configured by the builder. It returns an error wrapping ErrInvalidPath
+// if a path can't be parsed, and one wrapping ErrInvalidConfig if rules conflict: a path both masked
+// and kept or both masked and excluded, compared after the keys are normalized with WithFieldNameNormalizer,
+// or a keep path masking keys or excluding nodes. The builder can be used again afterwards.
+func (b *MaskerBuilder) Build() (Masker, error) {
+	keepPaths := b.keepPaths
+	opts := append([]option{}, b.opts...)
+	if keepPaths != nil {
+		// after the options, so KeepOnly wins over a WithKeepOnly option
+		opts = append(opts, WithKeepOnly(append([]string{}, keepPaths...)))
+	}
+	maskPaths := append(append([]string{}, b.maskPaths...), b.exclusions...)
+	m := NewMasker(maskPaths, opts...).(*masker)
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.isRegex || m.isJSONPointer || m.compiled != nil {
+		// the paths aren't written with the path syntax, or aren't the ones of the builder
+		return m, nil
+	}
+	for _, path := range append(maskPaths, keepPaths...) {
+		if _, err := parsePattern(path); err != nil {
+			return nil, withKind(ErrInvalidPath, fmt.Errorf("invalid mask path %q: %w", path, err))
+		}
+	}
+	masked := make(map[string]string, len(b.maskPaths))
+	for _, path := range b.maskPaths {
+		if !strings.HasSuffix(path, keySuffix) {
+			masked[m.ruleKey(path)] = path
+		}
+	}
+	for _, path := range keepPaths {
+		if strings.HasSuffix(path, keySuffix) || strings.HasPrefix(path, excludePrefix) {
+			return nil, withKind(ErrInvalidConfig, fmt.Errorf("keep path %q must match values", path))
+		}
+		if maskPath, ok := masked[m.ruleKey(path)]; ok {
+			return nil, withKind(ErrInvalidConfig, fmt.Errorf("path %q is both masked and kept", maskPath))
+		}
+	}
+	for _, path := range b.exclusions {
+		if maskPath, ok := masked[m.ruleKey(strings.TrimPrefix(path, excludePrefix))]; ok {
+			return nil, withKind(ErrInvalidConfig, fmt.Errorf("path %q is both masked and excluded", maskPath))
+		}
+	}
+	return m, nil
+}
+
+// ruleKey returns the form of a valid path in which paths matching the same nodes are equal,
+// e.g. "user.ssn" and "$['user'].ssn", its keys normalized if WithFieldNameNormalizer is used.
+func (m *masker) ruleKey(path string) string {
+	segments, _ := parsePath(path)
+	return formatSegments(normalizeKeys(withRoot(segments), m.normalizer), "")
+}
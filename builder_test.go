@@ -0,0 +1,121 @@
+package masker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskerBuilder(t *testing.T) {
+	maskEmail := func(field any) string {
+		email := field.(string)
+		return "***" + email[strings.Index(email, "@"):]
+	}
+
+	t.Run("Test with multiple rules", func(t *testing.T) {
+		m, err := NewMaskerBuilder().
+			AddPath("$.user.ssn", "$.cards[].number").
+			MaskObjectKeys("$.accounts.*").
+			Exclude("$.cards[0].number").
+			WithFunc("$.user.email", maskEmail).
+			WithOptions(WithFixedMaskString("xxx")).
+			Build()
+		assert.NoError(t, err)
+		masked, err := m.Mask(`{"user":{"ssn":"123","email":"john@example.com","name":"John"},` +
+			`"cards":[{"number":"4111"},{"number":"4222"}],"accounts":{"acc1":1}}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"user":{"ssn":"xxx","email":"***@example.com","name":"John"},`+
+			`"cards":[{"number":"4111"},{"number":"xxx"}],"accounts":{"xxx":1}}`, masked)
+	})
+
+	t.Run("Test with keep only", func(t *testing.T) {
+		m, err := NewMaskerBuilder().KeepOnly("$.user.id").AddPath("$.user.id~").Build()
+		assert.NoError(t, err)
+		masked, err := m.Mask(`{"user":{"id":1,"name":"John"}}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"user":{"[REDACTED]":1,"name":"[REDACTED]"}}`, masked)
+	})
+
+	t.Run("Test with normalizer", func(t *testing.T) {
+		m, err := NewMaskerBuilder().
+			AddPath("$.user_email").
+			WithOptions(WithFieldNameNormalizer(SnakeCaseFieldName)).
+			Build()
+		assert.NoError(t, err)
+		masked, err := m.Mask(`{"userEmail":"a@b.c"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"userEmail":"[REDACTED]"}`, masked)
+	})
+
+	t.Run("Test with builder used again", func(t *testing.T) {
+		b := NewMaskerBuilder().AddPath("$.a")
+		first, err := b.Build()
+		assert.NoError(t, err)
+		second, err := b.AddPath("$.b").Build()
+		assert.NoError(t, err)
+		masked, err := first.Mask(`{"a":1,"b":2}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"[REDACTED]","b":2}`, masked)
+		masked, err = second.Mask(`{"a":1,"b":2}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"[REDACTED]","b":"[REDACTED]"}`, masked)
+	})
+
+	testTable := []struct {
+		name        string
+		builder     *MaskerBuilder
+		expectedErr string
+		kind        error
+	}{
+		{
+			name:        "Test with invalid path",
+			builder:     NewMaskerBuilder().AddPath("$.a[", "$.b"),
+			expectedErr: `invalid mask path "$.a[": `,
+			kind:        ErrInvalidPath,
+		},
+		{
+			name:        "Test with invalid keep path",
+			builder:     NewMaskerBuilder().KeepOnly("$.a.."),
+			expectedErr: `invalid mask path "$.a..": `,
+			kind:        ErrInvalidPath,
+		},
+		{
+			name:        "Test with masked and kept path",
+			builder:     NewMaskerBuilder().AddPath("$.user.ssn").KeepOnly("user.ssn"),
+			expectedErr: `path "$.user.ssn" is both masked and kept`,
+			kind:        ErrInvalidConfig,
+		},
+		{
+			name:        "Test with masked and excluded path",
+			builder:     NewMaskerBuilder().AddPath("$['user'].ssn").Exclude("$.user.ssn"),
+			expectedErr: `path "$['user'].ssn" is both masked and excluded`,
+			kind:        ErrInvalidConfig,
+		},
+		{
+			name: "Test with normalized conflicting paths",
+			builder: NewMaskerBuilder().AddPath("$.UserEmail").KeepOnly("$.user_email").
+				WithOptions(WithFieldNameNormalizer(SnakeCaseFieldName)),
+			expectedErr: `path "$.UserEmail" is both masked and kept`,
+			kind:        ErrInvalidConfig,
+		},
+		{
+			name:        "Test with keep path masking keys",
+			builder:     NewMaskerBuilder().KeepOnly("$.ids~"),
+			expectedErr: `keep path "$.ids~" must match values`,
+			kind:        ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.builder.Build()
+			assert.Nil(t, m)
+			if assert.Error(t, err) {
+				assert.True(t, strings.HasPrefix(err.Error(), tt.expectedErr), err.Error())
+				assert.True(t, errors.Is(err, tt.kind))
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package masker
+
+import "fmt"
+
+// CompiledPaths is a set of mask paths parsed and validated once by CompilePaths,
+// to be reused by maskers with WithCompiledPaths.
+type CompiledPaths struct {
+	paths []string
+	set   pathSet
+}
+
+// CompilePaths parses and validates the syntax of the mask paths, returning an error
+// pointing at the first invalid path, e.g. an unterminated bracket or an empty key.
+// NewMasker validates its paths too, but only reports an invalid one on every mask call.
+func CompilePaths(paths []string) (CompiledPaths, error) {
+	for _, path := range paths {
+		if _, err := parsePattern(path); err != nil {
+			return CompiledPaths{}, withKind(ErrInvalidPath, fmt.Errorf("invalid mask path %q: %w", path, err))
+		}
+	}
+	paths = append([]string{}, paths...)
+	return CompiledPaths{paths: paths, set: newPathSet(paths)}, nil
+}
+
+// Paths returns the mask paths that were compiled.
+func (c CompiledPaths) Paths() []string {
+	return append([]string{}, c.paths...)
+}
+
+// WithCompiledPaths makes the masker use paths compiled by CompilePaths instead of
+// the maskPaths passed to NewMasker, avoiding parsing them again.
+// Compiled paths use the path syntax, so WithRegexPaths and WithJSONPointerPaths
+// have no effect along with them.
+func WithCompiledPaths(paths CompiledPaths) option {
+	return func(m *masker) {
+		m.compiled = &paths
+	}
+}
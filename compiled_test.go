@@ -0,0 +1,97 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompilePaths(t *testing.T) {
+	testTable := []struct {
+		name        string
+		paths       []string
+		expectedErr string
+	}{
+		{
+			name:  "valid paths",
+			paths: []string{"$.name", "$.users[].card", "$['user.email']", "$..password", "$.items[1:3]", "$.accounts.*~"},
+		},
+		{
+			name:        "unterminated bracket",
+			paths:       []string{"$.name", "$.users[.card"},
+			expectedErr: `invalid mask path "$.users[.card": unterminated bracket at offset 7`,
+		},
+		{
+			name:        "unclosed bracket",
+			paths:       []string{"$.users["},
+			expectedErr: `invalid mask path "$.users[": unterminated bracket at offset 7`,
+		},
+		{
+			name:        "unopened bracket",
+			paths:       []string{"$.users].card"},
+			expectedErr: `invalid mask path "$.users].card": unexpected ] at offset 7`,
+		},
+		{
+			name:        "unterminated quoted key",
+			paths:       []string{"$['user.email"},
+			expectedErr: `invalid mask path "$['user.email": unterminated quoted key at offset 1`,
+		},
+		{
+			name:        "empty segment after descent",
+			paths:       []string{"$.users..", "$.a"},
+			expectedErr: `invalid mask path "$.users..": empty key at offset 9`,
+		},
+		{
+			name:        "trailing dot",
+			paths:       []string{"$.users.name."},
+			expectedErr: `invalid mask path "$.users.name.": empty key at offset 13`,
+		},
+		{
+			name:        "key suffix on the root",
+			paths:       []string{"$~"},
+			expectedErr: `invalid mask path "$~": ~ suffix must follow a key`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := CompilePaths(tt.paths)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.paths, compiled.Paths())
+		})
+	}
+}
+
+func TestMask_compiledPaths(t *testing.T) {
+	compiled, err := CompilePaths([]string{"$.name", "$.cards[].number"})
+	assert.NoError(t, err)
+	masker := NewMasker(nil, WithCompiledPaths(compiled))
+
+	for _, input := range []string{
+		`{"name":"John","cards":[{"number":"4111"}]}`,
+		`{"name":"Jane","cards":[]}`,
+	} {
+		_, err := masker.Mask(input)
+		assert.NoError(t, err)
+	}
+	output, err := masker.Mask(`{"name":"John","cards":[{"number":"4111","type":"visa"}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"[REDACTED]","cards":[{"number":"[REDACTED]","type":"visa"}]}`, output)
+
+	output, err = masker.MaskWithPaths(`{"name":"John","age":30}`, []string{"$.age"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John","age":"[REDACTED]"}`, output)
+
+	masker = NewMasker(nil, WithJSONPointerPaths(), WithCompiledPaths(compiled))
+	output, err = masker.MaskWithPaths(`{"name":"John","age":30}`, []string{"$.age"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John","age":"[REDACTED]"}`, output)
+
+	output, err = masker.MaskSubtree(`{"user":{"name":"John","ssn":"123"}}`, "$.user", []string{"ssn"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"user":{"name":"John","ssn":"[REDACTED]"}}`, output)
+}
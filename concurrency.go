@@ -0,0 +1,112 @@
+package masker
+
+import (
+	"reflect"
+	"sync"
+)
+
+// concurrentMinElements is the number of elements below which WithConcurrency masks arrays
+// sequentially, spreading them over goroutines costing more than it saves.
+const concurrentMinElements = 128
+
+// WithConcurrency masks the elements of large arrays across up to n goroutines, e.g. the
+// top-level array of a big export, the masked elements being reassembled in their order.
+// Only the outermost arrays of at least 128 elements are split, the arrays found inside their
+// elements being masked by the goroutine masking the element. Arrays are masked sequentially
+// when n is less than 2. Mask functions, replacers, hooks and matchers may then be called
+// concurrently and must be safe for concurrent use.
+// MaskReader and MaskLines stream their input and only use it for the values they buffer.
+func WithConcurrency(n int) option {
+	return func(m *masker) {
+		m.concurrency = n
+	}
+}
+
+// maskElements masks the elements of the slice or array input at path, returning the masked
+// elements at their index, concurrently for large arrays, see WithConcurrency.
+func (m *masker) maskElements(input reflect.Value, state *maskState, path nodePath) ([]any, error) {
+	elements := make([]any, input.Len())
+	if m.concurrency < 2 || state.isConcurrent || len(elements) < concurrentMinElements {
+		for i := range elements {
+			maskedValue, err := m.maskWithPaths(input.Index(i), state, path.element(i, len(elements)))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = maskedValue
+		}
+		return elements, nil
+	}
+
+	workers := min(m.concurrency, len(elements))
+	chunk := (len(elements) + workers - 1) / workers
+	states := make([]*maskState, 0, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for start := 0; start < len(elements); start += chunk {
+		end := min(start+chunk, len(elements))
+		forked, w := state.fork(), len(states)
+		states = append(states, forked)
+		// the paths of the elements share the segments of path, see nodePath.key,
+		// so every goroutine extends its own copy
+		base := make(nodePath, len(path))
+		copy(base, path)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				maskedValue, err := m.maskWithPaths(input.Index(i), forked, base.element(i, len(elements)))
+				if err != nil {
+					errs[w] = err
+					return
+				}
+				elements[i] = maskedValue
+			}
+		}()
+	}
+	wg.Wait()
+	for w, forked := range states {
+		if errs[w] != nil {
+			return nil, errs[w]
+		}
+		state.merge(forked)
+	}
+	return elements, nil
+}
+
+// fork returns a state masking part of the document concurrently with s, see WithConcurrency.
+// What it records is added to s by merge.
+func (s *maskState) fork() *maskState {
+	forked := s.clone()
+	forked.isReport, forked.isDryRun, forked.isConcurrent = s.isReport, s.isDryRun, true
+	forked.tagged, forked.subtree, forked.isAroundProtected = s.tagged, s.subtree, s.isAroundProtected
+	if s.counts != nil {
+		forked.counts = make(map[string]int, len(s.counts))
+	}
+	if s.visiting != nil {
+		forked.visiting = make(map[visit]int, len(s.visiting))
+		for v, depth := range s.visiting {
+			forked.visiting[v] = depth
+		}
+	}
+	if s.vault != nil {
+		forked.vault = make(map[string]any)
+	}
+	return forked
+}
+
+// merge adds what a state returned by fork recorded to s, forked states being merged
+// in the order of the elements they masked.
+func (s *maskState) merge(forked *maskState) {
+	s.visited += forked.visited
+	s.maskedPaths = append(s.maskedPaths, forked.maskedPaths...)
+	s.hits = append(s.hits, forked.hits...)
+	for path := range forked.matched {
+		s.matched[path] = true
+	}
+	for path, value := range forked.vault {
+		s.vault[path] = value
+	}
+	for path, count := range forked.counts {
+		s.counts[path] += count
+	}
+}
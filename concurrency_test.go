@@ -0,0 +1,118 @@
+package masker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// largeArray returns a top-level array of n users with an ssn and nested tags.
+func largeArray(n int) string {
+	users := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		users = append(users, fmt.Sprintf(`{"id":%d,"ssn":"%03d","tags":["a","b"]}`, i, i))
+	}
+	return "[" + strings.Join(users, ",") + "]"
+}
+
+func TestWithConcurrency(t *testing.T) {
+	input := largeArray(1000)
+	testTable := []struct {
+		name      string
+		maskPaths []string
+		opts      []option
+	}{
+		{
+			name:      "Test with masked members",
+			maskPaths: []string{"$[].ssn", "$[].tags[1]"},
+		},
+		{
+			name:      "Test with dropped members",
+			maskPaths: []string{"$[].ssn", "$[1].id"},
+			opts:      []option{WithDropMaskedFields()},
+		},
+		{
+			name:      "Test with strict paths",
+			maskPaths: []string{"$[999].ssn", "$[0].id"},
+			opts:      []option{WithStrictPaths()},
+		},
+		{
+			name:      "Test with deep masked elements",
+			maskPaths: []string{"$[]"},
+			opts:      []option{WithDeepMaskSubtrees()},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := NewMasker(tt.maskPaths, tt.opts...).Mask(input)
+			assert.NoError(t, err)
+			masker := NewMasker(tt.maskPaths, append([]option{WithConcurrency(8)}, tt.opts...)...)
+			masked, err := masker.Mask(input)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, masked)
+
+			_, expectedPaths, err := NewMasker(nil, tt.opts...).MaskWithReport(input, tt.maskPaths)
+			assert.NoError(t, err)
+			_, maskedPaths, err := masker.MaskWithReport(input, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedPaths, maskedPaths)
+		})
+	}
+
+	t.Run("Test with strict paths matching nothing", func(t *testing.T) {
+		_, err := NewMasker([]string{"$[1000].ssn"}, WithConcurrency(8), WithStrictPaths()).Mask(input)
+		assert.EqualError(t, err, "mask paths matched nothing: $[1000].ssn")
+	})
+
+	t.Run("Test with dry run", func(t *testing.T) {
+		hits, err := NewMasker([]string{"$[].ssn"}, WithConcurrency(8)).MaskDryRun(input, nil)
+		assert.NoError(t, err)
+		assert.Len(t, hits, 1000)
+		for i, hit := range hits {
+			assert.Equal(t, fmt.Sprintf("$[%d].ssn", i), hit.Path)
+		}
+	})
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		ids := make([]int, 500)
+		for i := range ids {
+			ids[i] = i
+		}
+		masked, err := NewMasker([]string{"$[]"}, WithConcurrency(4),
+			WithReplacer(func(value any, path string) any { return value.(int) * 2 })).MaskValue(ids, nil)
+		assert.NoError(t, err)
+		for i, id := range masked.([]int) {
+			assert.Equal(t, i*2, id)
+		}
+	})
+
+	t.Run("Test with error in an element", func(t *testing.T) {
+		masker := NewMasker([]string{"$[].ssn"}, WithConcurrency(8), WithMaskFunc(func(field any) string {
+			if field == "500" || field == "900" {
+				panic("boom")
+			}
+			return DefaultMaskString
+		}))
+		_, err := masker.Mask(input)
+		assert.EqualError(t, err, "failed to mask object: mask function panicked at path $[500].ssn: boom")
+	})
+}
+
+func BenchmarkMask_concurrency(b *testing.B) {
+	input := largeArray(10000)
+	for _, n := range []int{1, 4} {
+		b.Run(fmt.Sprintf("goroutines=%d", n), func(b *testing.B) {
+			masker := NewMasker([]string{"$[].ssn"}, WithConcurrency(n))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := masker.Mask(input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
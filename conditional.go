@@ -0,0 +1,59 @@
+package masker
+
+// conditionalMask masks the nodes matching a path when a predicate over their parent object holds.
+type conditionalMask struct {
+	pattern pathPattern
+	// parent matches the parent objects of the nodes matched by pattern.
+	parent    pathPattern
+	predicate func(node map[string]any) bool
+}
+
+// WithConditionalMask masks path only when predicate returns true for the object containing it,
+// e.g. masking "$.payment.number" only when the payment object has "type":"card":
+//
+//	WithConditionalMask("$.payment.number", func(node map[string]any) bool {
+//		return node["type"] == "card"
+//	})
+//
+// The path doesn't need to be one of the mask paths, and a mask path matching
+// the same node masks it regardless of the predicate.
+// Since the predicate receives the parent object, a path that ends with an array
+// element, e.g. "$.items[]", never matches. Paths through arrays are evaluated
+// against each element instead, e.g. "$.payments[].number" calls the predicate
+// once per element of payments.
+func WithConditionalMask(path string, predicate func(node map[string]any) bool) option {
+	return withOptionPath(path, func(m *masker, path string, pattern pathPattern) {
+		if pattern.segments == nil || pattern.keys {
+			// invalid paths never match
+			return
+		}
+		last := pattern.segments[len(pattern.segments)-1]
+		if last.kind != keySegment && last.kind != globSegment && last.kind != wildcardSegment {
+			return
+		}
+		parent := pathPattern{path: path, segments: pattern.segments[:len(pattern.segments)-1]}
+		m.conditions = append(m.conditions, conditionalMask{pattern: pattern, parent: parent, predicate: predicate})
+	})
+}
+
+// matchesCondition checks if the child at path of the parent object should be masked by a conditional mask.
+func (m *masker) matchesCondition(parent map[string]any, path nodePath) bool {
+	path = normalizeKeys(path, m.normalizer)
+	for _, condition := range m.conditions {
+		if condition.pattern.match(path) && condition.predicate(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConditionParent checks if the node at path may be the parent object of a conditional mask.
+func (m *masker) isConditionParent(path nodePath) bool {
+	path = normalizeKeys(path, m.normalizer)
+	for _, condition := range m.conditions {
+		if condition.parent.match(path) {
+			return true
+		}
+	}
+	return false
+}
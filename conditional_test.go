@@ -0,0 +1,74 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithConditionalMask(t *testing.T) {
+	isCard := func(node map[string]any) bool {
+		return node["type"] == "card"
+	}
+
+	testTable := []struct {
+		name      string
+		input     string
+		path      string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:     "Test with matching sibling",
+			input:    `{"payment":{"number":"4111111111111234","type":"card"}}`,
+			path:     "$.payment.number",
+			expected: `{"payment":{"number":"[REDACTED]","type":"card"}}`,
+		},
+		{
+			name:     "Test with non matching sibling",
+			input:    `{"payment":{"number":"DE89370400440532013000","type":"iban"}}`,
+			path:     "$.payment.number",
+			expected: `{"payment":{"number":"DE89370400440532013000","type":"iban"}}`,
+		},
+		{
+			name:     "Test with missing sibling",
+			input:    `{"payment":{"number":"4111111111111234"}}`,
+			path:     "$.payment.number",
+			expected: `{"payment":{"number":"4111111111111234"}}`,
+		},
+		{
+			name:     "Test with array elements",
+			input:    `{"payments":[{"type":"card","number":"1"},{"type":"iban","number":"2"},{"type":"card","number":"3"}]}`,
+			path:     "$.payments[].number",
+			expected: `{"payments":[{"type":"card","number":"[REDACTED]"},{"type":"iban","number":"2"},{"type":"card","number":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "Test with path to an array element",
+			input:    `{"type":"card","numbers":["1"]}`,
+			path:     "$.numbers[]",
+			expected: `{"type":"card","numbers":["1"]}`,
+		},
+		{
+			name:      "Test with mask path matching regardless of the predicate",
+			input:     `{"payment":{"number":"DE89370400440532013000","type":"iban"}}`,
+			path:      "$.payment.number",
+			maskPaths: []string{"$.payment.number"},
+			expected:  `{"payment":{"number":"[REDACTED]","type":"iban"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, WithConditionalMask(tt.path, isCard))
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// csvField masks columns of the CSV text held by the strings matching a path, see WithCSVField.
+type csvField struct {
+	pattern pathPattern
+	columns map[int]bool
+	// header keeps the first row, strict returns an error for invalid CSV,
+	// and comma and lazyQuotes configure the csv.Reader.
+	header, strict, lazyQuotes bool
+	comma                      rune
+}
+
+// CSVOption configures how WithCSVField parses the CSV text of a field.
+type CSVOption func(*csvField)
+
+// CSVHeader keeps the first row of the CSV text, which holds the column names, as it is.
+func CSVHeader() CSVOption {
+	return func(f *csvField) {
+		f.header = true
+	}
+}
+
+// CSVComma sets the field delimiter of the CSV text, ',' by default, e.g. ';' or '\t'.
+func CSVComma(comma rune) CSVOption {
+	return func(f *csvField) {
+		f.comma = comma
+	}
+}
+
+// CSVLazyQuotes accepts quotes in unquoted fields and unescaped quotes in quoted fields,
+// see csv.Reader.LazyQuotes, which are otherwise invalid CSV.
+func CSVLazyQuotes() CSVOption {
+	return func(f *csvField) {
+		f.lazyQuotes = true
+	}
+}
+
+// CSVStrict makes masking return an error wrapping ErrInvalidCSV when the field isn't valid CSV,
+// instead of keeping it as it is.
+func CSVStrict() CSVOption {
+	return func(f *csvField) {
+		f.strict = true
+	}
+}
+
+// WithCSVField makes the string values at path be masked as the CSV text they hold,
+// e.g. an export embedded in a JSON document: the columns at the maskColumns indexes,
+// starting from 0, are masked in every row and the text is encoded back as CSV.
+// Cells are masked like the node at path would be, e.g. by the mask function registered
+// with WithMaskFuncForPath for path, and aren't reported by MaskWithReport, MaskWithVault or WithStrictPaths.
+// Quoted fields are read as encoding/csv reads them and written back quoted only when they need to be,
+// rows having any number of fields. Line endings follow the input, "\r\n" if it holds any.
+// Strings that aren't valid CSV are kept as they are, unless CSVStrict is used.
+// path uses the path syntax, and the first WithCSVField whose path matches a string is used.
+func WithCSVField(path string, maskColumns []int, opts ...CSVOption) option {
+	return withOptionPath(path, func(m *masker, _ string, pattern pathPattern) {
+		field := csvField{pattern: pattern, columns: make(map[int]bool, len(maskColumns)), comma: ','}
+		for _, column := range maskColumns {
+			field.columns[column] = true
+		}
+		for _, opt := range opts {
+			opt(&field)
+		}
+		m.csvFields = append(m.csvFields, field)
+	})
+}
+
+// csvField returns the CSV field the string at path holds, or nil, see WithCSVField.
+func (m *masker) csvField(path nodePath) *csvField {
+	if len(m.csvFields) == 0 {
+		return nil
+	}
+	path = normalizeKeys(path, m.normalizer)
+	for i := range m.csvFields {
+		if m.csvFields[i].pattern.match(path) {
+			return &m.csvFields[i]
+		}
+	}
+	return nil
+}
+
+// maskCSV masks the columns of the CSV text held by the string at path, returning it encoded.
+// Strings that aren't valid CSV are returned as they are, unless CSVStrict is used.
+func (m *masker) maskCSV(field *csvField, str string, path nodePath) (string, error) {
+	reader := csv.NewReader(strings.NewReader(str))
+	reader.Comma, reader.LazyQuotes, reader.FieldsPerRecord = field.comma, field.lazyQuotes, -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		if field.strict {
+			return "", withKind(ErrInvalidCSV, fmt.Errorf("failed to read CSV at path %s: %w", path, err))
+		}
+		m.log("Keeping invalid CSV", logActionKeep, path)
+		return str, nil
+	}
+	for i, record := range records {
+		if i == 0 && field.header {
+			continue
+		}
+		for column, cell := range record {
+			if !field.columns[column] {
+				continue
+			}
+			masked, err := m.maskedValue(cell, path, false)
+			if err != nil {
+				return "", err
+			}
+			if s, ok := masked.(string); ok {
+				record[column] = s
+			} else {
+				record[column] = fmt.Sprint(masked)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma, writer.UseCRLF = field.comma, strings.Contains(str, "\r\n")
+	if err := writer.WriteAll(records); err != nil {
+		return "", fmt.Errorf("failed to write CSV at path %s: %w", path, err)
+	}
+	encoded := buf.String()
+	if !strings.HasSuffix(str, "\n") {
+		// the writer ends every row with a line ending
+		encoded = strings.TrimSuffix(strings.TrimSuffix(encoded, "\n"), "\r")
+	}
+	return encoded, nil
+}
@@ -0,0 +1,109 @@
+package masker
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCSVField(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		columns  []int
+		opts     []CSVOption
+		expected string
+	}{
+		{
+			name:     "Test with multi-row CSV",
+			input:    `{"export":"john,123-45,NY\njane,678-90,LA\n"}`,
+			columns:  []int{1},
+			expected: `{"export":"john,[REDACTED],NY\njane,[REDACTED],LA\n"}`,
+		},
+		{
+			name:     "Test with header",
+			input:    `{"export":"name,ssn\njohn,123-45\njane,678-90"}`,
+			columns:  []int{1},
+			opts:     []CSVOption{CSVHeader()},
+			expected: `{"export":"name,ssn\njohn,[REDACTED]\njane,[REDACTED]"}`,
+		},
+		{
+			name:     "Test with quoted fields",
+			input:    `{"export":"\"Doe, John\",\"123\"\n\"Doe, Jane\",\"456\""}`,
+			columns:  []int{1},
+			expected: `{"export":"\"Doe, John\",[REDACTED]\n\"Doe, Jane\",[REDACTED]"}`,
+		},
+		{
+			name:     "Test with several columns and short rows",
+			input:    `{"export":"a,b,c\nd\r\ne,f\r\n"}`,
+			columns:  []int{0, 2, 5},
+			expected: `{"export":"[REDACTED],b,[REDACTED]\r\n[REDACTED]\r\n[REDACTED],f\r\n"}`,
+		},
+		{
+			name:     "Test with delimiter",
+			input:    `{"export":"john;123"}`,
+			columns:  []int{1},
+			opts:     []CSVOption{CSVComma(';')},
+			expected: `{"export":"john;[REDACTED]"}`,
+		},
+		{
+			name:     "Test with lazy quotes",
+			input:    `{"export":"jo\"hn,123"}`,
+			columns:  []int{1},
+			opts:     []CSVOption{CSVLazyQuotes()},
+			expected: `{"export":"\"jo\"\"hn\",[REDACTED]"}`,
+		},
+		{
+			name:     "Test with invalid CSV",
+			input:    `{"export":"jo\"hn,123"}`,
+			columns:  []int{1},
+			expected: `{"export":"jo\"hn,123"}`,
+		},
+		{
+			name:     "Test with non string value",
+			input:    `{"export":["john","123"]}`,
+			columns:  []int{1},
+			expected: `{"export":["john","123"]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(nil, WithCSVField("$.export", tt.columns, tt.opts...))
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with strict CSV", func(t *testing.T) {
+		_, err := NewMasker(nil, WithCSVField("$.export", []int{1}, CSVStrict())).Mask(`{"export":"jo\"hn,123"}`)
+		assert.EqualError(t, err, `failed to mask object: failed to read CSV at path $.export: parse error on line 1, column 3: bare " in non-quoted-field`)
+		assert.True(t, errors.Is(err, ErrInvalidCSV))
+	})
+
+	t.Run("Test with path mask function", func(t *testing.T) {
+		masker := NewMasker(nil,
+			WithCSVField("$.rows[].csv", []int{0}),
+			WithMaskFuncForPath("$.rows[].csv", func(field any) string {
+				return strings.Repeat("*", len(field.(string)))
+			}),
+		)
+		masked, err := masker.Mask(`{"rows":[{"csv":"john,1"},{"csv":"al,2"}]}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"rows":[{"csv":"****,1"},{"csv":"**,2"}]}`, masked)
+	})
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		masked, err := NewMasker(nil, WithCSVField("$.Export", []int{1})).MaskValue(struct{ Export string }{Export: "john,123"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, struct{ Export string }{Export: "john,[REDACTED]"}, masked)
+	})
+}
@@ -0,0 +1,62 @@
+package masker
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// visit identifies a pointer, map or slice being traversed by MaskValue, see maskState.enter.
+// The type tells apart a pointer to a struct from a pointer to its first field, and the length
+// the slices sharing their first element.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// enter records the pointers, maps and slices input holds as traversed, until the returned function
+// is called once input is masked. It returns an error if one of them is already being traversed
+// higher up, meaning input is part of a cycle that would be followed forever.
+// Only MaskValue sets visiting, decoded JSON documents not having cycles.
+func (s *maskState) enter(input reflect.Value, path nodePath) (func(), error) {
+	var entered []visit
+	leave := func() {
+		for _, v := range entered {
+			delete(s.visiting, v)
+		}
+	}
+	for input.IsValid() {
+		v := visit{typ: input.Type()}
+		switch input.Kind() {
+		case reflect.Interface:
+			if input.IsNil() {
+				return leave, nil
+			}
+			input = input.Elem()
+			continue
+		case reflect.Ptr, reflect.Map:
+			if input.IsNil() || input.Type() == objectType {
+				return leave, nil
+			}
+			v.ptr = input.Pointer()
+		case reflect.Slice:
+			if input.IsNil() || input.Len() == 0 {
+				return leave, nil
+			}
+			v.ptr, v.len = input.Pointer(), input.Len()
+		default:
+			return leave, nil
+		}
+		if depth, ok := s.visiting[v]; ok {
+			leave()
+			return nil, fmt.Errorf("cycle detected at path %s, which points back to %s", path, path[:depth])
+		}
+		s.visiting[v] = len(path)
+		entered = append(entered, v)
+		if input.Kind() != reflect.Ptr {
+			return leave, nil
+		}
+		input = input.Elem()
+	}
+	return leave, nil
+}
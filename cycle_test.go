@@ -0,0 +1,74 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cyclicNode struct {
+	Name string
+	Next *cyclicNode
+}
+
+func TestMaskValue_cycles(t *testing.T) {
+	self := &cyclicNode{Name: "self"}
+	self.Next = self
+
+	first := &cyclicNode{Name: "first"}
+	first.Next = &cyclicNode{Name: "second", Next: first}
+
+	selfMap := map[string]any{"ssn": "123"}
+	selfMap["self"] = selfMap
+
+	selfSlice := []any{"123", nil}
+	selfSlice[1] = selfSlice
+
+	testTable := []struct {
+		name        string
+		input       any
+		expectedErr string
+	}{
+		{
+			name:        "Test with struct pointing to itself",
+			input:       self,
+			expectedErr: "failed to mask object: cycle detected at path $.Next, which points back to $",
+		},
+		{
+			name:        "Test with longer cycle",
+			input:       cyclicNode{Name: "root", Next: first},
+			expectedErr: "failed to mask object: cycle detected at path $.Next.Next.Next, which points back to $.Next",
+		},
+		{
+			name:        "Test with map holding itself",
+			input:       selfMap,
+			expectedErr: "failed to mask object: cycle detected at path $.self, which points back to $",
+		},
+		{
+			name:        "Test with slice holding itself",
+			input:       selfSlice,
+			expectedErr: "failed to mask object: cycle detected at path $[1], which points back to $",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMasker([]string{"$..ssn"}).MaskValue(tt.input, nil)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+
+	t.Run("Test with shared pointers", func(t *testing.T) {
+		shared := &cyclicNode{Name: "shared"}
+		input := map[string]*cyclicNode{"a": shared, "b": shared}
+		masked, err := NewMasker([]string{"$.*.Name"}).MaskValue(input, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*cyclicNode{"a": {Name: "[REDACTED]"}, "b": {Name: "[REDACTED]"}}, masked)
+	})
+
+	t.Run("Test with dropped cycle", func(t *testing.T) {
+		masked, err := NewMasker([]string{"$.Next"}, WithDropMaskedFields()).MaskValue(self, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, cyclicNode{Name: "self"}, masked)
+	})
+}
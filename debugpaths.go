@@ -0,0 +1,41 @@
+package masker
+
+import "fmt"
+
+// MaskDebugPaths returns the normalized paths of every leaf of the input JSON string,
+// that is of its scalars, nulls and empty objects and arrays, with their array indexes
+// collapsed to [] as mask paths match them, e.g. "$.users[].email". Paths are returned
+// once each in document order, to be copied into mask paths. Nothing is masked.
+func (m *masker) MaskDebugPaths(input string) ([]string, error) {
+	value, err := m.decodeInput([]byte(input))
+	if err != nil {
+		return nil, withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	var paths []string
+	seen := make(map[string]bool)
+	var walk func(value any, path nodePath)
+	walk = func(value any, path nodePath) {
+		switch v := value.(type) {
+		case *object:
+			if len(v.keys) > 0 {
+				for i, key := range v.keys {
+					walk(v.values[i], path.key(key))
+				}
+				return
+			}
+		case []any:
+			if len(v) > 0 {
+				for i, elem := range v {
+					walk(elem, path.index(i))
+				}
+				return
+			}
+		}
+		if normalized := normalizePath(path); !seen[normalized] {
+			seen[normalized] = true
+			paths = append(paths, normalized)
+		}
+	}
+	walk(value, rootPath())
+	return paths, nil
+}
@@ -0,0 +1,57 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskDebugPaths(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:  "Test with nested document",
+			input: `{"name":"John","users":[{"email":"a","roles":["x","y"]},{"email":"b","phone":null}],"meta":{"user.id":1,"tags":[],"extra":{}}}`,
+			expected: []string{
+				"$.name",
+				"$.users[].email",
+				"$.users[].roles[]",
+				"$.users[].phone",
+				"$.meta['user.id']",
+				"$.meta.tags",
+				"$.meta.extra",
+			},
+		},
+		{
+			name:     "Test with scalar document",
+			input:    `"John"`,
+			expected: []string{"$"},
+		},
+		{
+			name:     "Test with nested arrays",
+			input:    `[[1,2],[3]]`,
+			expected: []string{"$[][]"},
+		},
+		{
+			name:      "Test with invalid JSON",
+			input:     `{"a":`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := NewMasker(nil).MaskDebugPaths(tt.input)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrInvalidJSON)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+}
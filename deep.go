@@ -0,0 +1,35 @@
+package masker
+
+import "reflect"
+
+// WithDeepMaskSubtrees masks the leaves of the objects and arrays matched by the mask paths one by one
+// instead of replacing the objects and arrays as a whole, keeping their structure: "$.user" masks
+// {"user":{"name":"John","tags":["a"]}} as {"user":{"name":"[REDACTED]","tags":["[REDACTED]"]}}.
+// Empty objects and arrays are kept as they are, and matched leaves are masked as usual.
+func WithDeepMaskSubtrees() option {
+	return func(m *masker) {
+		m.isDeepMask = true
+	}
+}
+
+// masksSubtree checks if the node matched by the mask paths is traversed for its leaves
+// to be masked instead of being masked as a whole, see WithDeepMaskSubtrees.
+func (m *masker) masksSubtree(input reflect.Value) bool {
+	if !m.isDeepMask || !input.IsValid() {
+		return false
+	}
+	if input.Type() == objectType || input.Type() == rawMessageType {
+		return !input.IsNil()
+	}
+	if isOpaque(input.Type()) {
+		return false
+	}
+	switch input.Kind() {
+	case reflect.Struct, reflect.Array:
+		return true
+	case reflect.Slice, reflect.Map:
+		return !input.IsNil()
+	default:
+		return false
+	}
+}
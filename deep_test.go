@@ -0,0 +1,113 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDeepMaskSubtrees(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		collapsed string
+		expected  string
+	}{
+		{
+			name:      "Test with object",
+			input:     `{"user":{"name":"John","age":30,"tags":["a","b"],"meta":{}},"id":1}`,
+			maskPaths: []string{"$.user"},
+			collapsed: `{"user":"[REDACTED]","id":1}`,
+			expected:  `{"user":{"name":"[REDACTED]","age":"[REDACTED]","tags":["[REDACTED]","[REDACTED]"],"meta":{}},"id":1}`,
+		},
+		{
+			name:      "Test with array",
+			input:     `{"cards":[{"number":"4111"},[1]]}`,
+			maskPaths: []string{"$.cards"},
+			collapsed: `{"cards":"[REDACTED]"}`,
+			expected:  `{"cards":[{"number":"[REDACTED]"},["[REDACTED]"]]}`,
+		},
+		{
+			name:      "Test with root",
+			input:     `{"a":{"b":1}}`,
+			maskPaths: []string{"$"},
+			collapsed: `"[REDACTED]"`,
+			expected:  `{"a":{"b":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with matched leaf",
+			input:     `{"ssn":"123","user":{"ssn":"456"}}`,
+			maskPaths: []string{"$..ssn"},
+			collapsed: `{"ssn":"[REDACTED]","user":{"ssn":"[REDACTED]"}}`,
+			expected:  `{"ssn":"[REDACTED]","user":{"ssn":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with exclusion",
+			input:     `{"user":{"id":1,"ssn":"123","address":{"city":"Paris"}}}`,
+			maskPaths: []string{"$.user", "!$.user.id"},
+			collapsed: `{"user":{"id":1,"ssn":"[REDACTED]","address":"[REDACTED]"}}`,
+			expected:  `{"user":{"id":1,"ssn":"[REDACTED]","address":{"city":"[REDACTED]"}}}`,
+		},
+		{
+			name:      "Test with dropped leaves",
+			input:     `{"user":{"name":"John","tags":["a"]},"id":1}`,
+			maskPaths: []string{"$.user"},
+			opts:      []option{WithDropMaskedFields()},
+			collapsed: `{"id":1}`,
+			expected:  `{"user":{"tags":[]},"id":1}`,
+		},
+		{
+			name:      "Test with kept nulls",
+			input:     `{"user":{"name":"John","phone":null}}`,
+			maskPaths: []string{"$.user"},
+			opts:      []option{WithKeepNulls()},
+			collapsed: `{"user":"[REDACTED]"}`,
+			expected:  `{"user":{"name":"[REDACTED]","phone":null}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			collapsed, err := NewMasker(tt.maskPaths, tt.opts...).Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.collapsed, collapsed)
+
+			masker := NewMasker(tt.maskPaths, append([]option{WithDeepMaskSubtrees()}, tt.opts...)...)
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with strict paths matching an empty object", func(t *testing.T) {
+		_, err := NewMasker([]string{"$.meta"}, WithDeepMaskSubtrees(), WithStrictPaths()).Mask(`{"meta":{}}`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Test with report", func(t *testing.T) {
+		_, maskedPaths, err := NewMasker(nil, WithDeepMaskSubtrees()).MaskWithReport(`{"user":{"name":"John","tags":["a"]}}`, []string{"$.user"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.user.name", "$.user.tags[0]"}, maskedPaths)
+	})
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		type user struct {
+			Name string
+			Raw  json.RawMessage
+		}
+		masked, err := NewMasker([]string{"$.user"}, WithDeepMaskSubtrees()).MaskValue(map[string]any{
+			"user": user{Name: "John", Raw: json.RawMessage(`{"a":1}`)},
+		}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"user": user{Name: DefaultMaskString, Raw: json.RawMessage(`{"a":"[REDACTED]"}`)}}, masked)
+	})
+}
@@ -0,0 +1,32 @@
+package masker
+
+// MaskHit is a node that a mask call would mask, see MaskDryRun.
+type MaskHit struct {
+	// Path is the concrete path of the node, e.g. "$.items[3].card".
+	// Masked keys have the path of their value with the ~ suffix, e.g. "$.accounts.acc1~".
+	Path string
+	// OriginalValue is the value of the node as decoded from the input, json.Number for numbers
+	// and map[string]any for objects, or the key itself for masked keys.
+	OriginalValue any
+}
+
+// MaskDryRun reports the nodes of the input JSON string that MaskWithPaths would mask
+// with the provided maskPaths, along with their original values, in traversal order,
+// without producing a masked document. Mask functions and replacers aren't called.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+func (m *masker) MaskDryRun(input string, maskPaths []string) ([]MaskHit, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return nil, err
+	}
+	state.isDryRun = true
+	if _, err := m.mask([]byte(input), state); err != nil {
+		return nil, err
+	}
+	return state.hits, nil
+}
+
+// recordHit records a node that was masked in dry run mode, see MaskDryRun.
+func (s *maskState) recordHit(path string, value any) {
+	s.hits = append(s.hits, MaskHit{Path: path, OriginalValue: value})
+}
@@ -0,0 +1,69 @@
+package masker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskDryRun(t *testing.T) {
+	input := `{"name":"John","ssn":"123","cards":[{"number":"4111","cvv":1}],"accounts":{"acc1":{"id":7}}}`
+	testTable := []struct {
+		name      string
+		maskPaths []string
+		opts      []option
+		expected  []MaskHit
+	}{
+		{
+			name:      "Test with leaves",
+			maskPaths: []string{"$.ssn", "$.cards[].number", "$.cards[].cvv"},
+			expected: []MaskHit{
+				{Path: "$.ssn", OriginalValue: "123"},
+				{Path: "$.cards[0].number", OriginalValue: "4111"},
+				{Path: "$.cards[0].cvv", OriginalValue: json.Number("1")},
+			},
+		},
+		{
+			name:      "Test with object",
+			maskPaths: []string{"$.accounts.acc1"},
+			expected: []MaskHit{
+				{Path: "$.accounts.acc1", OriginalValue: map[string]any{"id": json.Number("7")}},
+			},
+		},
+		{
+			name:      "Test with masked keys",
+			maskPaths: []string{"$.accounts.*~"},
+			expected: []MaskHit{
+				{Path: "$.accounts.acc1~", OriginalValue: "acc1"},
+			},
+		},
+		{
+			name:      "Test with no match",
+			maskPaths: []string{"$.missing"},
+		},
+		{
+			name:      "Test with mask function and drop",
+			maskPaths: []string{"$.name"},
+			opts: []option{WithDropMaskedFields(), WithMaskFunc(func(field any) string {
+				panic("mask function called in dry run")
+			})},
+			expected: []MaskHit{
+				{Path: "$.name", OriginalValue: "John"},
+			},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := NewMasker(nil, tt.opts...).MaskDryRun(input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, hits)
+		})
+	}
+
+	t.Run("Test with invalid json", func(t *testing.T) {
+		_, err := NewMasker(nil).MaskDryRun(`{`, nil)
+		assert.ErrorIs(t, err, ErrInvalidJSON)
+	})
+}
@@ -0,0 +1,24 @@
+package masker
+
+// DuplicateKeyMode defines how objects with duplicate keys are decoded, see WithDuplicateKeys.
+type DuplicateKeyMode int
+
+const (
+	// DuplicateKeysLastWins keeps a single member per key, at the position of its first occurrence
+	// with the value of its last one, like encoding/json does. It is the default.
+	DuplicateKeysLastWins DuplicateKeyMode = iota
+	// DuplicateKeysError makes masking return an error wrapping ErrInvalidJSON for documents with duplicate keys.
+	DuplicateKeysError
+	// DuplicateKeysKeepAll keeps every occurrence of duplicate keys in document order,
+	// every one of them being masked by the paths matching the key.
+	DuplicateKeysKeepAll
+)
+
+// WithDuplicateKeys sets how objects with duplicate keys are handled. By default only the last value
+// of a duplicate key is kept, which can hide a smuggled value, e.g. a second "role":"admin", from review.
+// MaskReader never buffers objects, so it writes every occurrence unless DuplicateKeysError is used.
+func WithDuplicateKeys(mode DuplicateKeyMode) option {
+	return func(m *masker) {
+		m.duplicates = mode
+	}
+}
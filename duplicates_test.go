@@ -0,0 +1,70 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask_duplicateKeys(t *testing.T) {
+	input := `{"user":{"role":"user","name":"John","role":"admin"}}`
+	testTable := []struct {
+		name           string
+		mode           DuplicateKeyMode
+		expected       string
+		expectedStream string
+		expectedErr    string
+	}{
+		{
+			name:           "Test with last wins",
+			mode:           DuplicateKeysLastWins,
+			expected:       `{"user":{"role":"[REDACTED]","name":"John"}}`,
+			expectedStream: `{"user":{"role":"[REDACTED]","name":"John","role":"[REDACTED]"}}`,
+		},
+		{
+			name:           "Test with keep all",
+			mode:           DuplicateKeysKeepAll,
+			expected:       `{"user":{"role":"[REDACTED]","name":"John","role":"[REDACTED]"}}`,
+			expectedStream: `{"user":{"role":"[REDACTED]","name":"John","role":"[REDACTED]"}}`,
+		},
+		{
+			name:        "Test with error",
+			mode:        DuplicateKeysError,
+			expectedErr: "failed to unmarshal input: duplicate key at path $.user.role",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker([]string{"$.user.role"}, WithDuplicateKeys(tt.mode))
+			output, err := m.Mask(input)
+			var out bytes.Buffer
+			streamErr := m.MaskReader(strings.NewReader(input), &out, nil)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.ErrorIs(t, err, ErrInvalidJSON)
+				assert.EqualError(t, streamErr, tt.expectedErr)
+				assert.ErrorIs(t, streamErr, ErrInvalidJSON)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.NoError(t, streamErr)
+			assert.Equal(t, tt.expectedStream, out.String())
+		})
+	}
+
+	t.Run("Test with keep all and masked keys", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.a~"}, WithDuplicateKeys(DuplicateKeysKeepAll)).Mask(`{"a":1,"b":2,"a":3}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"[REDACTED]":1,"b":2,"[REDACTED]_2":3}`, output)
+	})
+
+	t.Run("Test with keep all and report", func(t *testing.T) {
+		_, masked, err := NewMasker(nil, WithDuplicateKeys(DuplicateKeysKeepAll)).MaskWithReport(input, []string{"$..role"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.user.role", "$.user.role"}, masked)
+	})
+}
@@ -0,0 +1,42 @@
+package masker
+
+import "errors"
+
+var (
+	// ErrInvalidJSON is wrapped by the errors returned for input that isn't valid JSON.
+	ErrInvalidJSON = errors.New("invalid JSON")
+	// ErrInvalidPath is wrapped by the errors returned for mask paths that can't be parsed,
+	// by CompilePaths and for the regular expressions of WithRegexPaths.
+	ErrInvalidPath = errors.New("invalid mask path")
+	// ErrOutputTooLarge is wrapped by the errors returned when the masked output exceeds
+	// the limit set with WithMaxOutputBytes.
+	ErrOutputTooLarge = errors.New("masked output too large")
+	// ErrInvalidCSV is wrapped by the errors returned for the fields of WithCSVField
+	// that aren't valid CSV when CSVStrict is used.
+	ErrInvalidCSV = errors.New("invalid CSV")
+	// ErrInvalidConfig is wrapped by the errors returned by MaskerBuilder.Build for conflicting rules.
+	ErrInvalidConfig = errors.New("invalid masker configuration")
+	// ErrInvalidJWT is wrapped by the errors returned by MaskJWT for tokens that aren't JWTs.
+	ErrInvalidJWT = errors.New("invalid JWT")
+	// ErrInvalidYAML is wrapped by the errors returned by MaskYAML for input that isn't valid YAML.
+	ErrInvalidYAML = errors.New("invalid YAML")
+)
+
+// kindError is an error of a kind such as ErrInvalidJSON, keeping the message of its cause.
+type kindError struct {
+	kind error
+	err  error
+}
+
+// withKind wraps err so errors.Is reports it as kind, without changing its message.
+func withKind(kind error, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
@@ -0,0 +1,61 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrInvalidJSON(t *testing.T) {
+	inputs := []string{
+		"invalid",
+		`{"a":`,
+		`{"a":1}}`,
+		``,
+		`[1,2`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			masker := NewMasker([]string{"$.a"})
+			_, err := masker.Mask(input)
+			assert.True(t, errors.Is(err, ErrInvalidJSON), err)
+			assert.False(t, errors.Is(err, ErrInvalidPath))
+
+			var out bytes.Buffer
+			err = masker.MaskReader(strings.NewReader(input), &out, nil)
+			assert.True(t, errors.Is(err, ErrInvalidJSON), err)
+		})
+	}
+
+	t.Run("Test keeps the message and cause", func(t *testing.T) {
+		_, err := NewMasker(nil).Mask("invalid")
+		assert.EqualError(t, err, "failed to unmarshal input: invalid character 'i' looking for beginning of value")
+		var syntaxErr *json.SyntaxError
+		assert.True(t, errors.As(err, &syntaxErr))
+	})
+
+	t.Run("Test with raw message", func(t *testing.T) {
+		_, err := NewMasker(nil).MaskValue(map[string]any{"a": json.RawMessage(`{`)}, []string{"$.a.b"})
+		assert.True(t, errors.Is(err, ErrInvalidJSON), err)
+	})
+}
+
+func TestErrInvalidPath(t *testing.T) {
+	_, err := CompilePaths([]string{"$.users["})
+	assert.True(t, errors.Is(err, ErrInvalidPath), err)
+	assert.False(t, errors.Is(err, ErrInvalidJSON))
+
+	_, err = NewMasker([]string{"("}, WithRegexPaths()).Mask(`{}`)
+	assert.True(t, errors.Is(err, ErrInvalidPath), err)
+
+	_, err = NewMasker([]string{"$.items[0"}).Mask(`{"items": [1]}`)
+	assert.True(t, errors.Is(err, ErrInvalidPath), err)
+
+	_, err = NewMasker(nil).MaskWithPaths(`{"a": 1}`, []string{"$..", "$.a"})
+	assert.True(t, errors.Is(err, ErrInvalidPath), err)
+}
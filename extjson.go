@@ -0,0 +1,49 @@
+package masker
+
+// extendedJSONWrappers are the keys of the MongoDB extended JSON objects wrapping a single
+// value, e.g. {"$numberLong":"123"}, recognized with WithExtendedJSON.
+var extendedJSONWrappers = map[string]bool{
+	"$oid":           true,
+	"$date":          true,
+	"$numberInt":     true,
+	"$numberLong":    true,
+	"$numberDouble":  true,
+	"$numberDecimal": true,
+	"$symbol":        true,
+	"$uuid":          true,
+}
+
+// WithExtendedJSON masks MongoDB extended JSON values keeping their wrapper, so masking "$.amount"
+// in {"amount":{"$numberLong":"123"}} produces {"amount":{"$numberLong":"[REDACTED]"}}.
+// The wrappers are the objects with a single $oid, $date, $numberInt, $numberLong, $numberDouble,
+// $numberDecimal, $symbol or $uuid key holding a scalar or another wrapper, as $date may hold
+// a $numberLong. Mask functions receive the wrapped value, e.g. "123", and other objects are masked as usual.
+func WithExtendedJSON() option {
+	return func(m *masker) {
+		m.isExtendedJSON = true
+	}
+}
+
+// unwrapExtendedJSON returns the key and the wrapped value of an extended JSON wrapper,
+// ok being false if value isn't one, see WithExtendedJSON.
+func unwrapExtendedJSON(value any) (key string, wrapped any, ok bool) {
+	obj, isObject := value.(map[string]any)
+	if !isObject || len(obj) != 1 {
+		return "", nil, false
+	}
+	for k, v := range obj {
+		key, wrapped = k, v
+	}
+	if !extendedJSONWrappers[key] {
+		return "", nil, false
+	}
+	switch wrapped.(type) {
+	case map[string]any:
+		if _, _, ok := unwrapExtendedJSON(wrapped); !ok {
+			return "", nil, false
+		}
+	case []any:
+		return "", nil, false
+	}
+	return key, wrapped, true
+}
@@ -0,0 +1,82 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithExtendedJSON(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with $numberLong",
+			input:     `{"amount":{"$numberLong":"123"},"id":1}`,
+			maskPaths: []string{"$.amount"},
+			expected:  `{"amount":{"$numberLong":"[REDACTED]"},"id":1}`,
+		},
+		{
+			name:      "Test with $oid",
+			input:     `{"_id":{"$oid":"5f1d7a3b9c1e4b2a3c4d5e6f"}}`,
+			maskPaths: []string{"$._id"},
+			expected:  `{"_id":{"$oid":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with relaxed $date",
+			input:     `{"born":{"$date":"1990-01-01T00:00:00Z"}}`,
+			maskPaths: []string{"$.born"},
+			expected:  `{"born":{"$date":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with canonical $date",
+			input:     `{"born":{"$date":{"$numberLong":"631152000000"}}}`,
+			maskPaths: []string{"$.born"},
+			expected:  `{"born":{"$date":{"$numberLong":"[REDACTED]"}}}`,
+		},
+		{
+			name:      "Test with wrappers in arrays",
+			input:     `{"amounts":[{"$numberLong":"1"},{"$numberLong":"2"}]}`,
+			maskPaths: []string{"$.amounts[]"},
+			expected:  `{"amounts":[{"$numberLong":"[REDACTED]"},{"$numberLong":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with plain objects",
+			input:     `{"user":{"$numberLong":"1","name":"John"},"other":{"$unknown":"x"}}`,
+			maskPaths: []string{"$.user", "$.other"},
+			expected:  `{"user":"[REDACTED]","other":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with mask function",
+			input:     `{"amount":{"$numberLong":"123456"}}`,
+			maskPaths: []string{"$.amount"},
+			opts:      []option{WithPartialMask(2, '*')},
+			expected:  `{"amount":{"$numberLong":"****56"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, append(tt.opts, WithExtendedJSON())...)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test without extended JSON", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.amount"}).Mask(`{"amount":{"$numberLong":"123"}}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"amount":"[REDACTED]"}`, output)
+	})
+}
@@ -0,0 +1,43 @@
+package masker
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// WithIndent makes the masked documents pretty-printed like json.MarshalIndent does,
+// every element beginning on a new line with prefix followed by one copy of indent per level,
+// e.g. WithIndent("", "  "). It applies to Mask, MaskBytes and MaskReader among others,
+// but not to the JSON kept inside json.RawMessage values and strings, see WithNestedJSON.
+// Documents are compact by default.
+func WithIndent(prefix, indent string) option {
+	return func(m *masker) {
+		m.isIndent = true
+		m.indentPrefix, m.indent = prefix, indent
+	}
+}
+
+// marshal encodes the masked document, indented if WithIndent is used and escaped if WithASCIIOnly is.
+func (m *masker) marshal(value any) ([]byte, error) {
+	var data []byte
+	var err error
+	if m.isIndent {
+		data, err = json.MarshalIndent(value, m.indentPrefix, m.indent)
+	} else {
+		data, err = json.Marshal(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.encodeOutput(data), nil
+}
+
+// newline starts a new line for an element at the given depth when the output is indented.
+func (s *streamMasker) newline(depth int) {
+	if !s.masker.isIndent {
+		return
+	}
+	s.out.WriteByte('\n')
+	s.out.WriteString(s.masker.indentPrefix)
+	s.out.WriteString(strings.Repeat(s.masker.indent, depth))
+}
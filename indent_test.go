@@ -0,0 +1,72 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask_indent(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with nested containers",
+			input:     `{"name":"John","jobs":[{"id":1,"tags":["a"]}],"empty":{},"none":[]}`,
+			maskPaths: []string{"$.name", "$.jobs[].tags[]"},
+			opts:      []option{WithIndent("", "  ")},
+			expected: `{
+  "name": "[REDACTED]",
+  "jobs": [
+    {
+      "id": 1,
+      "tags": [
+        "[REDACTED]"
+      ]
+    }
+  ],
+  "empty": {},
+  "none": []
+}`,
+		},
+		{
+			name:      "Test with prefix and masked object",
+			input:     `{"a":{"b":1},"c":2}`,
+			maskPaths: []string{"$.a"},
+			opts: []option{WithIndent("> ", "\t"), WithReplacer(func(value any, path string) any {
+				return map[string]any{"masked": true}
+			})},
+			expected: "{\n> \t\"a\": {\n> \t\t\"masked\": true\n> \t},\n> \t\"c\": 2\n> }",
+		},
+		{
+			name:      "Test with scalar root",
+			input:     `"secret"`,
+			maskPaths: []string{"$"},
+			opts:      []option{WithIndent("", "  ")},
+			expected:  `"[REDACTED]"`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths, tt.opts...)
+			output, err := m.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			outputBytes, err := m.MaskBytes([]byte(tt.input))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(outputBytes))
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package masker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MaskJWT masks the claims of a JSON Web Token in its compact form, "header.payload.signature",
+// based on the provided claimPaths, e.g. "$.sub" or "$.email", returning a token with the same header
+// and the masked payload. The signature wouldn't match the masked payload and is dropped, the token
+// keeping its three parts with an empty signature, e.g. "eyJhbGci....eyJzdWIi....".
+// Tokens that don't have three parts, or whose header or payload aren't base64url-encoded JSON objects,
+// return an error wrapping ErrInvalidJWT.
+// A nil claimPaths falls back to the paths passed to NewMasker.
+func (m *masker) MaskJWT(token string, claimPaths []string) (string, error) {
+	state, err := m.newMaskState(claimPaths)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", withKind(ErrInvalidJWT, fmt.Errorf("failed to decode JWT: expected 3 parts, got %d", len(parts)))
+	}
+	if _, err := m.decodeJWTPart(parts[0]); err != nil {
+		return "", withKind(ErrInvalidJWT, fmt.Errorf("failed to decode JWT header: %w", err))
+	}
+	claims, err := m.decodeJWTPart(parts[1])
+	if err != nil {
+		return "", withKind(ErrInvalidJWT, fmt.Errorf("failed to decode JWT payload: %w", err))
+	}
+	masked, err := m.maskWithPaths(reflect.ValueOf(claims), state, rootPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to mask object: %w", err)
+	}
+	if err := state.unmatchedErr(); err != nil {
+		return "", err
+	}
+	if isDropped(masked) {
+		masked = nil
+	}
+	payload, err := json.Marshal(masked)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal masked object: %w", err)
+	}
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + ".", nil
+}
+
+// decodeJWTPart decodes the header or the payload of a JWT, a base64url-encoded JSON object.
+// Padding isn't part of JWTs but is tolerated.
+func (m *masker) decodeJWTPart(part string) (any, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeJSON(decoded, m.maxDepth, m.duplicates)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(*object); !ok {
+		return nil, errors.New("not a JSON object")
+	}
+	return value, nil
+}
@@ -0,0 +1,92 @@
+package masker
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskJWT(t *testing.T) {
+	encode := func(part string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(part))
+	}
+	header := encode(`{"alg":"HS256","typ":"JWT"}`)
+	token := header + "." + encode(`{"sub":"1234567890","email":"john@example.com","iat":1516239022}`) + ".c2lnbmF0dXJl"
+
+	testTable := []struct {
+		name        string
+		token       string
+		claimPaths  []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:       "Test with claims",
+			token:      token,
+			claimPaths: []string{"$.sub", "$.email"},
+			expected:   header + "." + encode(`{"sub":"[REDACTED]","email":"[REDACTED]","iat":1516239022}`) + ".",
+		},
+		{
+			name:       "Test without matching claims",
+			token:      token,
+			claimPaths: []string{"$.name"},
+			expected:   header + "." + encode(`{"sub":"1234567890","email":"john@example.com","iat":1516239022}`) + ".",
+		},
+		{
+			name:       "Test with nested claims",
+			token:      header + "." + encode(`{"user":{"email":"john@example.com"}}`) + ".",
+			claimPaths: []string{"$..email"},
+			expected:   header + "." + encode(`{"user":{"email":"[REDACTED]"}}`) + ".",
+		},
+		{
+			name:       "Test with padded payload",
+			token:      header + "." + base64.URLEncoding.EncodeToString([]byte(`{"sub":"1"}`)) + ".sig",
+			claimPaths: []string{"$.sub"},
+			expected:   header + "." + encode(`{"sub":"[REDACTED]"}`) + ".",
+		},
+		{
+			name:        "Test with two parts",
+			token:       header + "." + encode(`{}`),
+			claimPaths:  []string{"$.sub"},
+			expectedErr: "failed to decode JWT: expected 3 parts, got 2",
+		},
+		{
+			name:        "Test with invalid base64 header",
+			token:       "!!." + encode(`{}`) + ".sig",
+			claimPaths:  []string{"$.sub"},
+			expectedErr: "failed to decode JWT header: illegal base64 data at input byte 0",
+		},
+		{
+			name:        "Test with payload that isn't JSON",
+			token:       header + "." + encode(`hello`) + ".sig",
+			claimPaths:  []string{"$.sub"},
+			expectedErr: "failed to decode JWT payload: invalid character 'h' looking for beginning of value",
+		},
+		{
+			name:        "Test with payload that isn't an object",
+			token:       header + "." + encode(`[1]`) + ".sig",
+			claimPaths:  []string{"$.sub"},
+			expectedErr: "failed to decode JWT payload: not a JSON object",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masked, err := NewMasker(nil).MaskJWT(tt.token, tt.claimPaths)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.ErrorIs(t, err, ErrInvalidJWT)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+		})
+	}
+
+	t.Run("Test with masker paths", func(t *testing.T) {
+		masked, err := NewMasker([]string{"$.iat"}).MaskJWT(token, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, header+"."+encode(`{"sub":"1234567890","email":"john@example.com","iat":"[REDACTED]"}`)+".", masked)
+	})
+}
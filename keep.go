@@ -0,0 +1,48 @@
+package masker
+
+import "reflect"
+
+// WithKeepOnly turns the masker into an allow-list: every leaf value that isn't matched
+// by one of keepPaths, or below a node matched by one of them, is masked, whatever the mask paths.
+// The objects and arrays leading to the kept nodes are traversed and keep their keys,
+// so keeping "$.user.id" masks the siblings of id and of user but not the structure around them.
+// Keeping an object or an array, e.g. "$.user", keeps every value below it.
+// The mask paths still mask the nodes they match, including below kept nodes.
+// keepPaths use the same syntax as the mask paths, regular expressions with WithRegexPaths.
+func WithKeepOnly(keepPaths []string) option {
+	return func(m *masker) {
+		m.keepPaths = keepPaths
+	}
+}
+
+// isKept checks if the node at path or one of its ancestors is matched by a keep path.
+func (m *masker) isKept(path nodePath) bool {
+	for i := len(path); i > 0; i-- {
+		if m.keep.matches(path[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// masksLeaves checks if leaves may be masked whatever the mask paths,
+// see WithMaskAllOfKind, WithValueMatcher and WithKeepOnly.
+func (m *masker) masksLeaves() bool {
+	return len(m.maskKinds) > 0 || len(m.valueMatchers) > 0 || m.keep != nil
+}
+
+// masksLeaf checks if the leaf value at path should be masked because of its kind, its value,
+// because it isn't kept or because it is below a node matched by the mask paths which wasn't
+// masked as a whole for its exclusions, see maskState.matches. value is invalid for null leaves.
+func (m *masker) masksLeaf(value reflect.Value, state *maskState, path nodePath) bool {
+	if value.IsValid() && m.matchesKind(value) {
+		return true
+	}
+	if m.matchesValue(value) {
+		return true
+	}
+	if state.inheritsMask(path) {
+		return true
+	}
+	return m.keep != nil && !m.isKept(path)
+}
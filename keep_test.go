@@ -0,0 +1,100 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask_keepOnly(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		keepPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with kept leaf",
+			input:     `{"user":{"id":1,"name":"John","address":{"city":"Paris"}},"token":"abc"}`,
+			keepPaths: []string{"$.user.id"},
+			expected:  `{"user":{"id":1,"name":"[REDACTED]","address":{"city":"[REDACTED]"}},"token":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with kept object",
+			input:     `{"user":{"id":1,"address":{"city":"Paris"}},"token":"abc"}`,
+			keepPaths: []string{"$.user"},
+			expected:  `{"user":{"id":1,"address":{"city":"Paris"}},"token":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with kept array elements",
+			input:     `{"items":[{"id":1,"card":"4111"},{"id":2,"card":"4242"}],"tags":["a",null]}`,
+			keepPaths: []string{"$.items[].id"},
+			expected:  `{"items":[{"id":1,"card":"[REDACTED]"},{"id":2,"card":"[REDACTED]"}],"tags":["[REDACTED]","[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with wildcard",
+			input:     `{"a":{"id":1,"x":2},"b":{"id":3}}`,
+			keepPaths: []string{"$..id"},
+			expected:  `{"a":{"id":1,"x":"[REDACTED]"},"b":{"id":3}}`,
+		},
+		{
+			name:      "Test with mask path below kept node",
+			input:     `{"user":{"id":1,"ssn":"123"}}`,
+			maskPaths: []string{"$.user.ssn"},
+			keepPaths: []string{"$.user"},
+			expected:  `{"user":{"id":1,"ssn":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with nothing kept",
+			input:     `{"id":1,"list":[true]}`,
+			keepPaths: []string{},
+			expected:  `{"id":"[REDACTED]","list":["[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with dropped fields",
+			input:     `{"id":1,"name":"John","list":[1,2]}`,
+			keepPaths: []string{"$.id"},
+			opts:      []option{WithDropMaskedFields()},
+			expected:  `{"id":1,"list":[]}`,
+		},
+		{
+			name:      "Test with regex paths",
+			input:     `{"id":1,"name":"John"}`,
+			keepPaths: []string{`^\$\.id$`},
+			opts:      []option{WithRegexPaths()},
+			expected:  `{"id":1,"name":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths, append(tt.opts, WithKeepOnly(tt.keepPaths))...)
+			output, err := m.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with raw message", func(t *testing.T) {
+		masked, err := NewMasker(nil, WithKeepOnly([]string{"$.id"})).MaskValue(map[string]any{
+			"id":   1,
+			"data": json.RawMessage(`{"card":"4111"}`),
+		}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": 1, "data": json.RawMessage(`{"card":"[REDACTED]"}`)}, masked)
+	})
+
+	t.Run("Test with invalid regex", func(t *testing.T) {
+		_, err := NewMasker(nil, WithRegexPaths(), WithKeepOnly([]string{"("})).Mask(`{}`)
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+}
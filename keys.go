@@ -0,0 +1,67 @@
+package masker
+
+import "fmt"
+
+// maskKeys returns the object with the keys matching a key mask path (see keySuffix) masked.
+// Masked keys that collide with another key of the object get a numeric suffix,
+// e.g. two masked keys become "[REDACTED]" and "[REDACTED]_2".
+// Unmasked keys are never renamed.
+func (m *masker) maskKeys(obj *object, state *maskState, path nodePath) (*object, error) {
+	if len(state.maskPaths.keyPatterns) == 0 {
+		return obj, nil
+	}
+	masked := make([]bool, len(obj.keys))
+	taken := make(map[string]bool, len(obj.keys))
+	anyMasked := false
+	for i, key := range obj.keys {
+		switch matches := state.maskPaths.matchesKey(path.key(key)) && !m.isProtectedKey(key); {
+		case matches && m.isMasked(key):
+			m.log("Keeping masked key", logActionKeep, path.key(key))
+			state.recordKeptKey(path.key(key))
+			taken[key] = true
+		case matches:
+			masked[i] = true
+			anyMasked = true
+		default:
+			taken[key] = true
+		}
+	}
+	if !anyMasked {
+		return obj, nil
+	}
+
+	result := newObject()
+	for i, key := range obj.keys {
+		newKey := key
+		if masked[i] {
+			m.log("Masking key", logActionMaskKey, path.key(key))
+			state.recordMaskedKey(path.key(key))
+			if state.isDryRun {
+				state.recordHit(path.key(key).String()+keySuffix, key)
+			} else {
+				m.notifyMaskedKey(path.key(key), key)
+				state.recordOriginal(path.key(key).String()+keySuffix, key)
+				maskedKey, err := m.maskedKey(key, path.key(key))
+				if err != nil {
+					return nil, err
+				}
+				newKey = uniqueKey(maskedKey, taken)
+				taken[newKey] = true
+			}
+		}
+		result.add(newKey, obj.values[i])
+	}
+	return result, nil
+}
+
+// uniqueKey returns key, or key with the smallest numeric suffix that isn't taken.
+func uniqueKey(key string, taken map[string]bool) string {
+	if !taken[key] {
+		return key
+	}
+	for i := 2; ; i++ {
+		if candidate := fmt.Sprintf("%s_%d", key, i); !taken[candidate] {
+			return candidate
+		}
+	}
+}
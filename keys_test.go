@@ -0,0 +1,75 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask_keys(t *testing.T) {
+	testTable := []struct {
+		name          string
+		input         string
+		maskPaths     []string
+		expected      string
+		expectedPaths []string
+	}{
+		{
+			name:          "Test masking keys and leaving values intact",
+			input:         `{"accounts":{"4111111111111234":{"balance":1},"5500000000005678":{"balance":2}}}`,
+			maskPaths:     []string{"$.accounts.*~"},
+			expected:      `{"accounts":{"[REDACTED]":{"balance":1},"[REDACTED]_2":{"balance":2}}}`,
+			expectedPaths: []string{"$.accounts.4111111111111234~", "$.accounts.5500000000005678~"},
+		},
+		{
+			name:          "Test masking values and leaving keys intact",
+			input:         `{"accounts":{"4111111111111234":{"balance":1}}}`,
+			maskPaths:     []string{"$.accounts.*.balance"},
+			expected:      `{"accounts":{"4111111111111234":{"balance":"[REDACTED]"}}}`,
+			expectedPaths: []string{"$.accounts.4111111111111234.balance"},
+		},
+		{
+			name:          "Test masking keys and values below them",
+			input:         `{"accounts":{"4111111111111234":{"balance":1}}}`,
+			maskPaths:     []string{"$.accounts.*~", "$.accounts.*.balance"},
+			expected:      `{"accounts":{"[REDACTED]":{"balance":"[REDACTED]"}}}`,
+			expectedPaths: []string{"$.accounts.4111111111111234.balance", "$.accounts.4111111111111234~"},
+		},
+		{
+			name:          "Test masked key colliding with an unmasked key",
+			input:         `{"[REDACTED]":1,"secret":2}`,
+			maskPaths:     []string{"$.secret~"},
+			expected:      `{"[REDACTED]":1,"[REDACTED]_2":2}`,
+			expectedPaths: []string{"$.secret~"},
+		},
+		{
+			name:          "Test masking a single key in arrays",
+			input:         `{"users":[{"ssn":"1","name":"a"}]}`,
+			maskPaths:     []string{"$.users[].ssn~"},
+			expected:      `{"users":[{"[REDACTED]":"1","name":"a"}]}`,
+			expectedPaths: []string{"$.users[0].ssn~"},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, maskedPaths, err := masker.MaskWithReport(tt.input, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.Equal(t, tt.expectedPaths, maskedPaths)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestUniqueKey(t *testing.T) {
+	taken := map[string]bool{"a": true, "a_2": true}
+	assert.Equal(t, "b", uniqueKey("b", taken))
+	assert.Equal(t, "a_3", uniqueKey("a", taken))
+}
@@ -0,0 +1,46 @@
+package masker
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+var jsonNumberType = reflect.TypeOf(json.Number(""))
+
+// WithMaskAllStrings masks every string leaf of the documents, whatever its path.
+// Numbers, booleans and null are kept, and the mask paths still mask the nodes they match.
+func WithMaskAllStrings() option {
+	return WithMaskAllOfKind(reflect.String)
+}
+
+// WithMaskAllOfKind masks every leaf value of the given kinds, whatever its path,
+// e.g. reflect.Bool masks every boolean. The mask paths still mask the nodes they match.
+// Numbers of JSON documents are of kind reflect.Float64, like json.Unmarshal decodes them,
+// even though they are handed to mask functions as json.Number.
+// With WithDropMaskedFields, MaskReader buffers the whole document to remove the masked leaves.
+func WithMaskAllOfKind(kinds ...reflect.Kind) option {
+	return func(m *masker) {
+		if m.maskKinds == nil {
+			m.maskKinds = make(map[reflect.Kind]bool)
+		}
+		for _, kind := range kinds {
+			m.maskKinds[kind] = true
+		}
+	}
+}
+
+// matchesKind checks if the leaf value should be masked because of its kind.
+func (m *masker) matchesKind(value reflect.Value) bool {
+	if len(m.maskKinds) == 0 {
+		return false
+	}
+	return m.maskKinds[leafKind(value)]
+}
+
+// leafKind returns the kind of a leaf value, json.Number being a reflect.Float64.
+func leafKind(value reflect.Value) reflect.Kind {
+	if value.Type() == jsonNumberType {
+		return reflect.Float64
+	}
+	return value.Kind()
+}
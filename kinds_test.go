@@ -0,0 +1,84 @@
+package masker
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask_maskAllOfKind(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:     "Test with all strings",
+			input:    `{"name":"John","age":30,"active":true,"tags":["a",1],"address":{"city":"Paris","zip":75001},"none":null}`,
+			opts:     []option{WithMaskAllStrings()},
+			expected: `{"name":"[REDACTED]","age":30,"active":true,"tags":["[REDACTED]",1],"address":{"city":"[REDACTED]","zip":75001},"none":null}`,
+		},
+		{
+			name:      "Test with all strings and paths",
+			input:     `{"name":"John","age":30,"active":true}`,
+			maskPaths: []string{"$.age"},
+			opts:      []option{WithMaskAllStrings()},
+			expected:  `{"name":"[REDACTED]","age":"[REDACTED]","active":true}`,
+		},
+		{
+			name:      "Test with all strings and mask function for path",
+			input:     `{"name":"John","email":"john@example.com"}`,
+			maskPaths: []string{"$.email"},
+			opts: []option{WithMaskAllStrings(), WithMaskFuncForPath("$.email", func(field any) string {
+				return "email"
+			})},
+			expected: `{"name":"[REDACTED]","email":"email"}`,
+		},
+		{
+			name:     "Test with numbers and booleans",
+			input:    `{"name":"John","age":30,"score":1.5,"active":true}`,
+			opts:     []option{WithMaskAllOfKind(reflect.Float64, reflect.Bool)},
+			expected: `{"name":"John","age":"[REDACTED]","score":"[REDACTED]","active":"[REDACTED]"}`,
+		},
+		{
+			name:     "Test with scalar root",
+			input:    `"4111"`,
+			opts:     []option{WithMaskAllStrings()},
+			expected: `"[REDACTED]"`,
+		},
+		{
+			name:     "Test with dropped strings",
+			input:    `{"name":"John","age":30,"tags":["a",1,"b"]}`,
+			opts:     []option{WithMaskAllStrings(), WithDropMaskedFields()},
+			expected: `{"age":30,"tags":[1]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, tt.opts...)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		type account struct {
+			Name string
+			Age  int
+		}
+		masked, err := NewMasker(nil, WithMaskAllStrings()).MaskValue(account{Name: "John", Age: 30}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, account{Name: DefaultMaskString, Age: 30}, masked)
+	})
+}
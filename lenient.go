@@ -0,0 +1,167 @@
+package masker
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// WithLenientJSON accepts documents with // and /* */ comments and trailing commas in objects
+// and arrays, as found in JSON5-ish configuration files, which are otherwise invalid JSON.
+// They are masked like any document, the masked output being strict JSON:
+// comments are removed and, as always, the formatting of the input isn't preserved.
+// Other JSON5 extensions, such as single-quoted strings or unquoted keys, are still invalid.
+func WithLenientJSON() option {
+	return func(m *masker) {
+		m.isLenient = true
+	}
+}
+
+// decodeInput decodes an input document like decodeJSON does,
+// first removing its comments and trailing commas if WithLenientJSON is used.
+func (m *masker) decodeInput(input []byte) (any, error) {
+	if m.isLenient {
+		var err error
+		if input, err = io.ReadAll(newLenientReader(bytes.NewReader(input))); err != nil {
+			return nil, err
+		}
+	}
+	return decodeJSON(input, m.maxDepth, m.duplicates)
+}
+
+// lenientReader strips the comments and trailing commas of the JSON read from r, see WithLenientJSON.
+// They are replaced with spaces, newlines aside, for the other tokens to keep their offsets in the decoding errors.
+type lenientReader struct {
+	r   *bufio.Reader
+	out []byte
+	// inString and escaped track the strings of the input, which are copied as they are.
+	inString, escaped bool
+	// comma is set when a comma was read, it is only written once the next token shows it isn't trailing.
+	comma bool
+}
+
+func newLenientReader(r io.Reader) *lenientReader {
+	return &lenientReader{r: bufio.NewReader(r)}
+}
+
+func (l *lenientReader) Read(p []byte) (int, error) {
+	for len(l.out) == 0 {
+		if err := l.next(); err != nil {
+			if err == io.EOF && l.comma {
+				// a trailing comma at the top level is an error left to the decoder
+				l.comma = false
+				l.out = append(l.out, ',')
+				break
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, l.out)
+	l.out = l.out[n:]
+	return n, nil
+}
+
+// next reads the next byte of the input, appending what it stands for to out.
+func (l *lenientReader) next() error {
+	c, err := l.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if l.inString {
+		switch {
+		case l.escaped:
+			l.escaped = false
+		case c == '\\':
+			l.escaped = true
+		case c == '"':
+			l.inString = false
+		}
+		l.out = append(l.out, c)
+		return nil
+	}
+	switch c {
+	case ' ', '\t', '\r', '\n':
+		l.out = append(l.out, c)
+	case '/':
+		return l.skipComment()
+	case ',':
+		l.releaseComma()
+		l.comma = true
+	case '}', ']':
+		if l.comma {
+			l.comma = false
+			l.out = append(l.out, ' ')
+		}
+		l.out = append(l.out, c)
+	default:
+		l.releaseComma()
+		l.inString = c == '"'
+		l.out = append(l.out, c)
+	}
+	return nil
+}
+
+// releaseComma writes the pending comma, which turned out not to be trailing.
+func (l *lenientReader) releaseComma() {
+	if l.comma {
+		l.comma = false
+		l.out = append(l.out, ',')
+	}
+}
+
+// skipComment skips the comment whose leading slash was just read.
+// A slash that doesn't start a comment is kept, for the decoder to report it.
+func (l *lenientReader) skipComment() error {
+	c, err := l.r.ReadByte()
+	if err == io.EOF {
+		l.out = append(l.out, '/')
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '/':
+		l.out = append(l.out, ' ', ' ')
+		for {
+			c, err := l.r.ReadByte()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if c == '\n' {
+				l.out = append(l.out, c)
+				return nil
+			}
+			l.out = append(l.out, ' ')
+		}
+	case '*':
+		l.out = append(l.out, ' ', ' ')
+		star := false
+		for {
+			c, err := l.r.ReadByte()
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return err
+			}
+			if star && c == '/' {
+				l.out = append(l.out, ' ')
+				return nil
+			}
+			star = c == '*'
+			if c == '\n' {
+				l.out = append(l.out, c)
+			} else {
+				l.out = append(l.out, ' ')
+			}
+		}
+	default:
+		l.releaseComma()
+		l.out = append(l.out, '/')
+		return l.r.UnreadByte()
+	}
+}
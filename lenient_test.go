@@ -0,0 +1,98 @@
+package masker
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLenientJSON(t *testing.T) {
+	testTable := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "Test with trailing commas",
+			input:    `{"ssn":"123","tags":["a","b",],"empty":[],}`,
+			expected: `{"ssn":"[REDACTED]","tags":["a","b"],"empty":[]}`,
+		},
+		{
+			name: "Test with line comments",
+			input: `{
+	// the user's ssn
+	"ssn": "123", // masked
+	"url": "http://example.com" // slashes in strings are kept
+}`,
+			expected: `{"ssn":"[REDACTED]","url":"http://example.com"}`,
+		},
+		{
+			name:     "Test with block comments",
+			input:    `/* header */ {"ssn": /* inline */ "123", "note": "/* not a comment */"}`,
+			expected: `{"ssn":"[REDACTED]","note":"/* not a comment */"}`,
+		},
+		{
+			name:     "Test with trailing comma before a comment",
+			input:    "{\"ssn\":\"123\",\"a\":[1, // last\n],\n}",
+			expected: `{"ssn":"[REDACTED]","a":[1]}`,
+		},
+		{
+			name:     "Test with escaped quote in a string",
+			input:    `{"ssn":"1\"2,]","a":1,}`,
+			expected: `{"ssn":"[REDACTED]","a":1}`,
+		},
+		{
+			name:        "Test with double comma",
+			input:       `{"ssn":"123",,}`,
+			expectedErr: "failed to unmarshal input: invalid character ',' looking for beginning of value",
+		},
+		{
+			name:        "Test with unterminated block comment",
+			input:       `{"ssn":"123"} /* `,
+			expectedErr: "failed to unmarshal input: unexpected EOF",
+		},
+		{
+			name:        "Test with single-quoted string",
+			input:       `{'ssn':"123"}`,
+			expectedErr: "failed to unmarshal input: invalid character '\\'' looking for beginning of value",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker([]string{"$.ssn"}, WithLenientJSON())
+			masked, err := masker.Mask(tt.input)
+			var out bytes.Buffer
+			streamErr := masker.MaskReader(strings.NewReader(tt.input), &out, nil)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.ErrorIs(t, err, ErrInvalidJSON)
+				assert.Error(t, streamErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+			assert.NoError(t, streamErr)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test without lenient JSON", func(t *testing.T) {
+		_, err := NewMasker([]string{"$.ssn"}).Mask(`{"ssn":"123",}`)
+		assert.ErrorIs(t, err, ErrInvalidJSON)
+	})
+}
+
+func TestLenientReader(t *testing.T) {
+	// comments and trailing commas become spaces and commas are only written once the next token is read,
+	// so the output is as long as the input
+	input := "{\"a\":1, // c\n\"b\":[2,/* d */],}"
+	stripped, err := io.ReadAll(newLenientReader(strings.NewReader(input)))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"a\":1     \n,\"b\":[2        ] }", string(stripped))
+	assert.Len(t, stripped, len(input))
+}
@@ -0,0 +1,54 @@
+package masker
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithMaxOutputBytes limits the masked output to n bytes, as mask functions may make values
+// longer, e.g. WithLengthPreservingMask on long strings. Mask calls producing a larger output
+// return an error wrapping ErrOutputTooLarge instead. MaskReader and MaskLines stop writing
+// before the limit is crossed, the limit applying to everything they write to w.
+// A limit of 0 or less, the default, doesn't limit the output.
+func WithMaxOutputBytes(n int) option {
+	return func(m *masker) {
+		m.maxOutputBytes = n
+	}
+}
+
+// outputTooLarge returns the error for an output exceeding the limit of WithMaxOutputBytes.
+func (m *masker) outputTooLarge() error {
+	return withKind(ErrOutputTooLarge, fmt.Errorf("masked output exceeds %d bytes", m.maxOutputBytes))
+}
+
+// checkOutputSize checks that size bytes of masked output don't exceed the limit of WithMaxOutputBytes.
+func (m *masker) checkOutputSize(size int) error {
+	if m.maxOutputBytes > 0 && size > m.maxOutputBytes {
+		return m.outputTooLarge()
+	}
+	return nil
+}
+
+// limitOutput returns w, failing the writes that would exceed the limit of WithMaxOutputBytes.
+func (m *masker) limitOutput(w io.Writer) io.Writer {
+	if m.maxOutputBytes <= 0 {
+		return w
+	}
+	return &limitWriter{masker: m, w: w, remaining: m.maxOutputBytes}
+}
+
+// limitWriter writes to w until remaining bytes have been written, see WithMaxOutputBytes.
+type limitWriter struct {
+	masker    *masker
+	w         io.Writer
+	remaining int
+}
+
+// Write writes p entirely, or nothing of it if it would exceed the limit.
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		return 0, l.masker.outputTooLarge()
+	}
+	l.remaining -= len(p)
+	return l.w.Write(p)
+}
@@ -0,0 +1,76 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMaxOutputBytes(t *testing.T) {
+	// every masked value becomes 100 characters long
+	expanding := WithMaskFunc(func(field any) string {
+		return strings.Repeat("*", 100)
+	})
+	testTable := []struct {
+		name      string
+		input     string
+		limit     int
+		expectErr bool
+	}{
+		{
+			name:  "Test under the limit",
+			input: `{"ssn":"1"}`,
+			limit: 200,
+		},
+		{
+			name:  "Test at the limit",
+			input: `{"ssn":"1"}`,
+			limit: len(`{"ssn":""}`) + 100,
+		},
+		{
+			name:      "Test over the limit",
+			input:     `{"ssn":"1"}`,
+			limit:     len(`{"ssn":""}`) + 99,
+			expectErr: true,
+		},
+		{
+			name:      "Test with expanded values",
+			input:     `[{"ssn":"1"},{"ssn":"2"},{"ssn":"3"}]`,
+			limit:     250,
+			expectErr: true,
+		},
+		{
+			name:  "Test without limit",
+			input: `[{"ssn":"1"},{"ssn":"2"},{"ssn":"3"}]`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker([]string{"$..ssn"}, expanding, WithMaxOutputBytes(tt.limit))
+			output, err := masker.Mask(tt.input)
+			var out bytes.Buffer
+			streamErr := masker.MaskReader(strings.NewReader(tt.input), &out, nil)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrOutputTooLarge)
+				assert.Empty(t, output)
+				assert.ErrorIs(t, streamErr, ErrOutputTooLarge)
+				assert.LessOrEqual(t, out.Len(), tt.limit)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, streamErr)
+			assert.Equal(t, output, out.String())
+		})
+	}
+
+	t.Run("Test with lines", func(t *testing.T) {
+		masker := NewMasker([]string{"$.ssn"}, expanding, WithMaxOutputBytes(250))
+		var out bytes.Buffer
+		err := masker.MaskLines(strings.NewReader("{\"ssn\":\"1\"}\n{\"ssn\":\"2\"}\n{\"ssn\":\"3\"}\n"), &out, nil)
+		assert.ErrorIs(t, err, ErrOutputTooLarge)
+		assert.LessOrEqual(t, out.Len(), 250)
+	})
+}
@@ -0,0 +1,91 @@
+package masker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WithSkipInvalidLines makes MaskLines leave out the lines it fails to mask, e.g. malformed JSON,
+// instead of aborting. Skipped lines are never written, not even unmasked.
+func WithSkipInvalidLines() option {
+	return func(m *masker) {
+		m.isSkipInvalidLines = true
+	}
+}
+
+// MaskLines reads newline delimited JSON (JSON Lines) from r, masks every line as an independent
+// document based on the provided maskPaths and writes the masked lines to w.
+// Line boundaries are preserved: every masked document is written on a single line, even with
+// WithIndent, followed by the line ending of the input line ("\n" or "\r\n"), and the last line
+// only ends with a newline if the input does. Blank lines are written as empty lines without being masked.
+// By default, masking stops at the first line that fails, returning an error with its line number,
+// see WithSkipInvalidLines to skip such lines instead.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+// If an error is returned, the lines before the failing one have been written to w.
+func (m *masker) MaskLines(r io.Reader, w io.Writer, maskPaths []string) error {
+	template, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return err
+	}
+	in := bufio.NewReader(r)
+	out := bufio.NewWriter(m.limitOutput(w))
+	for number := 1; ; number++ {
+		line, readErr := in.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("failed to read line %d: %w", number, readErr)
+		}
+		if len(line) == 0 && readErr == io.EOF {
+			break
+		}
+		content, ending := splitLineEnding(line)
+		if len(bytes.TrimSpace(content)) > 0 {
+			masked, err := m.maskLine(content, template.clone())
+			if err != nil {
+				if !m.isSkipInvalidLines {
+					out.Flush()
+					return fmt.Errorf("line %d: %w", number, err)
+				}
+				ending = nil
+			}
+			if _, err := out.Write(masked); err != nil {
+				return fmt.Errorf("failed to write masked lines: %w", err)
+			}
+		}
+		if _, err := out.Write(ending); err != nil {
+			return fmt.Errorf("failed to write masked lines: %w", err)
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("failed to write masked lines: %w", err)
+	}
+	return nil
+}
+
+// maskLine masks a single line of MaskLines, keeping the masked document on one line.
+func (m *masker) maskLine(line []byte, state *maskState) ([]byte, error) {
+	masked, err := m.mask(line, state)
+	if err != nil || !m.isIndent {
+		return masked, err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, masked); err != nil {
+		return nil, fmt.Errorf("failed to marshal masked object: %w", err)
+	}
+	return compact.Bytes(), nil
+}
+
+// splitLineEnding splits line into its content and its line ending, "\n", "\r\n" or none.
+func splitLineEnding(line []byte) (content, ending []byte) {
+	content = bytes.TrimSuffix(line, []byte("\n"))
+	if len(content) == len(line) {
+		return line, nil
+	}
+	content = bytes.TrimSuffix(content, []byte("\r"))
+	return content, line[len(content):]
+}
@@ -0,0 +1,71 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskLines(t *testing.T) {
+	testTable := []struct {
+		name        string
+		input       string
+		options     []option
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "Test with multiple lines",
+			input:    "{\"name\":\"John\",\"ssn\":\"123\"}\n{\"ssn\":\"456\"}\n{\"name\":\"Jane\"}\n",
+			expected: "{\"name\":\"John\",\"ssn\":\"[REDACTED]\"}\n{\"ssn\":\"[REDACTED]\"}\n{\"name\":\"Jane\"}\n",
+		},
+		{
+			name:     "Test without trailing newline",
+			input:    "{\"ssn\":\"123\"}\n{\"ssn\":\"456\"}",
+			expected: "{\"ssn\":\"[REDACTED]\"}\n{\"ssn\":\"[REDACTED]\"}",
+		},
+		{
+			name:     "Test with blank lines and CRLF",
+			input:    "{\"ssn\":\"123\"}\r\n\r\n  \n{\"ssn\":\"456\"}\n",
+			expected: "{\"ssn\":\"[REDACTED]\"}\r\n\r\n\n{\"ssn\":\"[REDACTED]\"}\n",
+		},
+		{
+			name:     "Test with indent",
+			input:    "{\"a\":{\"ssn\":\"123\"}}\n",
+			options:  []option{WithIndent("", "  ")},
+			expected: "{\"a\":{\"ssn\":\"123\"}}\n",
+		},
+		{
+			name:     "Test with empty input",
+			input:    "",
+			expected: "",
+		},
+		{
+			name:        "Test with invalid line",
+			input:       "{\"ssn\":\"123\"}\n{\"ssn\":\n{\"ssn\":\"456\"}\n",
+			expected:    "{\"ssn\":\"[REDACTED]\"}\n",
+			expectedErr: "line 2: failed to unmarshal input",
+		},
+		{
+			name:     "Test with skipped invalid line",
+			input:    "{\"ssn\":\"123\"}\n{\"ssn\":\n{\"ssn\":\"456\"}",
+			options:  []option{WithSkipInvalidLines()},
+			expected: "{\"ssn\":\"[REDACTED]\"}\n{\"ssn\":\"[REDACTED]\"}",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := NewMasker([]string{"$.ssn"}, tt.options...).MaskLines(strings.NewReader(tt.input), &out, nil)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
@@ -1,22 +1,154 @@
 package masker
 
 import (
+	"context"
+	"encoding"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
 	"reflect"
-	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-var removeIndexRegex = regexp.MustCompile(`\[\d+\]`)
+const (
+	// DefaultMaskString is the value masked fields are replaced with when no mask
+	// function is configured.
+	DefaultMaskString = "[REDACTED]"
+	// DefaultMaxDepth is the maximum nesting depth of a document when WithMaxDepth isn't used.
+	DefaultMaxDepth = 1000
+	// logActionProcess, logActionMask, logActionMaskKey and logActionKeep are the actions logged for nodes.
+	logActionProcess = "process"
+	logActionMask    = "mask"
+	logActionMaskKey = "mask_key"
+	logActionKeep    = "keep"
+	// contextCheckInterval is the number of nodes traversed between checks of the context.
+	contextCheckInterval = 1024
+)
 
+// Masker masks JSON documents and Go values based on mask paths.
+// A Masker is immutable once created by NewMasker and is safe for concurrent use
+// by multiple goroutines, as long as the configured callbacks, e.g. mask functions,
+// are safe for concurrent use as well. Inputs are never modified, so the same input
+// can also be masked concurrently.
 type Masker interface {
-	Mask(data string, maskPaths []string) (string, error)
-	log(data string)
+	Mask(data string) (string, error)
+	MaskWithPaths(data string, maskPaths []string) (string, error)
+	MaskBytes(data []byte) ([]byte, error)
+	MaskWithReport(data string, maskPaths []string) (string, []string, error)
+	MaskWithVault(data string, maskPaths []string) (string, map[string]any, error)
+	MaskWithStats(data string, maskPaths []string) (string, map[string]int, error)
+	MaskSubtree(data string, rootPath string, relPaths []string) (string, error)
+	MaskContext(ctx context.Context, data string, maskPaths []string) (string, error)
+	MaskValue(v any, maskPaths []string) (any, error)
+	MaskDryRun(data string, maskPaths []string) ([]MaskHit, error)
+	MaskDebugPaths(data string) ([]string, error)
+	MaskBatch(data []string, maskPaths []string) ([]string, []error)
+	MaskYAML(data []byte, maskPaths []string) ([]byte, error)
+	MaskXML(data []byte, maskPaths []string) ([]byte, error)
+	MaskJWT(token string, claimPaths []string) (string, error)
+	MaskReader(r io.Reader, w io.Writer, maskPaths []string) error
+	MaskLines(r io.Reader, w io.Writer, maskPaths []string) error
+	MaskStruct(v any) ([]byte, error)
+	log(msg string, action string, path nodePath)
 }
 
 type masker struct {
-	maskFunc    func(field any) string
-	isDebugMode bool
+	maskPaths []string
+	maskFunc  func(field any) string
+	// pathMaskFunc replaces maskFunc when set, see WithMaskFuncContext.
+	pathMaskFunc func(field any, path string) string
+	// keyMaskFunc replaces maskFunc when set, see WithKeyMaskFunc.
+	keyMaskFunc func(field any, key string) string
+	// errMaskFunc replaces maskFunc when set, see WithMaskFuncError.
+	errMaskFunc func(field any) (string, error)
+	// nullMaskFunc replaces maskFunc when set, see WithMaskNullsDistinctly.
+	nullMaskFunc func(field any, isNull bool) string
+	pathFuncs    map[string]func(field any) string
+	// patternFuncs are the mask functions registered for paths with wildcards, see WithMaskFuncForPath.
+	patternFuncs []patternFunc
+	// typeFuncs are the mask functions registered by kind of value, see WithMaskFuncForType.
+	typeFuncs  map[reflect.Kind]func(value any) any
+	replacer   func(value any, path string) any
+	conditions []conditionalMask
+	// slicePredicates are the predicates of WithSlicePredicate.
+	slicePredicates []slicePredicate
+	matchers        []PathMatcher
+	maskKinds       map[reflect.Kind]bool
+	// valueMatchers are the matchers of WithValueMatcher.
+	valueMatchers []func(value any) bool
+	isDebugMode   bool
+	logger        *slog.Logger
+	isStrict      bool
+	isRegex       bool
+	isJSONPointer bool
+	isDrop        bool
+	isKeepNulls   bool
+	isKeepEmpty   bool
+	// isRawPassthrough is set by WithRawPassthrough.
+	isRawPassthrough bool
+	// isASCIIOnly is set by WithASCIIOnly.
+	isASCIIOnly bool
+	// optionPaths are the paths of the options, registered once compiled, see compileOptionPaths.
+	optionPaths []optionPath
+	// protectedKeys are the keys of WithProtectedKeys.
+	protectedKeys map[string]bool
+	// maskedMarker is the prefix of the strings already masked, see WithSkipMasked.
+	maskedMarker string
+	isDeepMask   bool
+	isLenient    bool
+	// concurrency is the number of goroutines masking the elements of large arrays, see WithConcurrency.
+	concurrency int
+	// isExtendedJSON keeps the wrappers of extended JSON values when masking them, see WithExtendedJSON.
+	isExtendedJSON bool
+	duplicates     DuplicateKeyMode
+	// batchWorkers is the number of documents MaskBatch masks concurrently, see WithBatchConcurrency.
+	batchWorkers int
+	// onMask is called for every masked value, see WithOnMask.
+	onMask func(path string, original any)
+	// maxOutputBytes limits the size of the masked output, see WithMaxOutputBytes.
+	maxOutputBytes int
+	// isSkipInvalidLines makes MaskLines skip the lines it fails to mask, see WithSkipInvalidLines.
+	isSkipInvalidLines bool
+	// normalizer normalizes the keys of the paths before matching, see WithFieldNameNormalizer.
+	normalizer func(string) string
+	// isIndent is set by WithIndent, with the prefix and indent of the lines.
+	isIndent     bool
+	indentPrefix string
+	indent       string
+	maxDepth     int
+	// arrayToken is the path segment matching every array index, see WithArrayToken.
+	arrayToken string
+	// paths is the compiled set of maskPaths.
+	paths pathSet
+	// keepPaths are the paths of WithKeepOnly, and keep their compiled set, nil if unused.
+	keepPaths []string
+	keep      *pathSet
+	// nestedPaths are the paths of WithNestedJSON, and nested their compiled set, nil if unused.
+	nestedPaths    []string
+	nested         *pathSet
+	isStrictNested bool
+	// base64Paths and base64InnerPaths are the paths of WithBase64JSON, and base64 and base64Inner
+	// their compiled sets, base64 being nil if unused.
+	base64Paths      []string
+	base64InnerPaths []string
+	base64           *pathSet
+	base64Inner      pathSet
+	// csvFields are the fields of WithCSVField.
+	csvFields []csvField
+	// regexValues are the regular expressions of WithRegexValueMask.
+	regexValues []regexValueMask
+	// buckets are the bucket sizes of WithNumericBucket.
+	buckets []numericBucket
+	// typeTags are the rules of WithTypeTagRule.
+	typeTags []typeTagRule
+	// compiled holds the paths set with WithCompiledPaths, nil if maskPaths are used.
+	compiled *CompiledPaths
+	// err is the configuration error returned by every mask call, e.g. an invalid regex path.
+	err error
 }
 
 type option func(*masker)
@@ -24,6 +156,66 @@ type option func(*masker)
 func WithMaskFunc(maskFunc func(field any) string) option {
 	return func(m *masker) {
 		m.maskFunc = maskFunc
+		m.pathMaskFunc = nil
+		m.keyMaskFunc = nil
+		m.errMaskFunc = nil
+		m.nullMaskFunc = nil
+	}
+}
+
+// WithMaskFuncContext sets the global mask function to maskFunc, which also receives the concrete
+// path of the masked node, e.g. "$.items[3].card", so a single function can pick a strategy per field.
+// Masked keys are passed with the ~ suffix, e.g. "$.accounts.acc1~".
+// It, WithKeyMaskFunc, WithMaskFuncError, WithMaskNullsDistinctly and WithMaskFunc replace each other, the last one applied winning.
+// They have a lower precedence than WithMaskFuncForPath, WithMaskFuncForType and WithReplacer.
+func WithMaskFuncContext(maskFunc func(field any, path string) string) option {
+	return func(m *masker) {
+		m.pathMaskFunc = maskFunc
+		m.keyMaskFunc = nil
+		m.errMaskFunc = nil
+		m.nullMaskFunc = nil
+	}
+}
+
+// WithKeyMaskFunc sets the global mask function to maskFunc, which also receives the key of the masked
+// node, so the masked value can tell what was removed, e.g. "[REDACTED:email]". Array elements get
+// the key of their array, e.g. "cards" for "$.cards[2]", and the root and the elements of a top-level
+// array an empty key. Masked keys are passed as both the value and the key.
+// It replaces WithMaskFunc and WithMaskFuncContext like they replace each other.
+func WithKeyMaskFunc(maskFunc func(field any, key string) string) option {
+	return func(m *masker) {
+		m.keyMaskFunc = maskFunc
+		m.pathMaskFunc = nil
+		m.errMaskFunc = nil
+		m.nullMaskFunc = nil
+	}
+}
+
+// WithMaskFuncError sets the global mask function to maskFunc, which can fail, e.g. when it calls
+// an external tokenization service. The error of the first value it fails to mask is returned
+// by the mask call, wrapped with the path of the node, e.g. "mask function failed at path $.ssn: ...".
+// Masked keys are passed to it as well. It replaces WithMaskFunc and WithMaskFuncContext
+// like they replace each other.
+func WithMaskFuncError(maskFunc func(field any) (string, error)) option {
+	return func(m *masker) {
+		m.errMaskFunc = maskFunc
+		m.pathMaskFunc = nil
+		m.keyMaskFunc = nil
+		m.nullMaskFunc = nil
+	}
+}
+
+// WithMaskNullsDistinctly sets the global mask function to maskFunc, which also receives whether
+// the masked value was null, so a redacted null can be told apart from a redacted value, e.g.
+// "[NULL-REDACTED]" and "[REDACTED]". Nil pointers and interfaces passed to MaskValue are null too,
+// and masked keys never are. It replaces WithMaskFunc and WithMaskFuncContext like they replace each other.
+// Nulls are only masked when a mask path matches them, and are left as they are with WithKeepNulls.
+func WithMaskNullsDistinctly(maskFunc func(field any, isNull bool) string) option {
+	return func(m *masker) {
+		m.nullMaskFunc = maskFunc
+		m.pathMaskFunc = nil
+		m.keyMaskFunc = nil
+		m.errMaskFunc = nil
 	}
 }
 
@@ -33,6 +225,142 @@ func WithFixedMaskString(maskStr string) option {
 	})
 }
 
+// WithMaskFuncForPath registers a mask function used only for the given path,
+// instead of the global mask function. The path must still be one of the mask paths
+// to be masked, and array indexes are matched using the [] syntax, e.g. "$.cards[].number".
+// The path may contain wildcards, e.g. "$..ssn". When several registered paths match a node,
+// the most specific one wins: a path without wildcards always wins, then the path made
+// of the most keys and indexes, see pathPattern.specificity. Among equally specific paths,
+// the last registered wins.
+func WithMaskFuncForPath(path string, maskFunc func(field any) string) option {
+	return withOptionPath(path, func(m *masker, path string, pattern pathPattern) {
+		if !pattern.keys && pattern.hasWildcards() {
+			m.patternFuncs = append(m.patternFuncs, patternFunc{pattern: pattern, maskFunc: maskFunc})
+			return
+		}
+		if m.pathFuncs == nil {
+			m.pathFuncs = make(map[string]func(field any) string)
+		}
+		m.pathFuncs[m.pathFuncKey(path)] = maskFunc
+	})
+}
+
+// patternFunc is a mask function registered for a path with wildcards.
+type patternFunc struct {
+	pattern  pathPattern
+	maskFunc func(field any) string
+}
+
+// WithReplacer replaces masked values with the result of replacer instead of a mask string,
+// so masked values can keep their type, e.g. masking numbers with 0.
+// The replacer receives the original value and the concrete path of the node, e.g. "$.items[3].card",
+// and takes precedence over the global mask function, but not over WithMaskFuncForPath.
+func WithReplacer(replacer func(value any, path string) any) option {
+	return func(m *masker) {
+		m.replacer = replacer
+	}
+}
+
+// WithMaskFuncForType registers a mask function for the masked values of the given kind,
+// e.g. reflect.Float64 to mask numbers as 0 instead of a string. Like in WithMaskAllOfKind,
+// numbers of JSON documents are of kind reflect.Float64, even though fn receives them as json.Number,
+// objects of kind reflect.Map and null of kind reflect.Invalid.
+// Only nodes that are masked use it, it takes precedence over WithReplacer and the global mask function
+// but not over WithMaskFuncForPath.
+func WithMaskFuncForType(kind reflect.Kind, fn func(value any) any) option {
+	return func(m *masker) {
+		if m.typeFuncs == nil {
+			m.typeFuncs = make(map[reflect.Kind]func(value any) any)
+		}
+		m.typeFuncs[kind] = fn
+	}
+}
+
+// WithStrictPaths makes masking return an error listing the mask paths that matched
+// nothing in the document, to surface misconfigured paths.
+// A path is considered matched when it masked a node, when a parent node it points into
+// was masked as a whole, or when it iterates an array that exists but is empty,
+// e.g. "$.items[].card" is matched by {"items":[]}.
+func WithStrictPaths() option {
+	return func(m *masker) {
+		m.isStrict = true
+	}
+}
+
+// WithMaxDepth sets the maximum nesting depth of the masked documents, the root being at depth 0.
+// Masking a document nested deeper returns an error indicating the path where the limit was hit.
+// Defaults to DefaultMaxDepth, this protects against stack exhaustion when masking untrusted input.
+func WithMaxDepth(maxDepth int) option {
+	return func(m *masker) {
+		m.maxDepth = maxDepth
+	}
+}
+
+// WithRegexPaths makes the mask paths regular expressions matched against the normalized path
+// of every node, e.g. `^\$\.users\.[^.]+\.ssn$`. Normalized paths have their array indexes
+// collapsed to [], so "$.users[2].ssn" is matched as "$.users[].ssn" and a regex matching
+// array elements has to match the literal [], e.g. `^\$\.users\[\]\.ssn$`.
+// Keys are formatted like in MaskWithReport, bracket-quoting the keys that can't use the dot notation.
+// The paths are compiled by NewMasker, and every mask call returns an error if one of them is invalid.
+func WithRegexPaths() option {
+	return func(m *masker) {
+		m.isRegex = true
+	}
+}
+
+// WithDropMaskedFields removes the masked nodes instead of replacing them with the masked value:
+// masked object members are deleted along with their key and masked array elements are removed,
+// shifting the elements that follow. Paths are matched against the indexes of the input document.
+// A masked document root becomes null, and masked struct fields, which can't be removed,
+// are set to their zero value.
+func WithDropMaskedFields() option {
+	return func(m *masker) {
+		m.isDrop = true
+	}
+}
+
+// WithKeepNulls leaves the null values matched by the mask paths as null instead of masking them,
+// so the masked document doesn't suggest a value was present. They are neither masked, dropped
+// with WithDropMaskedFields nor reported by MaskWithReport, but still count as matched for WithStrictPaths.
+// With WithDropMaskedFields, MaskReader buffers the whole document to keep them.
+func WithKeepNulls() option {
+	return func(m *masker) {
+		m.isKeepNulls = true
+	}
+}
+
+// WithMaskEmptyAsEmpty leaves the empty strings, arrays and objects matched by the mask paths
+// as they are instead of masking them, so the masked document doesn't suggest a value was present.
+// Like the nulls of WithKeepNulls, they are neither masked, dropped with WithDropMaskedFields
+// nor reported by MaskWithReport, but still count as matched for WithStrictPaths.
+func WithMaskEmptyAsEmpty() option {
+	return func(m *masker) {
+		m.isKeepEmpty = true
+	}
+}
+
+// WithSkipMasked leaves the strings and keys matched by the mask paths that start with marker as they are,
+// considering them already masked, so masking a masked document again doesn't change it
+// even with mask functions that aren't idempotent: WithHashMask hashes a hash once more,
+// but not a mask function prefixing the hash with marker, e.g. "h:".
+// As the values of WithMaskEmptyAsEmpty, they are neither masked again nor reported by MaskWithReport.
+// Masking with a fixed string, such as DefaultMaskString, is idempotent without it.
+func WithSkipMasked(marker string) option {
+	return func(m *masker) {
+		m.maskedMarker = marker
+	}
+}
+
+// WithLogger logs every node visited and masked to logger at debug level,
+// with the path of the node and the action taken as attributes.
+func WithLogger(logger *slog.Logger) option {
+	return func(m *masker) {
+		m.logger = logger
+	}
+}
+
+// WithDebugMode logs every node visited and masked to stderr, it is a shortcut for
+// WithLogger with a text handler enabled at debug level. WithLogger takes precedence over it.
 func WithDebugMode() option {
 	return func(m *masker) {
 		m.isDebugMode = true
@@ -40,121 +368,983 @@ func WithDebugMode() option {
 }
 
 func NewMasker(maskPaths []string, opts ...option) Masker {
-	m := &masker{}
+	m := &masker{
+		maskPaths:  maskPaths,
+		maxDepth:   DefaultMaxDepth,
+		arrayToken: DefaultArrayToken,
+		maskFunc: func(field any) string {
+			return DefaultMaskString
+		},
+	}
 	for _, opt := range opts {
 		opt(m)
 	}
+	if m.logger == nil && m.isDebugMode {
+		m.logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	m.compileOptionPaths()
+	if m.compiled != nil {
+		// compiled paths use the path syntax, and so do the paths of MaskWithPaths along with them
+		m.isRegex, m.isJSONPointer = false, false
+		m.maskPaths, m.paths = m.compiled.paths, m.compiled.set
+		if m.normalizer != nil {
+			m.paths = m.paths.withNormalizer(m.normalizer)
+		}
+	} else {
+		m.paths, m.err = m.compilePaths(maskPaths)
+	}
+	if err := validateArrayToken(m.arrayToken); err != nil && m.err == nil {
+		m.err = err
+	}
+	if m.keepPaths != nil && m.err == nil {
+		keep, err := m.compilePaths(m.keepPaths)
+		m.keep, m.err = &keep, err
+	}
+	if m.nestedPaths != nil && m.err == nil {
+		nested, err := m.compilePaths(m.nestedPaths)
+		m.nested, m.err = &nested, err
+	}
+	if m.base64Paths != nil && m.err == nil {
+		base64, err := m.compilePaths(m.base64Paths)
+		m.base64, m.err = &base64, err
+		if m.err == nil {
+			m.base64Inner, m.err = m.compilePaths(m.base64InnerPaths)
+		}
+	}
 	return m
 }
 
-// Mask masks the input JSON string based on the provided maskPaths.
-// maskPaths is a list of JSON paths that should be masked.
-// maskStr is the string that will replace the masked values.
+// optionPath is a path of an option, e.g. WithConditionalMask, compiled once the options are all applied,
+// so WithArrayToken and WithFieldNameNormalizer apply to it whatever the order of the options.
+type optionPath struct {
+	path string
+	// register registers the option with its compiled path, written with "[]" as the array token,
+	// and its pattern, the keys of which are normalized.
+	register func(m *masker, path string, pattern pathPattern)
+}
+
+// withOptionPath returns an option registering path with register once compiled, see compileOptionPaths.
+func withOptionPath(path string, register func(m *masker, path string, pattern pathPattern)) option {
+	return func(m *masker) {
+		m.optionPaths = append(m.optionPaths, optionPath{path: path, register: register})
+	}
+}
+
+// compileOptionPaths compiles the paths of the options in the order the options were applied,
+// with the array token replaced with "[]" and their keys normalized, and registers them.
+func (m *masker) compileOptionPaths() {
+	for _, p := range m.optionPaths {
+		path := replaceArrayToken(p.path, m.arrayToken)
+		pattern := compilePattern(path)
+		if m.normalizer != nil {
+			pattern = pattern.normalized(m.normalizer)
+		}
+		p.register(m, path, pattern)
+	}
+}
+
+// compilePaths compiles maskPaths into a pathSet, as JSON Pointers if WithJSONPointerPaths is used
+// or regular expressions if WithRegexPaths is used,
+// normalizing their keys if WithFieldNameNormalizer is used.
+func (m *masker) compilePaths(maskPaths []string) (pathSet, error) {
+	var set pathSet
+	if m.isJSONPointer {
+		var err error
+		if set, err = newPointerPathSet(maskPaths, m.arrayToken); err != nil {
+			return pathSet{}, err
+		}
+	} else if m.isRegex {
+		var err error
+		if set, err = newRegexPathSet(maskPaths, m.arrayToken); err != nil {
+			return pathSet{}, err
+		}
+	} else {
+		for _, path := range maskPaths {
+			if _, err := parsePattern(replaceArrayToken(path, m.arrayToken)); err != nil {
+				return pathSet{}, withKind(ErrInvalidPath, fmt.Errorf("invalid mask path %q: %w", path, err))
+			}
+		}
+		if m.arrayToken != DefaultArrayToken {
+			set = newArrayTokenPathSet(maskPaths, m.arrayToken)
+		} else {
+			set = newPathSet(maskPaths)
+		}
+	}
+	if m.normalizer != nil {
+		set = set.withNormalizer(m.normalizer)
+	}
+	return set, nil
+}
+
+// Mask masks the input JSON string based on the maskPaths passed to NewMasker.
+// Numbers are decoded as json.Number, so values that aren't masked keep their exact
+// representation and mask functions receive numbers as json.Number.
 // The function returns the masked JSON string.
-func (m *masker) Mask(input string, maskPaths []string) (string, error) {
-	maskPathsMap := make(map[string]bool)
-	for _, path := range maskPaths {
-		maskPathsMap[path] = true
+func (m *masker) Mask(input string) (string, error) {
+	return m.MaskWithPaths(input, nil)
+}
+
+// MaskWithPaths masks the input JSON string based on the provided maskPaths.
+// maskPaths is a list of JSON paths that should be masked, overriding the
+// paths passed to NewMasker for this call only. A nil maskPaths falls back to
+// the paths passed to NewMasker.
+// The function returns the masked JSON string.
+func (m *masker) MaskWithPaths(input string, maskPaths []string) (string, error) {
+	maskedBytes, err := m.maskBytes([]byte(input), maskPaths)
+	if err != nil {
+		return "", err
+	}
+	return string(maskedBytes), nil
+}
+
+// MaskBytes masks the input JSON bytes based on the maskPaths passed to NewMasker.
+// It behaves like Mask but avoids converting between strings and bytes.
+// The function returns the masked JSON bytes.
+func (m *masker) MaskBytes(input []byte) ([]byte, error) {
+	return m.maskBytes(input, nil)
+}
+
+// MaskWithReport masks the input JSON string like MaskWithPaths and additionally returns
+// the concrete paths that were masked, with their real array indexes (e.g. "$.items[3].card"),
+// in traversal order.
+func (m *masker) MaskWithReport(input string, maskPaths []string) (string, []string, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return "", nil, err
+	}
+	state.isReport = true
+	maskedBytes, err := m.mask([]byte(input), state)
+	if err != nil {
+		return "", nil, err
 	}
-	var inputValue interface{}
-	if err := json.Unmarshal([]byte(input), &inputValue); err != nil {
-		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	return string(maskedBytes), state.maskedPaths, nil
+}
+
+// MaskContext masks the input JSON string like MaskWithPaths, aborting when ctx is done.
+// The context is checked periodically while traversing the document, and the returned
+// error wraps ctx.Err() when masking was aborted.
+func (m *masker) MaskContext(ctx context.Context, input string, maskPaths []string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
-	maskedObject, err := m.maskWithPaths(reflect.ValueOf(inputValue), maskPathsMap, "$")
+	state, err := m.newMaskState(maskPaths)
 	if err != nil {
-		return "", fmt.Errorf("failed to mask object: %w", err)
+		return "", err
 	}
-	maskedBytes, err := json.Marshal(maskedObject)
+	state.ctx = ctx
+	maskedBytes, err := m.mask([]byte(input), state)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal masked object: %w", err)
+		return "", err
 	}
 	return string(maskedBytes), nil
 }
 
-// maskWithPaths recursively masks the input object based on the provided maskPaths.
-// maskPaths is a map of JSON paths that should be masked.
-// maskStr is the string that will replace the masked values.
+// droppedNode is returned by maskNode in place of the masked value when WithDropMaskedFields is used,
+// for parents to remove the node.
+type droppedNode struct{}
+
+// isDropped checks if the masked value of a node means it should be removed.
+func isDropped(value any) bool {
+	_, ok := value.(droppedNode)
+	return ok
+}
+
+// MaskValue masks a Go value based on the provided maskPaths without encoding it to JSON,
+// returning a masked copy of it. The value itself is never modified.
+// Struct fields are matched by their Go name, e.g. "$.Account.Card", map keys as encoding/json
+// formats them, e.g. "$.2" for the int key 2, and pointers are followed, the returned value
+// holding what they pointed to, and the fields, elements and map values of pointer types new pointers
+// to the masked values. Maps are masked like objects, including by key mask paths,
+// WithConditionalMask and WithTypeTagRule, maps whose keys can't hold a masked key being returned
+// as map[string]V.
+// Slices, arrays and maps whose elements can't hold a masked value, e.g. an []int,
+// are returned as []any or map[K]any, and masking a struct field whose type can't hold it
+// returns an error. json.RawMessage values are masked as the JSON they hold and stay encoded,
+// they are only decoded when a mask path points inside them.
+// Values that encode themselves or implement fmt.Stringer, e.g. time.Time or net.IP, are leaves
+// masked as a whole, mask paths never pointing inside them.
+// Cyclic values, e.g. a struct holding a pointer to itself, return an error rather than being followed forever.
+// Masking a document decoded once with MaskValue avoids decoding and encoding it on every call
+// like Mask does.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+func (m *masker) MaskValue(v any, maskPaths []string) (any, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return nil, err
+	}
+	state.visiting = make(map[visit]int)
+	masked, err := m.maskWithPaths(reflect.ValueOf(v), state, rootPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to mask object: %w", err)
+	}
+	if err := state.unmatchedErr(); err != nil {
+		return nil, err
+	}
+	if isDropped(masked) {
+		return nil, nil
+	}
+	return masked, nil
+}
+
+// maskState holds the state of a single mask call.
+type maskState struct {
+	// ctx aborts the traversal when done, nil if the call can't be canceled.
+	ctx context.Context
+	// visited is the number of nodes traversed so far.
+	visited int
+	// maskPaths is the set of JSON paths that should be masked.
+	maskPaths pathSet
+	// matchers are the custom matchers of the masker, see WithMatcher.
+	matchers []PathMatcher
+	// maskedPaths are the concrete paths that were masked, in traversal order,
+	// only collected when isReport is set by MaskWithReport.
+	isReport    bool
+	maskedPaths []string
+	// matched holds the mask paths that matched in strict mode, nil otherwise.
+	matched map[string]bool
+	// isDeep is set by WithDeepMaskSubtrees, the leaves below the matched nodes being masked.
+	isDeep bool
+	// isConcurrent is set for the states masking part of an array concurrently, see WithConcurrency.
+	isConcurrent bool
+	// isDryRun is set by MaskDryRun, the masked nodes being collected in hits instead of masked.
+	isDryRun bool
+	hits     []MaskHit
+	// vault holds the original values of the masked nodes by path for MaskWithVault, nil otherwise.
+	vault map[string]any
+	// tagged are the patterns of the type tag rules matching the objects above the node being masked,
+	// see WithTypeTagRule.
+	tagged []taggedPath
+	// visiting holds the pointers, maps and slices being traversed by MaskValue with the length of their path,
+	// to detect cycles, see enter. It is nil for decoded JSON documents.
+	visiting map[visit]int
+	// counts holds the number of nodes matched by every mask path for MaskWithStats, nil otherwise.
+	counts map[string]int
+	// subtree matches the roots of the subtrees masked by MaskSubtree, nil for the other calls.
+	subtree *subtreeRoot
+	// isAroundProtected is set while traversing a matched node holding protected keys,
+	// whose other leaves are masked instead, see WithProtectedKeys.
+	isAroundProtected bool
+}
+
+// newMaskState creates the state for a mask call using the provided maskPaths.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+func (m *masker) newMaskState(maskPaths []string) (*maskState, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	paths := m.paths
+	if maskPaths != nil {
+		var err error
+		if paths, err = m.compilePaths(maskPaths); err != nil {
+			return nil, err
+		}
+	}
+	state := &maskState{maskPaths: paths, matchers: m.matchers, isDeep: m.isDeepMask}
+	if m.isStrict {
+		state.matched = make(map[string]bool)
+	}
+	return state, nil
+}
+
+// recordMasked records that the node at path was masked.
+func (s *maskState) recordMasked(path nodePath) {
+	if s.isReport {
+		s.maskedPaths = append(s.maskedPaths, path.String())
+	}
+	if s.matched != nil {
+		s.recordMatched(path)
+	}
+	s.recordCount(path, false)
+}
+
+// recordMaskedKey records that the key of the node at path was masked.
+func (s *maskState) recordMaskedKey(path nodePath) {
+	if s.isReport {
+		s.maskedPaths = append(s.maskedPaths, path.String()+keySuffix)
+	}
+	if s.matched != nil {
+		s.recordMatched(path)
+	}
+	s.recordCount(path, true)
+}
+
+// recordKept records that the value at path was matched but kept, see WithKeepNulls and WithMaskEmptyAsEmpty.
+func (s *maskState) recordKept(path nodePath) {
+	if s.matched != nil {
+		s.recordMatched(path)
+	}
+	s.recordCount(path, false)
+}
+
+// recordKeptKey records that the key of the node at path was matched but kept, see WithSkipMasked.
+func (s *maskState) recordKeptKey(path nodePath) {
+	if s.matched != nil {
+		s.recordMatched(path)
+	}
+	s.recordCount(path, true)
+}
+
+// recordEmptyArray records that the node at path is an empty array,
+// so paths iterating its elements are considered matched.
+func (s *maskState) recordEmptyArray(path nodePath) {
+	if s.matched != nil {
+		s.recordMatched(path.element(0, 1))
+	}
+}
+
+// checkContext returns the context error if the call was canceled,
+// checking the context only once every contextCheckInterval nodes.
+func (s *maskState) checkContext() error {
+	if s.ctx == nil {
+		return nil
+	}
+	s.visited++
+	if s.visited%contextCheckInterval != 0 {
+		return nil
+	}
+	return s.ctx.Err()
+}
+
+func (s *maskState) recordMatched(path nodePath) {
+	for _, path := range s.maskPaths.covering(path) {
+		s.matched[path] = true
+	}
+	if len(s.maskPaths.exclusions) > 0 {
+		// the node may be masked because an ancestor matched, see maskState.matches
+		for _, path := range s.maskPaths.matchingAncestors(path) {
+			s.matched[path] = true
+		}
+	}
+}
+
+// unmatchedErr returns an error listing the mask paths that didn't match in strict mode.
+func (s *maskState) unmatchedErr() error {
+	if s.matched == nil {
+		return nil
+	}
+	var unmatched []string
+	for _, pattern := range s.maskPaths.all {
+		if !s.matched[pattern.path] {
+			unmatched = append(unmatched, pattern.path)
+		}
+	}
+	if len(unmatched) > 0 {
+		return fmt.Errorf("mask paths matched nothing: %s", strings.Join(unmatched, ", "))
+	}
+	return nil
+}
+
+// maskBytes unmarshals the input, masks it based on the provided maskPaths
+// and marshals the result. A nil maskPaths falls back to the paths passed to NewMasker.
+func (m *masker) maskBytes(input []byte, maskPaths []string) ([]byte, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return nil, err
+	}
+	return m.mask(input, state)
+}
+
+// mask unmarshals the input, masks it using the provided state and marshals the result.
+func (m *masker) mask(input []byte, state *maskState) ([]byte, error) {
+	if m.isRawPassthrough {
+		return m.maskRaw(input, state)
+	}
+	inputValue, err := m.decodeInput(input)
+	if err != nil {
+		return nil, withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	maskedObject, err := m.maskWithPaths(reflect.ValueOf(inputValue), state, rootPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to mask object: %w", err)
+	}
+	if isDropped(maskedObject) {
+		maskedObject = nil
+	}
+	if err := state.unmatchedErr(); err != nil {
+		return nil, err
+	}
+	maskedBytes, err := m.marshal(maskedObject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal masked object: %w", err)
+	}
+	if err := m.checkOutputSize(len(maskedBytes)); err != nil {
+		return nil, err
+	}
+	return maskedBytes, nil
+}
+
+// maskWithPaths recursively masks the input object based on the mask paths of the state.
+// The input is never modified, containers are copied into the returned object.
+// state holds the mask paths and collects the paths that were masked.
 // path is the current path of the object in the JSON.
 // The function returns the masked object.
 func (m *masker) maskWithPaths(
 	input reflect.Value,
-	maskPaths map[string]bool,
-	path string,
+	state *maskState,
+	path nodePath,
 ) (any, error) {
 
-	m.log(fmt.Sprintf("Processing path: %s", path))
-	// Dereference pointers
-	for input.Kind() == reflect.Ptr {
+	m.log("Processing path", logActionProcess, path)
+	if err := checkDepth(path, m.maxDepth); err != nil {
+		return nil, err
+	}
+	if err := state.checkContext(); err != nil {
+		return nil, err
+	}
+	// the pointers are kept to detect cycles, see enter
+	referenced := input
+	// Dereference pointers and interfaces, decoded JSON objects are handled as a whole
+	for (input.Kind() == reflect.Ptr && input.Type() != objectType) || (input.Kind() == reflect.Interface && !input.IsNil()) {
 		input = input.Elem()
 	}
+	if state.outsideSubtree(path, !isContainer(input)) {
+		m.log("Keeping value outside of the subtree", logActionKeep, path)
+		if !input.IsValid() {
+			return nil, nil
+		}
+		return input.Interface(), nil
+	}
+	if m.isProtected(path) {
+		m.log("Keeping protected value", logActionKeep, path)
+		if !referenced.IsValid() {
+			return nil, nil
+		}
+		return referenced.Interface(), nil
+	}
+
+	// check if the path should be masked, whatever the type of the node, including null
+	if state.matches(path) || m.failsSlicePredicate(input, path) {
+		protecting := m.holdsProtected(input)
+		if !m.masksSubtree(input) && !protecting {
+			return m.maskNode(input, state, path)
+		}
+		if state.matched != nil {
+			state.recordMatched(path)
+		}
+		if protecting && !state.isAroundProtected {
+			// the leaves below are masked instead, the protected ones aside
+			state.isAroundProtected = true
+			defer func() { state.isAroundProtected = false }()
+		}
+	}
+	if state.visiting != nil {
+		leave, err := state.enter(referenced, path)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+	}
 
-	// handle nil pointers
+	// null values and nil pointers have nothing below them, paths pointing inside them
+	// simply don't match and their parents store them as the zero value of their type, see valueOf
 	if !input.IsValid() {
-		return reflect.ValueOf(nil), nil
+		if m.masksLeaf(input, state, path) {
+			return m.maskNode(input, state, path)
+		}
+		return nil, nil
 	}
 
-	// check if the path should be masked
-	if isMaskedPath(path, maskPaths) {
-		m.log(fmt.Sprintf("Masking path: %s", path))
-		return m.maskFunc(input.Interface()), nil
+	if input.Type() == objectType {
+		return m.maskObject(input.Interface().(*object), state, path)
+	}
+	if input.Type() == rawMessageType {
+		return m.maskRawMessage(input.Interface().(json.RawMessage), state, path)
+	}
+	if isOpaque(input.Type()) {
+		if m.masksLeaf(input, state, path) {
+			return m.maskNode(input, state, path)
+		}
+		m.log("Keeping value", logActionKeep, path)
+		return input.Interface(), nil
+	}
+	if b := m.numericBucket(input, path); b != nil {
+		return m.bucketNumber(b, input, path), nil
 	}
 
 	switch input.Kind() {
 	case reflect.Struct:
+		masked := reflect.New(input.Type()).Elem()
+		masked.Set(input)
 		for i := 0; i < input.NumField(); i++ {
-			m.log(fmt.Sprintf("Processing field: %s", input.Type().Field(i).Name))
 			field := input.Type().Field(i)
-			fieldPath := path + "." + field.Name
-			if maskedValue, err := m.maskWithPaths(input.Field(i), maskPaths, fieldPath); err != nil {
+			if !field.IsExported() {
+				continue
+			}
+			maskedValue, err := m.maskWithPaths(input.Field(i), state, path.key(field.Name))
+			if err != nil {
 				return nil, err
-			} else {
-				input.Field(i).Set(reflect.ValueOf(maskedValue))
 			}
+			if isDropped(maskedValue) {
+				masked.Field(i).Set(reflect.Zero(field.Type))
+				continue
+			}
+			if !assignableTo(maskedValue, field.Type) {
+				return nil, fmt.Errorf("masked value of type %T can't be assigned to field %s of type %s at path %s",
+					maskedValue, field.Name, field.Type, path.key(field.Name))
+			}
+			masked.Field(i).Set(valueOf(maskedValue, field.Type))
 		}
+		return masked.Interface(), nil
 	case reflect.Slice, reflect.Array:
-		for i := 0; i < input.Len(); i++ {
-			m.log(fmt.Sprintf("Processing index: %d", i))
-			if maskedValue, err := m.maskWithPaths(input.Index(i), maskPaths, fmt.Sprintf("%s[%d]", path, i)); err != nil {
-				return nil, err
-			} else {
-				input.Index(i).Set(reflect.ValueOf(maskedValue))
+		if input.Kind() == reflect.Slice && input.IsNil() {
+			return input.Interface(), nil
+		}
+		if input.Len() == 0 {
+			state.recordEmptyArray(path)
+		}
+		elemType := input.Type().Elem()
+		elements, err := m.maskElements(input, state, path)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, 0, len(elements))
+		typed := true
+		for _, maskedValue := range elements {
+			if isDropped(maskedValue) {
+				continue
 			}
+			typed = typed && assignableTo(maskedValue, elemType)
+			values = append(values, maskedValue)
+		}
+		if !typed {
+			// the elements can't hold the masked values, e.g. masked elements of an []int
+			return values, nil
+		}
+		var masked reflect.Value
+		if input.Kind() == reflect.Slice {
+			masked = reflect.MakeSlice(input.Type(), len(values), len(values))
+		} else {
+			// dropped elements leave zero values at the end of arrays, which can't be shortened
+			masked = reflect.New(input.Type()).Elem()
+		}
+		for i, value := range values {
+			masked.Index(i).Set(valueOf(value, elemType))
 		}
+		return masked.Interface(), nil
 	case reflect.Map:
-		for _, key := range input.MapKeys() {
-			m.log(fmt.Sprintf("Processing key: %v", key.Interface()))
-			if maskedValue, err := m.maskWithPaths(input.MapIndex(key), maskPaths, fmt.Sprintf("%s.%v", path, key.Interface())); err != nil {
+		if input.IsNil() {
+			return input.Interface(), nil
+		}
+		return m.maskMap(input, state, path)
+	case reflect.Interface:
+		// only nil interfaces are left after dereferencing
+		if m.masksLeaf(reflect.Value{}, state, path) {
+			return m.maskNode(reflect.Value{}, state, path)
+		}
+		return nil, nil
+	case reflect.String:
+		if m.isNestedJSON(path) {
+			masked, err := m.maskNestedJSON(input.String(), state, path)
+			if err != nil {
 				return nil, err
-			} else {
-				input.SetMapIndex(key, reflect.ValueOf(maskedValue))
 			}
+			return reflect.ValueOf(masked).Convert(input.Type()).Interface(), nil
 		}
-	case reflect.Interface:
-		m.log(fmt.Sprintf("Processing interface: %v", input.Interface()))
-		if input.IsNil() {
-			return nil, nil
+		if m.isBase64JSON(path) {
+			masked, err := m.maskBase64JSON(input.String(), state, path)
+			if err != nil {
+				return nil, err
+			}
+			return reflect.ValueOf(masked).Convert(input.Type()).Interface(), nil
 		}
-		if maskedValue, err := m.maskWithPaths(input.Elem(), maskPaths, path); err != nil {
-			return nil, err
-		} else if input.CanSet() {
-			input.Set(reflect.ValueOf(maskedValue))
+		if field := m.csvField(path); field != nil {
+			masked, err := m.maskCSV(field, input.String(), path)
+			if err != nil {
+				return nil, err
+			}
+			return reflect.ValueOf(masked).Convert(input.Type()).Interface(), nil
+		}
+		if r := m.regexValueMask(path); r != nil {
+			return reflect.ValueOf(m.scrub(r, input.String(), path)).Convert(input.Type()).Interface(), nil
+		}
+		if m.masksLeaf(input, state, path) {
+			return m.maskNode(input, state, path)
 		}
+		m.log("Keeping value", logActionKeep, path)
 	default:
-		m.log(fmt.Sprintf("No action needed for: %v", input.Interface()))
-		// do nothing
+		if m.masksLeaf(input, state, path) {
+			return m.maskNode(input, state, path)
+		}
+		m.log("Keeping value", logActionKeep, path)
 	}
 	return input.Interface(), nil
 }
 
-func (m *masker) log(data string) {
-	if m.isDebugMode {
-		fmt.Println(data)
+// maskRawMessage masks an encoded JSON value found in a Go value, returning it encoded.
+// It is only decoded if a mask path may match inside it.
+func (m *masker) maskRawMessage(raw json.RawMessage, state *maskState, path nodePath) (any, error) {
+	if raw == nil || !m.mayMatchBelow(state, path) {
+		return raw, nil
+	}
+	value, err := decodeJSON(raw, m.maxDepth, m.duplicates)
+	if err != nil {
+		return nil, withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal raw message at path %s: %w", path, err))
+	}
+	masked, err := m.maskWithPaths(reflect.ValueOf(value), state, path)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(masked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal raw message at path %s: %w", path, err)
+	}
+	return json.RawMessage(encoded), nil
+}
+
+// mayMatchBelow checks if a node below path may be masked.
+// Custom matchers and conditional masks can't be inspected, so they may always match,
+// like leaves when they are masked whatever the paths or below matched nodes with WithDeepMaskSubtrees.
+// The strings and numbers transformed without being matched, see transformsBelow, count as masked.
+func (m *masker) mayMatchBelow(state *maskState, path nodePath) bool {
+	return len(state.matchers) > 0 || len(m.conditions) > 0 || len(m.slicePredicates) > 0 || len(m.typeTags) > 0 || len(state.tagged) > 0 || m.masksLeaves() || len(state.maskPaths.exclusions) > 0 ||
+		m.isDeepMask || len(state.maskPaths.covering(path)) > 0 || m.transformsBelow(path)
+}
+
+// mapKey returns the path segment of a map key, following the rules of encoding/json for
+// object keys so paths target the keys of the marshaled map: strings are used as is,
+// encoding.TextMarshaler keys are marshaled and integers are formatted in base 10.
+// Other keys, e.g. booleans, are formatted with fmt.Sprint.
+// Paths target non-string keys like any key, e.g. "$.2" for the int key 2 or "$.true".
+func mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10)
+	default:
+		return fmt.Sprint(key.Interface())
 	}
 }
 
-// isMaskedPath checks if the path is in the maskPaths map.
-// removeIndexRegex is used to remove array indexes from the path.
-func isMaskedPath(path string, maskPaths map[string]bool) bool {
-	_, ok := maskPaths[removeIndexRegex.ReplaceAllString(path, "[]")]
-	return ok
+// assignableTo checks if a masked value can be stored in a container of type t.
+// Pointers hold the values they pointed to, which are masked with the pointers followed, see valueOf.
+func assignableTo(value any, t reflect.Type) bool {
+	if value == nil {
+		return true
+	}
+	for valueType := reflect.TypeOf(value); !valueType.AssignableTo(t); t = t.Elem() {
+		if t.Kind() != reflect.Ptr {
+			return false
+		}
+	}
+	return true
+}
+
+// valueOf returns the reflect.Value of a masked value to be stored in a container of type t,
+// the zero value of t for nil. For pointer types, a value that can only be assigned to what
+// they point to is stored in a new pointer.
+func valueOf(value any, t reflect.Type) reflect.Value {
+	if value == nil {
+		return reflect.Zero(t)
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		return v
+	}
+	ptr := reflect.New(t.Elem())
+	ptr.Elem().Set(valueOf(value, t.Elem()))
+	return ptr
+}
+
+// isNull checks if the value is encoded as null: nil, a nil interface or a nil pointer.
+func isNull(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+	return false
+}
+
+// isEmpty checks if the value is an empty string, array, slice, map or decoded object.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Array, reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.Type() == objectType && len(value.Interface().(*object).keys) == 0
+}
+
+// keepsMatched checks if the value matched by a mask path is left unmasked,
+// see WithKeepNulls, WithMaskEmptyAsEmpty and WithSkipMasked.
+func (m *masker) keepsMatched(value reflect.Value) bool {
+	if isNull(value) {
+		return m.isKeepNulls
+	}
+	if value.Kind() == reflect.String && m.isMasked(value.String()) {
+		return true
+	}
+	return m.isKeepEmpty && isEmpty(value)
+}
+
+// isMasked checks if the string is already masked, see WithSkipMasked.
+func (m *masker) isMasked(str string) bool {
+	return m.maskedMarker != "" && strings.HasPrefix(str, m.maskedMarker)
+}
+
+// maskNode masks the node at path as a whole.
+// It returns a droppedNode if the node should be removed.
+func (m *masker) maskNode(input reflect.Value, state *maskState, path nodePath) (any, error) {
+	var value any
+	if input.IsValid() {
+		value = toPlain(input.Interface())
+	}
+	if m.keepsMatched(input) {
+		m.log("Keeping matched value", logActionKeep, path)
+		state.recordKept(path)
+		return value, nil
+	}
+	m.log("Masking path", logActionMask, path)
+	state.recordMasked(path)
+	if state.isDryRun {
+		state.recordHit(path.String(), value)
+		return value, nil
+	}
+	m.notifyMasked(path, value)
+	state.recordOriginal(path.String(), value)
+	if m.isDrop {
+		return droppedNode{}, nil
+	}
+	return m.maskedValue(value, path, isNull(input))
+}
+
+// maskObject masks the members of a decoded JSON object and then its keys,
+// returning a new object.
+func (m *masker) maskObject(obj *object, state *maskState, path nodePath) (any, error) {
+	var err error
+	values := reflect.ValueOf(obj.values)
+	// parent is the object handed to the predicates of conditional masks,
+	// converted before its members are masked
+	var parent map[string]any
+	if m.isConditionParent(path) {
+		parent = toPlain(obj).(map[string]any)
+	}
+	if tagged := m.taggedPaths(obj, path); tagged != nil {
+		// the patterns apply to the members of the object only, and are copied for the forked states
+		outer := state.tagged
+		state.tagged = append(outer[:len(outer):len(outer)], tagged...)
+		defer func() { state.tagged = outer }()
+	}
+	masked := &object{keys: make([]string, 0, len(obj.keys)), values: make([]any, 0, len(obj.keys)), index: make(map[string]int, len(obj.keys))}
+	for i, key := range obj.keys {
+		value := values.Index(i)
+		var maskedValue any
+		if parent != nil && !m.isProtectedKey(key) && m.matchesCondition(parent, path.key(key)) {
+			maskedValue, err = m.maskNode(value, state, path.key(key))
+		} else {
+			maskedValue, err = m.maskWithPaths(value, state, path.key(key))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isDropped(maskedValue) {
+			continue
+		}
+		masked.add(key, maskedValue)
+	}
+	return m.maskKeys(masked, state, path)
+}
+
+// maskMap masks a Go map like an object whose members are its entries, in the order of their keys
+// as formatted by mapKey, so the conditional masks, the type tag rules and the key mask paths apply to it.
+// Maps whose keys can't hold a masked key are returned as map[string]V, and those whose values
+// can't hold a masked value as map[K]any.
+func (m *masker) maskMap(input reflect.Value, state *maskState, path nodePath) (any, error) {
+	keys := input.MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = mapKey(key)
+	}
+	sort.Sort(mapEntries{names: names, keys: keys})
+	obj := newObject()
+	originals := make(map[string]reflect.Value, len(keys))
+	for i, key := range keys {
+		obj.add(names[i], input.MapIndex(key).Interface())
+		originals[names[i]] = key
+	}
+	result, err := m.maskObject(obj, state, path)
+	if err != nil {
+		return nil, err
+	}
+	maskedObj := result.(*object)
+
+	mapType, keyType, elemType := input.Type(), input.Type().Key(), input.Type().Elem()
+	stringKeys := keyType.Kind() == reflect.String || keyType == anyType
+	typedKeys, typedValues := true, true
+	for i, key := range maskedObj.keys {
+		if _, ok := originals[key]; !ok && !stringKeys {
+			typedKeys = false
+		}
+		typedValues = typedValues && assignableTo(maskedObj.values[i], elemType)
+	}
+	if !typedValues {
+		// the values can't hold the masked values, e.g. masked values of a map[string]int
+		elemType = anyType
+	}
+	if !typedKeys {
+		// the keys can't hold the masked keys, e.g. masked keys of a map[int]string
+		keyType = reflect.TypeOf("")
+	}
+	if !typedKeys || !typedValues {
+		mapType = reflect.MapOf(keyType, elemType)
+	}
+	masked := reflect.MakeMapWithSize(mapType, len(maskedObj.keys))
+	for i, name := range maskedObj.keys {
+		key, ok := originals[name]
+		if !ok || !typedKeys {
+			key = reflect.ValueOf(name).Convert(keyType)
+		}
+		masked.SetMapIndex(key, valueOf(maskedObj.values[i], elemType))
+	}
+	return masked.Interface(), nil
+}
+
+// mapEntries sorts the keys of a map by their names, see maskMap.
+type mapEntries struct {
+	names []string
+	keys  []reflect.Value
+}
+
+func (e mapEntries) Len() int           { return len(e.names) }
+func (e mapEntries) Less(i, j int) bool { return e.names[i] < e.names[j] }
+func (e mapEntries) Swap(i, j int) {
+	e.names[i], e.names[j] = e.names[j], e.names[i]
+	e.keys[i], e.keys[j] = e.keys[j], e.keys[i]
+}
+
+// maskedValue returns the value replacing the node at path, using the mask function registered
+// for the path, the one registered for the kind of the value, the replacer or the global mask function,
+// in that order. isNull tells if the node was null, see WithMaskNullsDistinctly.
+// A panic of the mask function is returned as an error.
+func (m *masker) maskedValue(value any, path nodePath, isNull bool) (masked any, err error) {
+	defer recoverMaskFunc(path, &err)
+	if m.isExtendedJSON {
+		if key, wrapped, ok := unwrapExtendedJSON(value); ok {
+			masked, err := m.maskedValue(wrapped, path, isNull)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{key: masked}, nil
+		}
+	}
+	if maskFunc := m.pathFunc(path); maskFunc != nil {
+		return maskFunc(value), nil
+	}
+	if typeFunc := m.typeFunc(value); typeFunc != nil {
+		return typeFunc(value), nil
+	}
+	if m.replacer != nil {
+		return m.replacer(value, path.String()), nil
+	}
+	if m.keyMaskFunc != nil {
+		return m.keyMaskFunc(value, path.lastKey()), nil
+	}
+	if m.errMaskFunc != nil {
+		masked, err := m.errMaskFunc(value)
+		if err != nil {
+			return nil, fmt.Errorf("mask function failed at path %s: %w", path, err)
+		}
+		return masked, nil
+	}
+	if m.nullMaskFunc != nil {
+		return m.nullMaskFunc(value, isNull), nil
+	}
+	if m.pathMaskFunc != nil {
+		return m.pathMaskFunc(value, path.String()), nil
+	}
+	return m.maskFunc(value), nil
+}
+
+// maskedKey returns the global mask function applied to the key of the node at path.
+// A panic of the mask function is returned as an error.
+func (m *masker) maskedKey(key string, path nodePath) (masked string, err error) {
+	defer recoverMaskFunc(path, &err)
+	if m.keyMaskFunc != nil {
+		return m.keyMaskFunc(key, key), nil
+	}
+	if m.errMaskFunc != nil {
+		masked, err := m.errMaskFunc(key)
+		if err != nil {
+			return "", fmt.Errorf("mask function failed at path %s: %w", path, err)
+		}
+		return masked, nil
+	}
+	if m.nullMaskFunc != nil {
+		return m.nullMaskFunc(key, false), nil
+	}
+	if m.pathMaskFunc != nil {
+		return m.pathMaskFunc(key, path.String()+keySuffix), nil
+	}
+	return m.maskFunc(key), nil
+}
+
+// recoverMaskFunc recovers from a panic of a mask function called for the node at path,
+// setting err to an error identifying the path. It must be deferred.
+func recoverMaskFunc(path nodePath, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("mask function panicked at path %s: %v", path, r)
+	}
+}
+
+// pathFunc returns the most specific mask function registered for path, nil if there is none.
+func (m *masker) pathFunc(path nodePath) func(field any) string {
+	if len(m.pathFuncs) == 0 && len(m.patternFuncs) == 0 {
+		return nil
+	}
+	path = normalizeKeys(path, m.normalizer)
+	if maskFunc, ok := m.pathFuncs[normalizePath(path)]; ok {
+		return maskFunc
+	}
+	var best func(field any) string
+	bestScore := -1
+	for _, f := range m.patternFuncs {
+		// >= so the last registered wins among equally specific paths
+		if score := f.pattern.specificity(); score >= bestScore && f.pattern.match(path) {
+			best, bestScore = f.maskFunc, score
+		}
+	}
+	return best
+}
+
+// typeFunc returns the mask function registered for the kind of value, nil if there is none.
+func (m *masker) typeFunc(value any) func(value any) any {
+	if len(m.typeFuncs) == 0 {
+		return nil
+	}
+	kind := reflect.Invalid
+	if value != nil {
+		kind = leafKind(reflect.ValueOf(value))
+	}
+	return m.typeFuncs[kind]
+}
+
+// checkDepth returns an error if the path is nested deeper than maxDepth.
+func checkDepth(path nodePath, maxDepth int) error {
+	if len(path)-1 > maxDepth {
+		return fmt.Errorf("maximum depth of %d exceeded at path %s", maxDepth, path)
+	}
+	return nil
+}
+
+// log logs the action taken for the node at path at debug level, if a logger is configured.
+func (m *masker) log(msg string, action string, path nodePath) {
+	if m.logger == nil || !m.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	m.logger.LogAttrs(context.Background(), slog.LevelDebug, msg,
+		slog.String("path", path.String()), slog.String("normalized_path", normalizePath(path)),
+		slog.String("action", action))
 }
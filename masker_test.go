@@ -1,56 +1,24 @@
 package masker
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestIsMaskedPath(t *testing.T) {
-
-	testTable := []struct {
-		name      string
-		path      string
-		maskPaths map[string]bool
-		expected  bool
-	}{
-		{
-			name: "mask by path",
-			path: "someField.subField",
-			maskPaths: map[string]bool{
-				"someField.subField": true,
-			},
-			expected: true,
-		},
-		{
-			name: "mask by path with index",
-			path: "someField[2].subField",
-			maskPaths: map[string]bool{
-				"someField[].subField": true,
-			},
-			expected: true,
-		},
-		{
-			name: "not matching",
-			path: "someField.subField",
-			maskPaths: map[string]bool{
-				"test": true,
-			},
-			expected: false,
-		},
-	}
-
-	for _, tt := range testTable {
-		t.Run(tt.name, func(t *testing.T) {
-			ok := isMaskedPath(tt.path, tt.maskPaths)
-			assert.Equal(t, tt.expected, ok)
-		})
-	}
-}
-
 func TestMask_genericFields(t *testing.T) {
 	testTime, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
 	objectToJson := func(obj interface{}) string {
@@ -136,11 +104,2480 @@ func TestMask_genericFields(t *testing.T) {
 	for _, tt := range testTable {
 		t.Run(tt.name, func(t *testing.T) {
 			masker := NewMasker(tt.maskPaths, WithFixedMaskString("[REDACTED]"), WithDebugMode())
-			output, err := masker.Mask(tt.input, tt.maskPaths)
+			output, err := masker.MaskWithPaths(tt.input, tt.maskPaths)
+			assert.Equal(t, tt.expected, output)
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestMask_defaultMaskFunc(t *testing.T) {
+	masker := NewMasker([]string{"$.name"})
+	output, err := masker.Mask(`{"name":"John","age":30}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"`+DefaultMaskString+`","age":30}`, output)
+}
+
+func TestMask_storedPaths(t *testing.T) {
+	input := `{"name":"John","email":"john@example.com","age":30}`
+
+	testTable := []struct {
+		name        string
+		storedPaths []string
+		callPaths   []string
+		useStored   bool
+		expected    string
+	}{
+		{
+			name:        "Mask uses stored paths",
+			storedPaths: []string{"$.name", "$.email"},
+			useStored:   true,
+			expected:    `{"name":"[REDACTED]","email":"[REDACTED]","age":30}`,
+		},
+		{
+			name:        "MaskWithPaths overrides stored paths",
+			storedPaths: []string{"$.name"},
+			callPaths:   []string{"$.email"},
+			expected:    `{"name":"John","email":"[REDACTED]","age":30}`,
+		},
+		{
+			name:        "MaskWithPaths with nil paths falls back to stored paths",
+			storedPaths: []string{"$.name"},
+			callPaths:   nil,
+			expected:    `{"name":"[REDACTED]","email":"john@example.com","age":30}`,
+		},
+		{
+			name:        "MaskWithPaths with empty paths masks nothing",
+			storedPaths: []string{"$.name"},
+			callPaths:   []string{},
+			expected:    `{"name":"John","email":"john@example.com","age":30}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.storedPaths, WithFixedMaskString("[REDACTED]"))
+			var output string
+			var err error
+			if tt.useStored {
+				output, err = masker.Mask(input)
+			} else {
+				output, err = masker.MaskWithPaths(input, tt.callPaths)
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestMask_wildcard(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with wildcard map keys",
+			input:     `{"users":{"u1":{"ssn":"1","name":"a"},"u2":{"ssn":"2","name":"b"}}}`,
+			maskPaths: []string{"$.users.*.ssn"},
+			expected:  `{"users":{"u1":{"ssn":"[REDACTED]","name":"a"},"u2":{"ssn":"[REDACTED]","name":"b"}}}`,
+		},
+		{
+			name:      "Test with wildcard array elements",
+			input:     `{"users":[{"ssn":"1"},{"ssn":"2"}]}`,
+			maskPaths: []string{"$.users.*.ssn"},
+			expected:  `{"users":[{"ssn":"[REDACTED]"},{"ssn":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with wildcard top level values",
+			input:     `{"a":1,"b":{"c":2},"d":[3]}`,
+			maskPaths: []string{"$.*"},
+			expected:  `{"a":"[REDACTED]","b":"[REDACTED]","d":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with wildcard and array syntax",
+			input:     `{"groups":{"g1":{"members":[{"email":"a"},{"email":"b"}]},"g2":{"members":[{"email":"c"}]}}}`,
+			maskPaths: []string{"$.groups.*.members[].email"},
+			expected:  `{"groups":{"g1":{"members":[{"email":"[REDACTED]"},{"email":"[REDACTED]"}]},"g2":{"members":[{"email":"[REDACTED]"}]}}}`,
+		},
+		{
+			name:      "Test with wildcard children of mixed types",
+			input:     `{"config":{"host":"db","port":5432,"tls":true,"proxy":null,"auth":{"user":"u","password":"p"},"hosts":["a","b"]},"name":"app"}`,
+			maskPaths: []string{"$.config.*"},
+			expected:  `{"config":{"host":"[REDACTED]","port":"[REDACTED]","tls":"[REDACTED]","proxy":"[REDACTED]","auth":"[REDACTED]","hosts":"[REDACTED]"},"name":"app"}`,
+		},
+		{
+			name:      "Test with wildcard elements of mixed types",
+			input:     `{"config":[1,{"a":2},[3]]}`,
+			maskPaths: []string{"$.config.*"},
+			expected:  `{"config":["[REDACTED]","[REDACTED]","[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with wildcard below a scalar",
+			input:     `{"config":"raw","other":{"a":1}}`,
+			maskPaths: []string{"$.config.*"},
+			expected:  `{"config":"raw","other":{"a":1}}`,
+		},
+		{
+			name:      "Test with wildcard of empty containers",
+			input:     `{"config":{},"list":[]}`,
+			maskPaths: []string{"$.config.*", "$.list.*"},
+			expected:  `{"config":{},"list":[]}`,
+		},
+		{
+			name:      "Test with wildcard matching one level only",
+			input:     `{"config":{"auth":{"password":"p"}}}`,
+			maskPaths: []string{"$.config.*.*.*"},
+			expected:  `{"config":{"auth":{"password":"p"}}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestMask_glob(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with prefix pattern",
+			input:     `{"secretKey":"a","secretToken":"b","secret":"c","mySecret":"d"}`,
+			maskPaths: []string{"$.secret*"},
+			expected:  `{"secretKey":"[REDACTED]","secretToken":"[REDACTED]","secret":"[REDACTED]","mySecret":"d"}`,
+		},
+		{
+			name:      "Test with suffix pattern",
+			input:     `{"user":{"accessToken":"a","refreshToken":"b","tokenType":"c"}}`,
+			maskPaths: []string{"$.user.*Token"},
+			expected:  `{"user":{"accessToken":"[REDACTED]","refreshToken":"[REDACTED]","tokenType":"c"}}`,
+		},
+		{
+			name:      "Test with single character pattern",
+			input:     `{"pin1":"1","pin2":"2","pin10":"3","pin":"4"}`,
+			maskPaths: []string{"$.pin?"},
+			expected:  `{"pin1":"[REDACTED]","pin2":"[REDACTED]","pin10":"3","pin":"4"}`,
+		},
+		{
+			name:      "Test with pattern inside arrays",
+			input:     `{"users":[{"apiKey":"a","name":"b"},{"appKey":"c"}]}`,
+			maskPaths: []string{"$.users[].a??Key"},
+			expected:  `{"users":[{"apiKey":"[REDACTED]","name":"b"},{"appKey":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with escaped star",
+			input:     `{"a*":"1","ab":"2"}`,
+			maskPaths: []string{`$.a\*`},
+			expected:  `{"a*":"[REDACTED]","ab":"2"}`,
+		},
+		{
+			name:      "Test with quoted star",
+			input:     `{"a*":"1","ab":"2"}`,
+			maskPaths: []string{"$['a*']"},
+			expected:  `{"a*":"[REDACTED]","ab":"2"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestMask_recursiveDescent(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with key at different depths",
+			input:     `{"password":"a","user":{"password":"b","profile":{"password":"c"}}}`,
+			maskPaths: []string{"$..password"},
+			expected:  `{"password":"[REDACTED]","user":{"password":"[REDACTED]","profile":{"password":"[REDACTED]"}}}`,
+		},
+		{
+			name:      "Test with key inside arrays",
+			input:     `{"users":[{"password":"a"},{"auth":{"password":"b"}}]}`,
+			maskPaths: []string{"$.**.password"},
+			expected:  `{"users":[{"password":"[REDACTED]"},{"auth":{"password":"[REDACTED]"}}]}`,
+		},
+		{
+			name:      "Test with array elements at any depth",
+			input:     `{"a":{"tokens":[1,2]},"b":[{"tokens":[3]}]}`,
+			maskPaths: []string{"$..tokens[]"},
+			expected:  `{"a":{"tokens":["[REDACTED]","[REDACTED]"]},"b":[{"tokens":["[REDACTED]"]}]}`,
+		},
+		{
+			name:      "Test with overlapping exact and recursive paths",
+			input:     `{"user":{"password":"b"}}`,
+			maskPaths: []string{"$.user.password", "$..password"},
+			expected:  `{"user":{"password":"[REDACTED]"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestMaskBytes(t *testing.T) {
+	testTable := []struct {
+		name        string
+		input       []byte
+		maskPaths   []string
+		expected    []byte
+		expectedErr error
+	}{
+		{
+			name:        "Test with invalid json",
+			input:       []byte("invalid"),
+			expectedErr: fmt.Errorf("failed to unmarshal input: invalid character 'i' looking for beginning of value"),
+		},
+		{
+			name:      "Test with mask on path",
+			input:     []byte(`{"name":"John","jobs":[{"name":"dev"}]}`),
+			maskPaths: []string{"$.jobs[].name"},
+			expected:  []byte(`{"name":"John","jobs":[{"name":"[REDACTED]"}]}`),
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.MaskBytes(tt.input)
 			assert.Equal(t, tt.expected, output)
 			if tt.expectedErr != nil {
 				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			stringOutput, err := masker.Mask(string(tt.input))
+			assert.NoError(t, err)
+			assert.Equal(t, string(tt.expected), stringOutput)
+		})
+	}
+}
+
+// largeDocument builds a JSON array document of roughly size bytes.
+func largeDocument(size int) []byte {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; sb.Len() < size; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"id":%d,"name":"user-%d","email":"user-%d@example.com","tags":["a","b"]}`, i, i, i)
+	}
+	sb.WriteString("]")
+	return []byte(sb.String())
+}
+
+func BenchmarkMask(b *testing.B) {
+	input := string(largeDocument(1 << 20))
+	masker := NewMasker([]string{"$[].email"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := masker.Mask(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMask_manyPaths(b *testing.B) {
+	// a wide object with 500 fields and a mask path for every other field
+	fields := make([]string, 0, 500)
+	maskPaths := make([]string, 0, 250)
+	for i := 0; i < 500; i++ {
+		fields = append(fields, fmt.Sprintf(`"field%d":{"value":%d,"items":[{"id":%d}]}`, i, i, i))
+		if i%2 == 0 {
+			maskPaths = append(maskPaths, fmt.Sprintf("$.field%d.items[].id", i))
+		}
+	}
+	input := "{" + strings.Join(fields, ",") + "}"
+	masker := NewMasker(maskPaths)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := masker.Mask(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMask_arrays(b *testing.B) {
+	// nested arrays of 5000 leaves in total, every path of which has array indexes to collapse
+	rows := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		cells := make([]string, 0, 50)
+		for j := 0; j < 50; j++ {
+			cells = append(cells, fmt.Sprintf(`{"v":%d}`, j))
+		}
+		rows = append(rows, "["+strings.Join(cells, ",")+"]")
+	}
+	input := `{"rows":[` + strings.Join(rows, ",") + `]}`
+	masker := NewMasker([]string{"$.rows[][].v"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := masker.Mask(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMaskValue(b *testing.B) {
+	var input any
+	if err := json.Unmarshal(largeDocument(1<<20), &input); err != nil {
+		b.Fatal(err)
+	}
+	masker := NewMasker([]string{"$[].email"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := masker.MaskValue(input, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMaskBytes(b *testing.B) {
+	input := largeDocument(1 << 20)
+	masker := NewMasker([]string{"$[].email"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := masker.MaskBytes(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMask_maskFuncForPath(t *testing.T) {
+	emailMask := func(field any) string {
+		email := field.(string)
+		return "***" + email[strings.Index(email, "@"):]
+	}
+	cardMask := func(field any) string {
+		card := field.(string)
+		return "****" + card[len(card)-4:]
+	}
+	masker := NewMasker(
+		[]string{"$.email", "$.cards[].number", "$.name"},
+		WithMaskFuncForPath("$.email", emailMask),
+		WithMaskFuncForPath("$.cards[].number", cardMask),
+	)
+
+	output, err := masker.Mask(`{"name":"John","email":"john@domain.com","cards":[{"number":"4111111111111234"},{"number":"5500000000005678"}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"[REDACTED]","email":"***@domain.com","cards":[{"number":"****1234"},{"number":"****5678"}]}`, output)
+}
+
+func TestMask_maskFuncForPathPrecedence(t *testing.T) {
+	fixed := func(mask string) func(field any) string {
+		return func(field any) string {
+			return mask
+		}
+	}
+	testTable := []struct {
+		name     string
+		opts     []option
+		expected string
+	}{
+		{
+			name: "Test with exact path over wildcard",
+			opts: []option{
+				WithMaskFuncForPath("$..ssn", fixed("any")),
+				WithMaskFuncForPath("$.user.ssn", fixed("exact")),
+			},
+			expected: `{"user":{"ssn":"exact"},"admin":{"ssn":"any"}}`,
+		},
+		{
+			name: "Test with exact path registered first",
+			opts: []option{
+				WithMaskFuncForPath("$.user.ssn", fixed("exact")),
+				WithMaskFuncForPath("$.*.ssn", fixed("any")),
+			},
+			expected: `{"user":{"ssn":"exact"},"admin":{"ssn":"any"}}`,
+		},
+		{
+			name: "Test with most specific wildcard",
+			opts: []option{
+				WithMaskFuncForPath("$.*.ssn", fixed("star")),
+				WithMaskFuncForPath("$..ssn", fixed("descendant")),
+				WithMaskFuncForPath("$.us*.ssn", fixed("glob")),
+			},
+			expected: `{"user":{"ssn":"glob"},"admin":{"ssn":"star"}}`,
+		},
+		{
+			name: "Test with equally specific paths",
+			opts: []option{
+				WithMaskFuncForPath("$.*.ssn", fixed("first")),
+				WithMaskFuncForPath("$.user.*", fixed("last")),
+			},
+			expected: `{"user":{"ssn":"last"},"admin":{"ssn":"first"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker([]string{"$..ssn"}, tt.opts...).Mask(`{"user":{"ssn":"1"},"admin":{"ssn":"2"}}`)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestMask_keyOrder(t *testing.T) {
+	input := `{"zeta":1,"alpha":{"y":true,"b":null,"x":[{"k2":"v","k1":"v"}]},"mid":"secret","beta":[3,2,1]}`
+	expected := `{"zeta":1,"alpha":{"y":true,"b":null,"x":[{"k2":"[REDACTED]","k1":"v"}]},"mid":"[REDACTED]","beta":[3,2,1]}`
+	masker := NewMasker([]string{"$.mid", "$.alpha.x[].k2"})
+
+	for i := 0; i < 100; i++ {
+		output, err := masker.Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, output)
+	}
+}
+
+func TestMaskWithReport(t *testing.T) {
+	testTable := []struct {
+		name          string
+		input         string
+		maskPaths     []string
+		expected      string
+		expectedPaths []string
+	}{
+		{
+			name:          "Test with array indexes",
+			input:         `{"items":[{"card":"1"},{"id":2},{"card":"3"}]}`,
+			maskPaths:     []string{"$.items[].card"},
+			expected:      `{"items":[{"card":"[REDACTED]"},{"id":2},{"card":"[REDACTED]"}]}`,
+			expectedPaths: []string{"$.items[0].card", "$.items[2].card"},
+		},
+		{
+			name:          "Test with nested maps in traversal order",
+			input:         `{"b":{"secret":1},"a":{"nested":{"secret":2}},"secret":3}`,
+			maskPaths:     []string{"$..secret"},
+			expected:      `{"b":{"secret":"[REDACTED]"},"a":{"nested":{"secret":"[REDACTED]"}},"secret":"[REDACTED]"}`,
+			expectedPaths: []string{"$.b.secret", "$.a.nested.secret", "$.secret"},
+		},
+		{
+			name:      "Test with paths never matching",
+			input:     `{"a":1}`,
+			maskPaths: []string{"$.b"},
+			expected:  `{"a":1}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(nil)
+			output, maskedPaths, err := masker.MaskWithReport(tt.input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.Equal(t, tt.expectedPaths, maskedPaths)
+		})
+	}
+}
+
+func TestMask_strictPaths(t *testing.T) {
+	testTable := []struct {
+		name        string
+		input       string
+		maskPaths   []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:      "Test with matching path",
+			input:     `{"Time":"2021-01-01T00:00:00Z"}`,
+			maskPaths: []string{"$.Time"},
+			expected:  `{"Time":"[REDACTED]"}`,
+		},
+		{
+			name:        "Test with typo path",
+			input:       `{"Time":"2021-01-01T00:00:00Z","name":"John"}`,
+			maskPaths:   []string{"$.TIme", "$.name", "$.age"},
+			expectedErr: "mask paths matched nothing: $.TIme, $.age",
+		},
+		{
+			name:      "Test with empty array",
+			input:     `{"items":[]}`,
+			maskPaths: []string{"$.items[].card", "$.items[]"},
+			expected:  `{"items":[]}`,
+		},
+		{
+			name:        "Test with missing array",
+			input:       `{"other":[]}`,
+			maskPaths:   []string{"$.items[].card"},
+			expectedErr: "mask paths matched nothing: $.items[].card",
+		},
+		{
+			name:      "Test with path below a masked node",
+			input:     `{"user":{"name":"John"}}`,
+			maskPaths: []string{"$.user", "$.user.name"},
+			expected:  `{"user":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with wildcard path",
+			input:     `{"users":{"u1":{"ssn":"1"}}}`,
+			maskPaths: []string{"$.users.*.ssn", "$..ssn"},
+			expected:  `{"users":{"u1":{"ssn":"[REDACTED]"}}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, WithStrictPaths())
+			output, err := masker.Mask(tt.input)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+		})
+	}
+}
+
+func TestMask_quotedKeys(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with key containing dots",
+			input:     `{"user.email":"a@b.c","user":{"email":"d@e.f"}}`,
+			maskPaths: []string{"$.['user.email']"},
+			expected:  `{"user.email":"[REDACTED]","user":{"email":"d@e.f"}}`,
+		},
+		{
+			name:      "Test with nested path not matching key containing dots",
+			input:     `{"user.email":"a@b.c","user":{"email":"d@e.f"}}`,
+			maskPaths: []string{"$.user.email"},
+			expected:  `{"user.email":"a@b.c","user":{"email":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with key containing brackets",
+			input:     `{"tags[0]":"a","tags":["b"]}`,
+			maskPaths: []string{"$['tags[0]']"},
+			expected:  `{"tags[0]":"[REDACTED]","tags":["b"]}`,
+		},
+		{
+			name:      "Test with key containing quotes",
+			input:     `{"it's":"a","say \"hi\"":"b"}`,
+			maskPaths: []string{`$['it\'s']`, `$["say \"hi\""]`},
+			expected:  `{"it's":"[REDACTED]","say \"hi\"":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with quoted keys and array syntax",
+			input:     `{"a.b":[{"c.d":1},{"c.d":2}]}`,
+			maskPaths: []string{"$['a.b'][]['c.d']"},
+			expected:  `{"a.b":[{"c.d":"[REDACTED]"},{"c.d":"[REDACTED]"}]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestMask_arrayIndexes(t *testing.T) {
+	input := `{"items":[{"token":"a"},{"token":"b"},{"token":"c"},{"token":"d"},{"token":"e"}]}`
+
+	testTable := []struct {
+		name      string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with explicit index",
+			maskPaths: []string{"$.items[3].token"},
+			expected:  `{"items":[{"token":"a"},{"token":"b"},{"token":"c"},{"token":"[REDACTED]"},{"token":"e"}]}`,
+		},
+		{
+			name:      "Test with range",
+			maskPaths: []string{"$.items[0:2].token"},
+			expected:  `{"items":[{"token":"[REDACTED]"},{"token":"[REDACTED]"},{"token":"c"},{"token":"d"},{"token":"e"}]}`,
+		},
+		{
+			name:      "Test with open ended ranges",
+			maskPaths: []string{"$.items[:1].token", "$.items[4:].token"},
+			expected:  `{"items":[{"token":"[REDACTED]"},{"token":"b"},{"token":"c"},{"token":"d"},{"token":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with index, range and array syntax",
+			maskPaths: []string{"$.items[1]", "$.items[2:4].token", "$.items[].missing"},
+			expected:  `{"items":[{"token":"a"},"[REDACTED]",{"token":"[REDACTED]"},{"token":"[REDACTED]"},{"token":"e"}]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.Mask(input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestMask_lastIndex(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with single element",
+			input:     `{"logins":[{"ip":"1.1.1.1"}]}`,
+			maskPaths: []string{"$.logins[-1].ip"},
+			expected:  `{"logins":[{"ip":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with several elements",
+			input:     `{"logins":[{"ip":"1.1.1.1"},{"ip":"2.2.2.2"},{"ip":"3.3.3.3"}]}`,
+			maskPaths: []string{"$.logins[-1].ip"},
+			expected:  `{"logins":[{"ip":"1.1.1.1"},{"ip":"2.2.2.2"},{"ip":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with last token",
+			input:     `{"logins":[{"ip":"1.1.1.1"},{"ip":"2.2.2.2"}]}`,
+			maskPaths: []string{"$.logins[last].ip"},
+			expected:  `{"logins":[{"ip":"1.1.1.1"},{"ip":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with empty array",
+			input:     `{"logins":[]}`,
+			maskPaths: []string{"$.logins[-1].ip"},
+			expected:  `{"logins":[]}`,
+		},
+		{
+			name:      "Test with index before the start of the array",
+			input:     `{"logins":["a","b"]}`,
+			maskPaths: []string{"$.logins[-3]"},
+			expected:  `{"logins":["a","b"]}`,
+		},
+		{
+			name:      "Test with second to last element",
+			input:     `{"logins":["a","b","c"]}`,
+			maskPaths: []string{"$.logins[-2]"},
+			expected:  `{"logins":["a","[REDACTED]","c"]}`,
+		},
+		{
+			name:      "Test with arrays of varying lengths",
+			input:     `{"users":[{"logins":["a"]},{"logins":["b","c","d"]},{"logins":["e","f"]}]}`,
+			maskPaths: []string{"$.users[].logins[-1]"},
+			expected:  `{"users":[{"logins":["[REDACTED]"]},{"logins":["b","c","[REDACTED]"]},{"logins":["e","[REDACTED]"]}]}`,
+		},
+		{
+			name:      "Test with recursive descent and exclusion",
+			input:     `{"a":{"logins":["x","y"]},"b":{"logins":["z"]}}`,
+			maskPaths: []string{"$..logins[]", "!$.a.logins[-1]"},
+			expected:  `{"a":{"logins":["[REDACTED]","y"]},"b":{"logins":["[REDACTED]"]}}`,
+		},
+		{
+			name:      "Test with nested arrays",
+			input:     `[[1,2],[3]]`,
+			maskPaths: []string{"$[-1][-1]"},
+			expected:  `[[1,2],["[REDACTED]"]]`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+
+			output, err = NewMasker(tt.maskPaths, WithRawPassthrough()).Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		masked, err := NewMasker([]string{"$.Logins[last]"}).MaskValue(map[string][]string{"Logins": {"a", "b"}}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{"Logins": {"a", "[REDACTED]"}}, masked)
+	})
+
+	t.Run("Test with strict paths", func(t *testing.T) {
+		_, err := NewMasker([]string{"$.logins[-1]"}, WithStrictPaths()).Mask(`{"logins":[]}`)
+		assert.NoError(t, err)
+	})
+}
+
+func TestMask_maxDepth(t *testing.T) {
+	deep := func(depth int) string {
+		return strings.Repeat("[", depth) + strings.Repeat("]", depth)
+	}
+
+	testTable := []struct {
+		name        string
+		input       string
+		opts        []option
+		expectedErr string
+	}{
+		{
+			name:  "Test with document at the default max depth",
+			input: deep(DefaultMaxDepth),
+		},
+		{
+			name:        "Test with document deeper than the default max depth",
+			input:       deep(5000),
+			expectedErr: "failed to unmarshal input: maximum depth of 1000 exceeded at path $" + strings.Repeat("[0]", DefaultMaxDepth+1),
+		},
+		{
+			name:  "Test with document at a custom max depth",
+			input: `{"a":{"b":[1]}}`,
+			opts:  []option{WithMaxDepth(3)},
+		},
+		{
+			name:        "Test with document deeper than a custom max depth",
+			input:       `{"a":{"b":[{"c":1}]}}`,
+			opts:        []option{WithMaxDepth(3)},
+			expectedErr: "failed to unmarshal input: maximum depth of 3 exceeded at path $.a.b[0].c",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker([]string{"$..c"}, tt.opts...)
+			_, err := masker.Mask(tt.input)
+			var out bytes.Buffer
+			streamErr := masker.MaskReader(strings.NewReader(tt.input), &out, nil)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Error(t, streamErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, streamErr)
+		})
+	}
+}
+
+func TestMask_deepNesting(t *testing.T) {
+	t.Run("Test with document nested to the limit of encoding/json", func(t *testing.T) {
+		// encoding/json refuses to decode documents nested deeper than 10000 levels
+		depth := 9999
+		nested := strings.Repeat(`{"a":`, depth-2) + `[1]` + strings.Repeat(`}`, depth-2)
+		input := `{"ssn":"123","deep":` + nested + `}`
+		masker := NewMasker([]string{"$.ssn"}, WithMaxDepth(depth+1))
+
+		masked, err := masker.Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"ssn":"[REDACTED]","deep":`+nested+`}`, masked)
+
+		var out bytes.Buffer
+		assert.NoError(t, masker.MaskReader(strings.NewReader(input), &out, nil))
+		assert.Equal(t, masked, out.String())
+	})
+
+	t.Run("Test with value nested far deeper than a decoded document can be", func(t *testing.T) {
+		depth := 50000
+		var nested any = []any{"leaf"}
+		for i := 0; i < depth; i++ {
+			nested = []any{nested}
+		}
+		masker := NewMasker([]string{"$[0]"}, WithMaxDepth(depth+10))
+
+		masked, err := masker.MaskValue([]any{"secret", nested}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"[REDACTED]", nested}, masked)
+	})
+}
+
+func TestMask_numberPrecision(t *testing.T) {
+	input := `{"id":9007199254740993,"amount":12.50,"big":123456789012345678901234567890,"masked":9007199254740993}`
+	var received any
+	masker := NewMasker([]string{"$.masked"}, WithMaskFunc(func(field any) string {
+		received = field
+		return "[REDACTED]"
+	}))
+
+	output, err := masker.Mask(input)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":9007199254740993,"amount":12.50,"big":123456789012345678901234567890,"masked":"[REDACTED]"}`, output)
+	assert.Equal(t, json.Number("9007199254740993"), received)
+
+	var out bytes.Buffer
+	assert.NoError(t, masker.MaskReader(strings.NewReader(input), &out, nil))
+	assert.Equal(t, output, out.String())
+}
+
+func TestMaskContext(t *testing.T) {
+	input := string(largeDocument(1 << 20))
+
+	t.Run("Test without cancellation", func(t *testing.T) {
+		masker := NewMasker(nil)
+		output, err := masker.MaskContext(context.Background(), `{"email":"user@example.com"}`, []string{"$.email"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"email":"[REDACTED]"}`, output)
+	})
+
+	t.Run("Test with canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := NewMasker(nil).MaskContext(ctx, input, []string{"$[].email"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Test with cancellation mid-traversal", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		calls := 0
+		masker := NewMasker(nil, WithMaskFunc(func(field any) string {
+			calls++
+			if calls == 10 {
+				cancel()
+			}
+			return DefaultMaskString
+		}))
+		_, err := masker.MaskContext(ctx, input, []string{"$[].email"})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, calls, 10+contextCheckInterval)
+	})
+
+	t.Run("Test with expired deadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		_, err := NewMasker(nil).MaskContext(ctx, input, []string{"$[].email"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestMask_regexPaths(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with dynamic keys",
+			input:     `{"users":{"u1":{"ssn":"1","name":"a"},"u2":{"ssn":"2","name":"b"}}}`,
+			maskPaths: []string{`^\$\.users\.[^.]+\.ssn$`},
+			expected:  `{"users":{"u1":{"ssn":"[REDACTED]","name":"a"},"u2":{"ssn":"[REDACTED]","name":"b"}}}`,
+		},
+		{
+			name:      "Test with multiple dynamic segments",
+			input:     `{"orgs":{"o1":{"teams":{"t1":{"token":"a","apiKey":"b"}}},"o2":{"teams":{"t2":{"token":"c","id":1}}}}}`,
+			maskPaths: []string{`^\$\.orgs\.\w+\.teams\.\w+\.(token|apiKey)$`},
+			expected:  `{"orgs":{"o1":{"teams":{"t1":{"token":"[REDACTED]","apiKey":"[REDACTED]"}}},"o2":{"teams":{"t2":{"token":"[REDACTED]","id":1}}}}}`,
+		},
+		{
+			name:      "Test with normalized array indexes",
+			input:     `{"users":[{"ssn":"1"},{"ssn":"2"}]}`,
+			maskPaths: []string{`^\$\.users\[\]\.ssn$`},
+			expected:  `{"users":[{"ssn":"[REDACTED]"},{"ssn":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with unanchored regex",
+			input:     `{"password":"a","user":{"passwordHash":"b","name":"c"}}`,
+			maskPaths: []string{`password`},
+			expected:  `{"password":"[REDACTED]","user":{"passwordHash":"[REDACTED]","name":"c"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, WithRegexPaths())
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			output, err = NewMasker(nil, WithRegexPaths()).MaskWithPaths(tt.input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+
+	t.Run("Test with invalid regex", func(t *testing.T) {
+		masker := NewMasker([]string{`^\$\.users\.(ssn$`}, WithRegexPaths())
+		_, err := masker.Mask(`{"users":{}}`)
+		assert.ErrorContains(t, err, `invalid mask path regex "^\\$\\.users\\.(ssn$"`)
+		_, err = masker.MaskWithPaths(`{"users":{}}`, []string{"ssn"})
+		assert.Error(t, err)
+
+		_, err = NewMasker(nil, WithRegexPaths()).MaskWithPaths(`{"users":{}}`, []string{"("})
+		assert.ErrorContains(t, err, `invalid mask path regex "("`)
+	})
+}
+
+func TestMask_dropMaskedFields(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with object member",
+			input:     `{"name":"John","ssn":"123","age":30}`,
+			maskPaths: []string{"$.ssn"},
+			expected:  `{"name":"John","age":30}`,
+		},
+		{
+			name:      "Test with first and last members",
+			input:     `{"ssn":"123","name":"John","card":"4111"}`,
+			maskPaths: []string{"$.ssn", "$.card"},
+			expected:  `{"name":"John"}`,
+		},
+		{
+			name:      "Test with nested members in arrays",
+			input:     `{"users":[{"name":"a","ssn":"1"},{"name":"b","ssn":"2"}]}`,
+			maskPaths: []string{"$.users[].ssn"},
+			expected:  `{"users":[{"name":"a"},{"name":"b"}]}`,
+		},
+		{
+			name:      "Test with every array element",
+			input:     `{"tokens":["a","b","c"],"id":1}`,
+			maskPaths: []string{"$.tokens[]"},
+			expected:  `{"tokens":[],"id":1}`,
+		},
+		{
+			name:      "Test with consecutive array elements",
+			input:     `[0,1,2,3,4,5]`,
+			maskPaths: []string{"$[1:3]", "$[4]"},
+			expected:  `[0,3,5]`,
+		},
+		{
+			name:      "Test with wildcard members",
+			input:     `{"secrets":{"a":1,"b":{"c":2}},"id":1}`,
+			maskPaths: []string{"$.secrets.*"},
+			expected:  `{"secrets":{},"id":1}`,
+		},
+		{
+			name:      "Test with root",
+			input:     `{"name":"John"}`,
+			maskPaths: []string{"$"},
+			expected:  `null`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, WithDropMaskedFields())
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test the key is absent", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.user.ssn"}, WithDropMaskedFields()).Mask(`{"user":{"ssn":"123"}}`)
+		assert.NoError(t, err)
+		var decoded map[string]map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(output), &decoded))
+		assert.NotContains(t, decoded["user"], "ssn")
+	})
+
+	t.Run("Test with report", func(t *testing.T) {
+		_, masked, err := NewMasker(nil, WithDropMaskedFields()).MaskWithReport(`{"a":[1,2],"b":3}`, []string{"$.a[]", "$.b"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.a[0]", "$.a[1]", "$.b"}, masked)
+	})
+}
+
+func TestMaskWithPaths_doesNotMutateInput(t *testing.T) {
+	type account struct {
+		Name string
+		Card string
+	}
+	newInput := func() map[string]any {
+		return map[string]any{
+			"user":     map[string]any{"name": "John", "ssn": "123"},
+			"tokens":   []any{"a", "b", nil},
+			"accounts": []account{{Name: "main", Card: "4111"}},
+			"owner":    &account{Name: "Jane", Card: "5500"},
+		}
+	}
+	maskPaths := []string{"$.user.ssn", "$.tokens[]", "$.accounts[].Card", "$.owner.Card"}
+
+	m := NewMasker(maskPaths).(*masker)
+	input := newInput()
+	state, err := m.newMaskState(nil)
+	assert.NoError(t, err)
+	masked, err := m.maskWithPaths(reflect.ValueOf(input), state, rootPath())
+	assert.NoError(t, err)
+
+	assert.Equal(t, newInput(), input)
+	assert.Equal(t, map[string]any{
+		"user":     map[string]any{"name": "John", "ssn": DefaultMaskString},
+		"tokens":   []any{DefaultMaskString, DefaultMaskString, DefaultMaskString},
+		"accounts": []account{{Name: "main", Card: DefaultMaskString}},
+		"owner":    account{Name: "Jane", Card: DefaultMaskString},
+	}, masked)
+}
+
+func TestMask_nullArrayElements(t *testing.T) {
+	output, err := NewMasker([]string{"$.b"}).Mask(`{"a":[null,1],"c":{"d":null},"b":null}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":[null,1],"c":{"d":null},"b":"[REDACTED]"}`, output)
+}
+
+func TestMask_optionalRoot(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		paths    []string
+		expected string
+	}{
+		{
+			name:     "Test with key",
+			input:    `{"user":{"email":"a","name":"b"}}`,
+			paths:    []string{"user.email", "$.user.email"},
+			expected: `{"user":{"email":"[REDACTED]","name":"b"}}`,
+		},
+		{
+			name:     "Test with wildcards",
+			input:    `{"users":[{"ssn":"1"}],"ssn":"2"}`,
+			paths:    []string{"..ssn", "$..ssn"},
+			expected: `{"users":[{"ssn":"[REDACTED]"}],"ssn":"[REDACTED]"}`,
+		},
+		{
+			name:     "Test with array root",
+			input:    `[{"a":1},{"a":2}]`,
+			paths:    []string{"[].a", "$[].a"},
+			expected: `[{"a":"[REDACTED]"},{"a":"[REDACTED]"}]`,
+		},
+		{
+			name:     "Test with array element",
+			input:    `[1,2]`,
+			paths:    []string{"[1]", "$[1]"},
+			expected: `[1,"[REDACTED]"]`,
+		},
+		{
+			name:     "Test with root",
+			input:    `{"a":1}`,
+			paths:    []string{"$"},
+			expected: `"[REDACTED]"`,
+		},
+		{
+			name:     "Test with empty path",
+			input:    `{"a":1}`,
+			paths:    []string{""},
+			expected: `{"a":1}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range tt.paths {
+				output, err := NewMasker([]string{path}).Mask(tt.input)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, output, path)
+			}
+		})
+	}
+
+	t.Run("Test with mask function for path", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.a", "b"}, WithMaskFuncForPath("a", func(field any) string {
+			return "a"
+		})).Mask(`{"a":1,"b":2}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"a","b":"[REDACTED]"}`, output)
+	})
+}
+
+func TestMask_multidimensionalArrays(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with fields of inner elements",
+			input:     `[[{"field":1,"x":2}],[{"field":3}]]`,
+			maskPaths: []string{"$[][].field"},
+			expected:  `[[{"field":"[REDACTED]","x":2}],[{"field":"[REDACTED]"}]]`,
+		},
+		{
+			name:      "Test with inner elements",
+			input:     `{"matrix":[[1,2],[3]],"other":[1]}`,
+			maskPaths: []string{"$.matrix[][]"},
+			expected:  `{"matrix":[["[REDACTED]","[REDACTED]"],["[REDACTED]"]],"other":[1]}`,
+		},
+		{
+			name:      "Test with inner arrays",
+			input:     `{"matrix":[[1,2],[3]]}`,
+			maskPaths: []string{"$.matrix[]"},
+			expected:  `{"matrix":["[REDACTED]","[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with concrete indexes",
+			input:     `{"matrix":[[1,2],[3,4]]}`,
+			maskPaths: []string{"$.matrix[1][0]"},
+			expected:  `{"matrix":[[1,2],["[REDACTED]",4]]}`,
+		},
+		{
+			name:      "Test with three dimensions",
+			input:     `[[[1],[2]],[[3]]]`,
+			maskPaths: []string{"$[][1][]"},
+			expected:  `[[[1],["[REDACTED]"]],[[3]]]`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths)
+			output, err := m.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with report", func(t *testing.T) {
+		_, masked, err := NewMasker(nil).MaskWithReport(`{"a":[[1,2],[3]]}`, []string{"$.a[][]"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.a[0][0]", "$.a[0][1]", "$.a[1][0]"}, masked)
+	})
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		masked, err := NewMasker(nil).MaskValue([][]int{{1, 2}, {3}}, []string{"$[][1]"})
+		assert.NoError(t, err)
+		assert.Equal(t, []any{[]any{1, DefaultMaskString}, []int{3}}, masked)
+	})
+}
+
+func TestMask_keepNulls(t *testing.T) {
+	input := `{"ssn":null,"card":"4111","tags":[null,"a"],"user":{"email":null}}`
+	maskPaths := []string{"$.ssn", "$.card", "$.tags[]", "$.user.email"}
+	testTable := []struct {
+		name           string
+		opts           []option
+		expected       string
+		expectedMasked []string
+	}{
+		{
+			name:           "Test with replaced nulls",
+			expected:       `{"ssn":"[REDACTED]","card":"[REDACTED]","tags":["[REDACTED]","[REDACTED]"],"user":{"email":"[REDACTED]"}}`,
+			expectedMasked: []string{"$.ssn", "$.card", "$.tags[0]", "$.tags[1]", "$.user.email"},
+		},
+		{
+			name:           "Test with kept nulls",
+			opts:           []option{WithKeepNulls()},
+			expected:       `{"ssn":null,"card":"[REDACTED]","tags":[null,"[REDACTED]"],"user":{"email":null}}`,
+			expectedMasked: []string{"$.card", "$.tags[1]"},
+		},
+		{
+			name:           "Test with kept nulls and dropped fields",
+			opts:           []option{WithKeepNulls(), WithDropMaskedFields()},
+			expected:       `{"ssn":null,"tags":[null],"user":{"email":null}}`,
+			expectedMasked: []string{"$.card", "$.tags[1]"},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(maskPaths, tt.opts...)
+			output, masked, err := m.MaskWithReport(input, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.Equal(t, tt.expectedMasked, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with strict paths", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.ssn"}, WithKeepNulls(), WithStrictPaths()).Mask(`{"ssn":null}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"ssn":null}`, output)
+	})
+
+	t.Run("Test with keep only", func(t *testing.T) {
+		output, err := NewMasker(nil, WithKeepNulls(), WithKeepOnly([]string{"$.id"})).Mask(`{"id":1,"a":null,"b":2}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"id":1,"a":null,"b":"[REDACTED]"}`, output)
+	})
+}
+
+func TestMask_maskEmptyAsEmpty(t *testing.T) {
+	input := `{"ssn":"","tags":[],"user":{},"card":"4111","items":[{"id":""}]}`
+	maskPaths := []string{"$.ssn", "$.tags", "$.user", "$.card", "$.items[].id"}
+	testTable := []struct {
+		name           string
+		opts           []option
+		expected       string
+		expectedMasked []string
+	}{
+		{
+			name:           "Test with replaced empty values",
+			expected:       `{"ssn":"[REDACTED]","tags":"[REDACTED]","user":"[REDACTED]","card":"[REDACTED]","items":[{"id":"[REDACTED]"}]}`,
+			expectedMasked: []string{"$.ssn", "$.tags", "$.user", "$.card", "$.items[0].id"},
+		},
+		{
+			name:           "Test with kept empty values",
+			opts:           []option{WithMaskEmptyAsEmpty()},
+			expected:       `{"ssn":"","tags":[],"user":{},"card":"[REDACTED]","items":[{"id":""}]}`,
+			expectedMasked: []string{"$.card"},
+		},
+		{
+			name:           "Test with kept empty values and dropped fields",
+			opts:           []option{WithMaskEmptyAsEmpty(), WithDropMaskedFields()},
+			expected:       `{"ssn":"","tags":[],"user":{},"items":[{"id":""}]}`,
+			expectedMasked: []string{"$.card"},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(maskPaths, tt.opts...)
+			output, masked, err := m.MaskWithReport(input, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.Equal(t, tt.expectedMasked, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with strict paths", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.ssn"}, WithMaskEmptyAsEmpty(), WithStrictPaths()).Mask(`{"ssn":""}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"ssn":""}`, output)
+	})
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		masked, err := NewMasker(nil, WithMaskEmptyAsEmpty()).MaskValue(map[string]any{"a": []int{}, "b": map[string]int{}, "c": "x"}, []string{"$.a", "$.b", "$.c"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": []int{}, "b": map[string]int{}, "c": "[REDACTED]"}, masked)
+	})
+}
+
+func TestMask_idempotent(t *testing.T) {
+	input := `{"ssn":"123","age":42,"user":{"email":"a@b.c","tags":["x"]},"empty":"","none":null,"ids":{"u1":1,"u2":2}}`
+	maskPaths := []string{"$.ssn", "$.age", "$.user", "$.empty", "$.none", "$.ids.*~"}
+	hashPrefixed := WithMaskFunc(func(field any) string {
+		sum := sha256.Sum256([]byte(fmt.Sprint(field)))
+		return "h:" + hex.EncodeToString(sum[:])
+	})
+	testTable := []struct {
+		name string
+		opts []option
+	}{
+		{name: "Test with default mask string"},
+		{name: "Test with fixed mask string", opts: []option{WithFixedMaskString("***")}},
+		{name: "Test with kept nulls and empty values", opts: []option{WithKeepNulls(), WithMaskEmptyAsEmpty()}},
+		{name: "Test with dropped fields", opts: []option{WithDropMaskedFields()}},
+		{name: "Test with deep masking", opts: []option{WithDeepMaskSubtrees()}},
+		{name: "Test with skipped hashes", opts: []option{hashPrefixed, WithSkipMasked("h:")}},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(maskPaths, tt.opts...)
+			once, err := m.Mask(input)
+			assert.NoError(t, err)
+			twice, err := m.Mask(once)
+			assert.NoError(t, err)
+			assert.Equal(t, once, twice)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(once), &out, nil))
+			assert.Equal(t, once, out.String())
+		})
+	}
+
+	t.Run("Test with hash mask", func(t *testing.T) {
+		m := NewMasker([]string{"$.ssn"}, WithHashMask("salt"))
+		once, err := m.Mask(`{"ssn":"123"}`)
+		assert.NoError(t, err)
+		twice, err := m.Mask(once)
+		assert.NoError(t, err)
+		assert.NotEqual(t, once, twice)
+	})
+
+	t.Run("Test with skipped masked values", func(t *testing.T) {
+		output, masked, err := NewMasker([]string{"$.a", "$.b"}, WithSkipMasked("[REDACTED")).MaskWithReport(`{"a":"[REDACTED:3 chars]","b":"x"}`, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"[REDACTED:3 chars]","b":"[REDACTED]"}`, output)
+		assert.Equal(t, []string{"$.b"}, masked)
+	})
+}
+
+func TestMask_exclusions(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with wildcard except a field",
+			input:     `{"users":{"u1":{"id":1,"name":"John","ssn":"123"},"u2":{"id":2,"name":"Jane"}},"count":2}`,
+			maskPaths: []string{"$.users.*", "!$.users.*.id"},
+			expected:  `{"users":{"u1":{"id":1,"name":"[REDACTED]","ssn":"[REDACTED]"},"u2":{"id":2,"name":"[REDACTED]"}},"count":2}`,
+		},
+		{
+			name:      "Test with excluded subtree",
+			input:     `{"a":{"b":{"c":1,"d":[2]},"e":3}}`,
+			maskPaths: []string{"$.a", "!$.a.b"},
+			expected:  `{"a":{"b":{"c":1,"d":[2]},"e":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with excluded match",
+			input:     `{"user":{"ssn":"1"},"admin":{"ssn":"2"}}`,
+			maskPaths: []string{"$..ssn", "!$.admin.ssn"},
+			expected:  `{"user":{"ssn":"[REDACTED]"},"admin":{"ssn":"2"}}`,
+		},
+		{
+			name:      "Test with excluded array element",
+			input:     `{"items":[{"x":1},{"x":2}]}`,
+			maskPaths: []string{"$.items", "!$.items[0]"},
+			expected:  `{"items":[{"x":1},"[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with recursive exclusion",
+			input:     `{"id":1,"user":{"id":2,"name":"John"},"tags":["a"]}`,
+			maskPaths: []string{"$", "!$..id"},
+			expected:  `{"id":1,"user":{"id":2,"name":"[REDACTED]"},"tags":["[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with recursive exclusion and matched leaves",
+			input:     `{"users":[{"id":1,"name":"John"}],"token":"t"}`,
+			maskPaths: []string{"$.users[].*", "$.token", "!$..id"},
+			expected:  `{"users":[{"id":1,"name":"[REDACTED]"}],"token":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with exclusion only",
+			input:     `{"a":1}`,
+			maskPaths: []string{"!$.a"},
+			expected:  `{"a":1}`,
+		},
+		{
+			name:      "Test with dropped fields",
+			input:     `{"users":[{"id":1,"name":"John"}]}`,
+			maskPaths: []string{"$.users[]", "!$.users[].id"},
+			opts:      []option{WithDropMaskedFields()},
+			expected:  `{"users":[{"id":1}]}`,
+		},
+		{
+			name:      "Test with regex exclusion",
+			input:     `{"a":{"id":1,"b":2}}`,
+			maskPaths: []string{`^\$\.a$`, `!^\$\.a\.id$`},
+			opts:      []option{WithRegexPaths()},
+			expected:  `{"a":{"id":1,"b":"[REDACTED]"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, tt.opts...)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with report and strict paths", func(t *testing.T) {
+		masker := NewMasker([]string{"$.users.*", "!$.users.*.id"}, WithStrictPaths())
+		output, masked, err := masker.MaskWithReport(`{"users":{"u1":{"id":1,"name":"John"}}}`, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"users":{"u1":{"id":1,"name":"[REDACTED]"}}}`, output)
+		assert.Equal(t, []string{"$.users.u1.name"}, masked)
+	})
+
+	t.Run("Test with exclusion of a key path", func(t *testing.T) {
+		_, err := CompilePaths([]string{"!$.a~"})
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+}
+
+func TestMask_missingIntermediateNodes(t *testing.T) {
+	maskPaths := []string{"$.user.profile.ssn", "$.items[].card.number"}
+	testTable := []struct {
+		name  string
+		input string
+	}{
+		{name: "Test with null intermediate object", input: `{"user":{"profile":null},"items":[{"card":null}]}`},
+		{name: "Test with missing intermediate object", input: `{"user":{},"items":[{}]}`},
+		{name: "Test with missing root members", input: `{"other":1}`},
+		{name: "Test with null root", input: `null`},
+		{name: "Test with scalar intermediate", input: `{"user":{"profile":"none"},"items":[1,"a",true]}`},
+		{name: "Test with array intermediate", input: `{"user":{"profile":[{"ssn":"123"}]},"items":[]}`},
+		{name: "Test with null array", input: `{"user":null,"items":null}`},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(maskPaths)
+			output, err := m.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.input, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.JSONEq(t, tt.input, out.String())
+		})
+	}
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		type profile struct {
+			SSN string
+		}
+		type user struct {
+			Profile *profile
+			Extra   map[string]any
+			Any     any
+		}
+		input := user{Extra: map[string]any{"profile": nil}}
+		masked, err := NewMasker(nil).MaskValue(input, []string{"$.Profile.SSN", "$.Extra.profile.SSN", "$.Any.SSN"})
+		assert.NoError(t, err)
+		assert.Equal(t, input, masked)
+
+		masked, err = NewMasker(nil).MaskValue(map[string]*profile{"a": nil}, []string{"$.a.SSN"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*profile{"a": nil}, masked)
+	})
+}
+
+func TestMaskValue(t *testing.T) {
+	type card struct {
+		Number string
+		Expiry string
+	}
+	type customer struct {
+		Name  string
+		Age   int
+		Card  card
+		Cards []card
+		Tags  map[string]string
+		Notes any
+	}
+
+	testTable := []struct {
+		name      string
+		input     any
+		maskPaths []string
+		expected  any
+	}{
+		{
+			name: "Test with struct",
+			input: customer{
+				Name:  "John",
+				Age:   30,
+				Card:  card{Number: "4111", Expiry: "12/30"},
+				Cards: []card{{Number: "5500", Expiry: "01/29"}},
+				Tags:  map[string]string{"ssn": "123", "team": "a"},
+				Notes: map[string]any{"secret": "x"},
+			},
+			maskPaths: []string{"$.Name", "$.Card.Number", "$.Cards[].Number", "$.Tags.ssn", "$.Notes.secret"},
+			expected: customer{
+				Name:  DefaultMaskString,
+				Age:   30,
+				Card:  card{Number: DefaultMaskString, Expiry: "12/30"},
+				Cards: []card{{Number: DefaultMaskString, Expiry: "01/29"}},
+				Tags:  map[string]string{"ssn": DefaultMaskString, "team": "a"},
+				Notes: map[string]any{"secret": DefaultMaskString},
+			},
+		},
+		{
+			name:      "Test with pointer to struct",
+			input:     &card{Number: "4111", Expiry: "12/30"},
+			maskPaths: []string{"$.Number"},
+			expected:  card{Number: DefaultMaskString, Expiry: "12/30"},
+		},
+		{
+			name:      "Test with map",
+			input:     map[string]any{"user": map[string]any{"email": "a@b.c"}, "list": []any{1, 2}},
+			maskPaths: []string{"$.user.email", "$.list[1]"},
+			expected:  map[string]any{"user": map[string]any{"email": DefaultMaskString}, "list": []any{1, DefaultMaskString}},
+		},
+		{
+			name:      "Test with slice",
+			input:     []string{"a", "b"},
+			maskPaths: []string{"$[0]"},
+			expected:  []string{DefaultMaskString, "b"},
+		},
+		{
+			name:      "Test with concrete int slice",
+			input:     []int{1, 2, 3},
+			maskPaths: []string{"$[1]"},
+			expected:  []any{1, DefaultMaskString, 3},
+		},
+		{
+			name:      "Test with concrete int slice without masked elements",
+			input:     []int{1, 2, 3},
+			maskPaths: []string{"$[5]"},
+			expected:  []int{1, 2, 3},
+		},
+		{
+			name:      "Test with concrete int array",
+			input:     [2]int{1, 2},
+			maskPaths: []string{"$[]"},
+			expected:  []any{DefaultMaskString, DefaultMaskString},
+		},
+		{
+			name:      "Test with concrete int map",
+			input:     map[string]int{"a": 1, "b": 2},
+			maskPaths: []string{"$.b"},
+			expected:  map[string]any{"a": 1, "b": DefaultMaskString},
+		},
+		{
+			name:      "Test with raw message",
+			input:     map[string]any{"payload": json.RawMessage(`{"card":"4111","type":"visa"}`), "id": 1},
+			maskPaths: []string{"$.payload.card"},
+			expected:  map[string]any{"payload": json.RawMessage(`{"card":"[REDACTED]","type":"visa"}`), "id": 1},
+		},
+		{
+			name:      "Test with untouched raw message",
+			input:     map[string]any{"payload": json.RawMessage(`{ "card" : "4111" }`)},
+			maskPaths: []string{"$.other"},
+			expected:  map[string]any{"payload": json.RawMessage(`{ "card" : "4111" }`)},
+		},
+		{
+			name:      "Test with masked raw message",
+			input:     map[string]any{"payload": json.RawMessage(`{"card":"4111"}`)},
+			maskPaths: []string{"$.payload"},
+			expected:  map[string]any{"payload": DefaultMaskString},
+		},
+		{
+			name:      "Test with nil",
+			input:     nil,
+			maskPaths: []string{"$.a"},
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker(nil).MaskValue(tt.input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+
+	t.Run("Test with field that can't hold the masked value", func(t *testing.T) {
+		_, err := NewMasker(nil).MaskValue(customer{Age: 30}, []string{"$.Age"})
+		assert.EqualError(t, err, "failed to mask object: masked value of type string can't be assigned to field Age of type int at path $.Age")
+
+		_, err = NewMasker(nil).MaskValue(struct{ Pins []int }{Pins: []int{1, 2}}, []string{"$.Pins[0]"})
+		assert.EqualError(t, err, "failed to mask object: masked value of type []interface {} can't be assigned to field Pins of type []int at path $.Pins")
+	})
+}
+
+// linkedNode is a node of an acyclic linked list, see TestMaskValue_pointers.
+type linkedNode struct {
+	Value string
+	Next  *linkedNode
+}
+
+func TestMaskValue_pointers(t *testing.T) {
+	type card struct {
+		Number string
+		Holder string
+	}
+	type account struct {
+		Name  *string
+		SSN   *string
+		Cards []*card
+		ByID  map[string]*card
+	}
+	name, ssn := "John", "123-45-6789"
+
+	testTable := []struct {
+		name      string
+		input     any
+		maskPaths []string
+		expected  any
+	}{
+		{
+			name:      "Test with acyclic pointer chain",
+			input:     &linkedNode{Value: "a", Next: &linkedNode{Value: "b", Next: &linkedNode{Value: "c"}}},
+			maskPaths: []string{"$.Next.Value"},
+			expected:  linkedNode{Value: "a", Next: &linkedNode{Value: "[REDACTED]", Next: &linkedNode{Value: "c"}}},
+		},
+		{
+			name:      "Test with pointer fields, elements and map values",
+			input:     account{Name: &name, SSN: &ssn, Cards: []*card{{Number: "4111", Holder: "John"}, nil}, ByID: map[string]*card{"a": {Number: "5500"}}},
+			maskPaths: []string{"$.SSN", "$.Cards[].Number", "$.ByID.*.Number"},
+			expected: account{
+				Name:  &name,
+				SSN:   &[]string{"[REDACTED]"}[0],
+				Cards: []*card{{Number: "[REDACTED]", Holder: "John"}, nil},
+				ByID:  map[string]*card{"a": {Number: "[REDACTED]"}},
+			},
+		},
+		{
+			name:     "Test with nothing masked",
+			input:    account{Name: &name, Cards: []*card{{Number: "4111"}}},
+			expected: account{Name: &name, Cards: []*card{{Number: "4111"}}},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker(nil).MaskValue(tt.input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+
+	t.Run("Test input is left untouched", func(t *testing.T) {
+		input := account{SSN: &ssn}
+		_, err := NewMasker([]string{"$.SSN"}).MaskValue(input, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "123-45-6789", *input.SSN)
+	})
+}
+
+func TestMaskValue_opaqueValues(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ip := net.ParseIP("10.0.0.1")
+
+	testTable := []struct {
+		name      string
+		input     any
+		maskPaths []string
+		opts      []option
+		expected  any
+	}{
+		{
+			name:      "Test with time masked directly",
+			input:     created,
+			maskPaths: []string{"$"},
+			expected:  DefaultMaskString,
+		},
+		{
+			name:      "Test with time field",
+			input:     struct{ Created any }{Created: created},
+			maskPaths: []string{"$.Created"},
+			expected:  struct{ Created any }{Created: DefaultMaskString},
+		},
+		{
+			name:      "Test with time passed to the mask function",
+			input:     map[string]any{"created": &created},
+			maskPaths: []string{"$.created"},
+			opts: []option{WithMaskFunc(func(field any) string {
+				return field.(time.Time).Format(time.DateOnly)
+			})},
+			expected: map[string]any{"created": "2024-05-01"},
+		},
+		{
+			name:      "Test with path inside a time",
+			input:     map[string]any{"created": created},
+			maskPaths: []string{"$.created.wall"},
+			expected:  map[string]any{"created": created},
+		},
+		{
+			name:      "Test with unmatched IP kept as is",
+			input:     map[string]any{"ip": ip},
+			maskPaths: []string{"$.other"},
+			expected:  map[string]any{"ip": ip},
+		},
+		{
+			name:     "Test with keep only",
+			input:    map[string]any{"id": 1, "created": created, "ip": ip},
+			opts:     []option{WithKeepOnly([]string{"$.id"})},
+			expected: map[string]any{"id": 1, "created": DefaultMaskString, "ip": DefaultMaskString},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker(nil, tt.opts...).MaskValue(tt.input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestMask_root(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with string root",
+			input:     `"4111111111111234"`,
+			maskPaths: []string{"$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with number root",
+			input:     `42`,
+			maskPaths: []string{"$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with boolean root",
+			input:     `true`,
+			maskPaths: []string{"$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with null root",
+			input:     `null`,
+			maskPaths: []string{"$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with object root",
+			input:     `{"name":"John","cards":["4111"]}`,
+			maskPaths: []string{"$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with array root",
+			input:     `[{"name":"John"},1]`,
+			maskPaths: []string{"$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with root and child paths",
+			input:     `{"name":"John"}`,
+			maskPaths: []string{"$.name", "$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with empty paths on scalar root",
+			input:     `"4111111111111234"`,
+			maskPaths: []string{},
+			expected:  `"4111111111111234"`,
+		},
+		{
+			name:      "Test with empty paths on null root",
+			input:     `null`,
+			maskPaths: []string{},
+			expected:  `null`,
+		},
+		{
+			name:      "Test with empty paths on object root",
+			input:     `{"name":"John"}`,
+			maskPaths: []string{},
+			expected:  `{"name":"John"}`,
+		},
+		{
+			name:      "Test with child path on scalar root",
+			input:     `"4111111111111234"`,
+			maskPaths: []string{"$.name", "$[]"},
+			expected:  `"4111111111111234"`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with nil value root", func(t *testing.T) {
+		output, err := NewMasker([]string{"$"}).MaskValue(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultMaskString, output)
+	})
+}
+
+func TestMask_concurrent(t *testing.T) {
+	masker := NewMasker([]string{"$.users[].email", "$..ssn", "$.secret*"}, WithMaskFuncForPath("$.users[].ssn", func(field any) string {
+		return "***"
+	}))
+	shared := map[string]any{"users": []any{map[string]any{"email": "shared@example.com", "ssn": "0"}}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := fmt.Sprintf(`{"users":[{"email":"user-%d@example.com","ssn":"%d"}],"secretKey":"%d","id":%d}`, i, i, i, i)
+			expected := fmt.Sprintf(`{"users":[{"email":"[REDACTED]","ssn":"***"}],"secretKey":"[REDACTED]","id":%d}`, i)
+
+			output, err := masker.Mask(input)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, output)
+
+			outputBytes, err := masker.MaskBytes([]byte(input))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(outputBytes))
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(input), &out, nil))
+			assert.Equal(t, expected, out.String())
+
+			masked, err := masker.MaskValue(shared, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]any{"users": []any{map[string]any{"email": DefaultMaskString, "ssn": "***"}}}, masked)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, map[string]any{"users": []any{map[string]any{"email": "shared@example.com", "ssn": "0"}}}, shared)
+}
+
+func TestMask_replacer(t *testing.T) {
+	replacer := func(value any, path string) any {
+		switch value.(type) {
+		case json.Number, int:
+			return 0
+		case bool:
+			return false
+		case string:
+			return "***"
+		default:
+			return nil
+		}
+	}
+
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with typed replacements",
+			input:     `{"age":30,"score":1.5,"active":true,"name":"John","id":1}`,
+			maskPaths: []string{"$.age", "$.score", "$.active", "$.name"},
+			expected:  `{"age":0,"score":0,"active":false,"name":"***","id":1}`,
+		},
+		{
+			name:      "Test with objects and arrays",
+			input:     `{"card":{"number":"4111"},"pins":[1,2]}`,
+			maskPaths: []string{"$.card", "$.pins[]"},
+			expected:  `{"card":null,"pins":[0,0]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, WithReplacer(replacer))
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with concrete paths", func(t *testing.T) {
+		var paths []string
+		masker := NewMasker([]string{"$.items[].card"}, WithReplacer(func(value any, path string) any {
+			paths = append(paths, path)
+			return value
+		}))
+		output, err := masker.Mask(`{"items":[{"card":"a"},{"card":"b"}]}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"items":[{"card":"a"},{"card":"b"}]}`, output)
+		assert.Equal(t, []string{"$.items[0].card", "$.items[1].card"}, paths)
+	})
+
+	t.Run("Test with mask function for path", func(t *testing.T) {
+		masker := NewMasker([]string{"$.a", "$.b"}, WithReplacer(replacer), WithMaskFuncForPath("$.b", func(field any) string {
+			return "b"
+		}))
+		output, err := masker.Mask(`{"a":1,"b":2}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":0,"b":"b"}`, output)
+	})
+
+	t.Run("Test with typed struct fields", func(t *testing.T) {
+		type account struct {
+			Age    int
+			Active bool
+		}
+		masked, err := NewMasker([]string{"$.Age", "$.Active"}, WithReplacer(replacer)).MaskValue(account{Age: 30, Active: true}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, account{}, masked)
+	})
+}
+
+// recordingHandler is a slog.Handler recording the records it handles.
+type recordingHandler struct {
+	level   slog.Level
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *recordingHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+func TestMask_maskFuncForType(t *testing.T) {
+	zero := func(value any) any {
+		return 0
+	}
+	stars := func(value any) any {
+		return "***"
+	}
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with numbers and strings",
+			input:     `{"age":30,"score":1.5,"name":"John","active":true}`,
+			maskPaths: []string{"$.age", "$.score", "$.name", "$.active"},
+			opts:      []option{WithMaskFuncForType(reflect.Float64, zero), WithMaskFuncForType(reflect.String, stars)},
+			expected:  `{"age":0,"score":0,"name":"***","active":"[REDACTED]"}`,
+		},
+		{
+			name:     "Test with all numbers",
+			input:    `{"a":1,"b":{"c":[2,"x"]}}`,
+			opts:     []option{WithMaskAllOfKind(reflect.Float64), WithMaskFuncForType(reflect.Float64, zero)},
+			expected: `{"a":0,"b":{"c":[0,"x"]}}`,
+		},
+		{
+			name:      "Test with objects and null",
+			input:     `{"a":{"b":1},"c":null}`,
+			maskPaths: []string{"$.a", "$.c"},
+			opts: []option{
+				WithMaskFuncForType(reflect.Map, func(value any) any { return map[string]any{} }),
+				WithMaskFuncForType(reflect.Invalid, func(value any) any { return "null" }),
+			},
+			expected: `{"a":{},"c":"null"}`,
+		},
+		{
+			name:      "Test with mask function for path",
+			input:     `{"age":30,"count":2}`,
+			maskPaths: []string{"$.age", "$.count"},
+			opts: []option{WithMaskFuncForType(reflect.Float64, zero), WithMaskFuncForPath("$.count", func(field any) string {
+				return "path"
+			})},
+			expected: `{"age":0,"count":"path"}`,
+		},
+		{
+			name:      "Test with replacer",
+			input:     `{"age":30,"name":"John"}`,
+			maskPaths: []string{"$.age", "$.name"},
+			opts: []option{WithMaskFuncForType(reflect.Float64, zero), WithReplacer(func(value any, path string) any {
+				return path
+			})},
+			expected: `{"age":0,"name":"$.name"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths, tt.opts...)
+			output, err := m.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		type account struct {
+			Balance float64
+			Name    string
+		}
+		masked, err := NewMasker(nil, WithMaskFuncForType(reflect.Float64, func(value any) any {
+			return 0.0
+		})).MaskValue(account{Balance: 12.5, Name: "John"}, []string{"$.Balance", "$.Name"})
+		assert.NoError(t, err)
+		assert.Equal(t, account{Balance: 0, Name: DefaultMaskString}, masked)
+	})
+}
+
+func TestMask_maskFuncContext(t *testing.T) {
+	maskFunc := WithMaskFuncContext(func(field any, path string) string {
+		switch {
+		case strings.HasSuffix(path, ".email"):
+			email := field.(string)
+			return "***" + email[strings.Index(email, "@"):]
+		case strings.HasSuffix(path, "~"):
+			return "key"
+		default:
+			return "[" + path + "]"
+		}
+	})
+	m := NewMasker([]string{"$.users[].email", "$.users[].ssn", "$.ids.*~"}, maskFunc)
+	input := `{"users":[{"email":"john@example.com","ssn":"1"},{"email":"jane@example.org","ssn":"2"}],"ids":{"a":1}}`
+	expected := `{"users":[{"email":"***@example.com","ssn":"[$.users[0].ssn]"},{"email":"***@example.org","ssn":"[$.users[1].ssn]"}],"ids":{"key":1}}`
+
+	output, err := m.Mask(input)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+
+	var out bytes.Buffer
+	assert.NoError(t, m.MaskReader(strings.NewReader(input), &out, nil))
+	assert.Equal(t, expected, out.String())
+
+	t.Run("Test with mask function set after", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.a"}, maskFunc, WithFixedMaskString("fixed")).Mask(`{"a":1}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"fixed"}`, output)
+	})
+}
+
+func TestMask_keyMaskFunc(t *testing.T) {
+	maskFunc := WithKeyMaskFunc(func(field any, key string) string {
+		return "[REDACTED:" + key + "]"
+	})
+	m := NewMasker([]string{"$.user.email", "$.cards[]", "$.matrix[][]", "$.ids.*~"}, maskFunc)
+	input := `{"user":{"email":"john@example.com","name":"John"},"cards":["4111"],"matrix":[[1]],"ids":{"a":1}}`
+	expected := `{"user":{"email":"[REDACTED:email]","name":"John"},"cards":["[REDACTED:cards]"],` +
+		`"matrix":[["[REDACTED:matrix]"]],"ids":{"[REDACTED:a]":1}}`
+
+	output, err := m.Mask(input)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+
+	var out bytes.Buffer
+	assert.NoError(t, m.MaskReader(strings.NewReader(input), &out, nil))
+	assert.Equal(t, expected, out.String())
+
+	t.Run("Test with root", func(t *testing.T) {
+		output, err := NewMasker([]string{"$", "$[]"}, maskFunc).Mask(`[1]`)
+		assert.NoError(t, err)
+		assert.Equal(t, `"[REDACTED:]"`, output)
+	})
+
+	t.Run("Test with mask functions replacing each other", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.a"}, WithMaskFuncContext(func(field any, path string) string { return path }), maskFunc).Mask(`{"a":1}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"[REDACTED:a]"}`, output)
+
+		output, err = NewMasker([]string{"$.a"}, maskFunc, WithFixedMaskString("fixed")).Mask(`{"a":1}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"fixed"}`, output)
+	})
+}
+
+func TestMask_maskFuncError(t *testing.T) {
+	errUnavailable := errors.New("tokenizer unavailable")
+	tokenize := WithMaskFuncError(func(field any) (string, error) {
+		if field == "fail" {
+			return "", errUnavailable
+		}
+		return "tok_" + fmt.Sprint(field), nil
+	})
+	testTable := []struct {
+		name        string
+		input       string
+		maskPaths   []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:      "Test with masked values",
+			input:     `{"ssn":"123","cards":["4111"]}`,
+			maskPaths: []string{"$.ssn", "$.cards[]"},
+			expected:  `{"ssn":"tok_123","cards":["tok_4111"]}`,
+		},
+		{
+			name:        "Test with failing value",
+			input:       `{"ssn":"123","users":[{"ssn":"fail"}]}`,
+			maskPaths:   []string{"$..ssn"},
+			expectedErr: "failed to mask object: mask function failed at path $.users[0].ssn: tokenizer unavailable",
+		},
+		{
+			name:        "Test with failing key",
+			input:       `{"ids":{"fail":1}}`,
+			maskPaths:   []string{"$.ids.*~"},
+			expectedErr: "failed to mask object: mask function failed at path $.ids.fail: tokenizer unavailable",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths, tokenize)
+			output, err := m.Mask(tt.input)
+			var out bytes.Buffer
+			streamErr := m.MaskReader(strings.NewReader(tt.input), &out, nil)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.ErrorIs(t, err, errUnavailable)
+				assert.EqualError(t, streamErr, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.NoError(t, streamErr)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		_, err := NewMasker([]string{"$.SSN"}, tokenize).MaskValue(struct{ SSN string }{SSN: "fail"}, nil)
+		assert.ErrorIs(t, err, errUnavailable)
+	})
+
+	t.Run("Test with mask function set after", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.a"}, tokenize, WithFixedMaskString("fixed")).Mask(`{"a":"fail"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"fixed"}`, output)
+	})
+}
+
+func TestMask_maskNullsDistinctly(t *testing.T) {
+	distinct := WithMaskNullsDistinctly(func(field any, isNull bool) string {
+		if isNull {
+			return "[NULL-REDACTED]"
+		}
+		return "[REDACTED]"
+	})
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:      "Test with null and non null values",
+			input:     `{"ssn":null,"email":"john@example.com","phones":[null,"555"]}`,
+			maskPaths: []string{"$.ssn", "$.email", "$.phones[]"},
+			expected:  `{"ssn":"[NULL-REDACTED]","email":"[REDACTED]","phones":["[NULL-REDACTED]","[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with null string and empty string",
+			input:     `{"a":"null","b":"","c":0}`,
+			maskPaths: []string{"$.a", "$.b", "$.c"},
+			expected:  `{"a":"[REDACTED]","b":"[REDACTED]","c":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with masked key",
+			input:     `{"ids":{"acc1":null}}`,
+			maskPaths: []string{"$.ids.*~", "$.ids.*"},
+			expected:  `{"ids":{"[REDACTED]":"[NULL-REDACTED]"}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths, distinct)
+			output, err := m.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		user := map[string]any{"Email": (*string)(nil), "Card": 4111}
+		masked, err := NewMasker([]string{"$.Email", "$.Card"}, distinct).MaskValue(user, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"Email": "[NULL-REDACTED]", "Card": "[REDACTED]"}, masked)
+	})
+
+	t.Run("Test with kept nulls", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.a", "$.b"}, distinct, WithKeepNulls()).Mask(`{"a":null,"b":1}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":null,"b":"[REDACTED]"}`, output)
+	})
+
+	t.Run("Test with mask function set after", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.a"}, distinct, WithFixedMaskString("fixed")).Mask(`{"a":null}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"fixed"}`, output)
+	})
+}
+
+func TestMask_maskFuncPanic(t *testing.T) {
+	upper := WithMaskFunc(func(field any) string {
+		return strings.ToUpper(field.(string))
+	})
+	testTable := []struct {
+		name        string
+		input       string
+		maskPaths   []string
+		expectedErr string
+	}{
+		{
+			name:        "Test with value",
+			input:       `{"name":"john","age":30}`,
+			maskPaths:   []string{"$.name", "$.age"},
+			expectedErr: "failed to mask object: mask function panicked at path $.age: interface conversion: interface {} is json.Number, not string",
+		},
+		{
+			name:        "Test with nested value",
+			input:       `{"users":[{"ids":[1]}]}`,
+			maskPaths:   []string{"$.users[].ids"},
+			expectedErr: "failed to mask object: mask function panicked at path $.users[0].ids: interface conversion: interface {} is []interface {}, not string",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths, upper)
+			_, err := m.Mask(tt.input)
+			assert.EqualError(t, err, tt.expectedErr)
+
+			err = m.MaskReader(strings.NewReader(tt.input), &bytes.Buffer{}, nil)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+
+	t.Run("Test with keys", func(t *testing.T) {
+		m := NewMasker([]string{"$.*~"}, WithMaskFunc(func(field any) string {
+			panic("no keys")
+		}))
+		_, err := m.Mask(`{"a":1}`)
+		assert.EqualError(t, err, "failed to mask object: mask function panicked at path $.a: no keys")
+
+		err = m.MaskReader(strings.NewReader(`{"a":1}`), &bytes.Buffer{}, nil)
+		assert.EqualError(t, err, "failed to mask object: mask function panicked at path $.a: no keys")
+	})
+
+	t.Run("Test without panic", func(t *testing.T) {
+		output, err := NewMasker([]string{"$.name"}, upper).Mask(`{"name":"john","age":30}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"JOHN","age":30}`, output)
+	})
+}
+
+func TestMask_logger(t *testing.T) {
+	attrs := func(record slog.Record) map[string]string {
+		attrs := make(map[string]string)
+		record.Attrs(func(attr slog.Attr) bool {
+			attrs[attr.Key] = attr.Value.String()
+			return true
+		})
+		return attrs
+	}
+
+	t.Run("Test with debug level", func(t *testing.T) {
+		handler := &recordingHandler{level: slog.LevelDebug}
+		masker := NewMasker([]string{"$.a"}, WithLogger(slog.New(handler)))
+		_, err := masker.Mask(`{"a":1,"b":2}`)
+		assert.NoError(t, err)
+
+		var logged []map[string]string
+		for _, record := range handler.records {
+			assert.Equal(t, slog.LevelDebug, record.Level)
+			logged = append(logged, attrs(record))
+		}
+		assert.Equal(t, []map[string]string{
+			{"path": "$", "normalized_path": "$", "action": "process"},
+			{"path": "$.a", "normalized_path": "$.a", "action": "process"},
+			{"path": "$.a", "normalized_path": "$.a", "action": "mask"},
+			{"path": "$.b", "normalized_path": "$.b", "action": "process"},
+			{"path": "$.b", "normalized_path": "$.b", "action": "keep"},
+		}, logged)
+	})
+
+	t.Run("Test with info level", func(t *testing.T) {
+		handler := &recordingHandler{level: slog.LevelInfo}
+		_, err := NewMasker([]string{"$.a"}, WithLogger(slog.New(handler))).Mask(`{"a":1}`)
+		assert.NoError(t, err)
+		assert.Empty(t, handler.records)
+	})
+
+	t.Run("Test without logger", func(t *testing.T) {
+		assert.Nil(t, NewMasker(nil).(*masker).logger)
+		assert.NotNil(t, NewMasker(nil, WithDebugMode()).(*masker).logger)
+	})
+
+	t.Run("Test with logger and debug mode", func(t *testing.T) {
+		handler := &recordingHandler{level: slog.LevelDebug}
+		masker := NewMasker([]string{"$.a"}, WithLogger(slog.New(handler)), WithDebugMode())
+		var out bytes.Buffer
+		assert.NoError(t, masker.MaskReader(strings.NewReader(`{"a":1}`), &out, nil))
+		assert.Len(t, handler.records, 3)
+	})
+}
+
+// colorKey is a map key marshaled as the name of the color.
+type colorKey int
+
+func (k colorKey) MarshalText() ([]byte, error) {
+	return []byte([]string{"red", "green"}[k]), nil
+}
+
+func TestMaskValue_mapKeys(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     any
+		maskPaths []string
+		expected  any
+	}{
+		{
+			name:      "Test with int keys",
+			input:     map[int]string{1: "a", 2: "b", -3: "c"},
+			maskPaths: []string{"$.2", "$['-3']"},
+			expected:  map[int]string{1: "a", 2: DefaultMaskString, -3: DefaultMaskString},
+		},
+		{
+			name:      "Test with uint keys",
+			input:     map[uint8]string{7: "a", 8: "b"},
+			maskPaths: []string{"$.7"},
+			expected:  map[uint8]string{7: DefaultMaskString, 8: "b"},
+		},
+		{
+			name:      "Test with bool keys",
+			input:     map[bool]string{true: "a", false: "b"},
+			maskPaths: []string{"$.true"},
+			expected:  map[bool]string{true: DefaultMaskString, false: "b"},
+		},
+		{
+			name:      "Test with text marshaler keys",
+			input:     map[colorKey]string{0: "a", 1: "b"},
+			maskPaths: []string{"$.green", "$.1"},
+			expected:  map[colorKey]string{0: "a", 1: DefaultMaskString},
+		},
+		{
+			name:      "Test with wildcard under int keys",
+			input:     map[int]map[string]string{1: {"ssn": "1"}, 2: {"ssn": "2"}},
+			maskPaths: []string{"$.*.ssn"},
+			expected:  map[int]map[string]string{1: {"ssn": DefaultMaskString}, 2: {"ssn": DefaultMaskString}},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker(nil).MaskValue(tt.input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+
+	t.Run("Test with report", func(t *testing.T) {
+		_, masked, err := NewMasker(nil).MaskWithReport(`{"1":"a"}`, []string{"$.1"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.1"}, masked)
+	})
+}
+
+func TestMaskValue_mapRules(t *testing.T) {
+	isCard := func(node map[string]any) bool {
+		return node["type"] == "card"
+	}
+
+	testTable := []struct {
+		name      string
+		input     any
+		maskPaths []string
+		opts      []option
+		expected  any
+	}{
+		{
+			name:      "Test with key paths",
+			input:     map[string]map[string]string{"accounts": {"alice": "1", "bob": "2"}},
+			maskPaths: []string{"$.accounts.alice~"},
+			expected:  map[string]map[string]string{"accounts": {DefaultMaskString: "1", "bob": "2"}},
+		},
+		{
+			name:      "Test with key paths under int keys",
+			input:     map[int]string{1: "a", 2: "b"},
+			maskPaths: []string{"$.1~"},
+			expected:  map[string]string{DefaultMaskString: "a", "2": "b"},
+		},
+		{
+			name: "Test with conditional masks",
+			input: []any{
+				map[string]any{"type": "card", "number": "4111"},
+				map[string]any{"type": "bank", "number": "0012"},
+			},
+			opts:     []option{WithConditionalMask("$[].number", isCard)},
+			expected: []any{map[string]any{"type": "card", "number": DefaultMaskString}, map[string]any{"type": "bank", "number": "0012"}},
+		},
+		{
+			name: "Test with type tag rules",
+			input: map[string][]map[string]string{"items": {
+				{"type": "card", "data": "4111"},
+				{"type": "bank", "data": "0012"},
+			}},
+			opts: []option{WithTypeTagRule("type", "card", "data")},
+			expected: map[string][]map[string]string{"items": {
+				{"type": "card", "data": DefaultMaskString},
+				{"type": "bank", "data": "0012"},
+			}},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker(tt.maskPaths, tt.opts...).MaskValue(tt.input, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
 		})
 	}
 }
@@ -0,0 +1,204 @@
+package masker
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithPartialMask masks values keeping only their last keep characters,
+// replacing the rest with maskChar, e.g. "4111111111111234" becomes "************1234".
+// Non-string values are converted with fmt.Sprint first.
+// Values that are not longer than keep are masked entirely to avoid leaking them,
+// and a negative keep is the same as 0.
+func WithPartialMask(keep int, maskChar rune) option {
+	keep = max(keep, 0)
+	return WithMaskFunc(func(field any) string {
+		runes := []rune(stringify(field))
+		if len(runes) <= keep {
+			return strings.Repeat(string(maskChar), len(runes))
+		}
+		masked := len(runes) - keep
+		return strings.Repeat(string(maskChar), masked) + string(runes[masked:])
+	})
+}
+
+// WithPartialMaskPrefix masks values keeping only their first show characters,
+// replacing the rest with maskChar, e.g. "abc-123-456" becomes "abc********" with show 3.
+// Non-string values are converted with fmt.Sprint first.
+// Values that are not longer than show are masked entirely to avoid leaking them,
+// and a negative show is the same as 0.
+func WithPartialMaskPrefix(show int, maskChar rune) option {
+	show = max(show, 0)
+	return WithMaskFunc(func(field any) string {
+		runes := []rune(stringify(field))
+		if len(runes) <= show {
+			return strings.Repeat(string(maskChar), len(runes))
+		}
+		return string(runes[:show]) + strings.Repeat(string(maskChar), len(runes)-show)
+	})
+}
+
+// DefaultTruncateTemplate is the template of WithTruncateMask producing e.g. "[REDACTED:142 chars]".
+const DefaultTruncateTemplate = "[REDACTED:%d chars]"
+
+// WithLengthPreservingMask masks values with maskChar repeated once per character of the value,
+// e.g. a 10 characters password becomes 10 maskChar. Strings are counted in runes, other values
+// by the length of their JSON encoding, e.g. 5 for 12345 and 4 for true, and null becomes an empty string.
+func WithLengthPreservingMask(maskChar rune) option {
+	return WithMaskFunc(func(field any) string {
+		return strings.Repeat(string(maskChar), valueLength(field))
+	})
+}
+
+// WithTruncateMask masks values with template, every "%d" of which is replaced with their length,
+// keeping a sense of the size of long values without their content, e.g. "[REDACTED:%d chars]" masks
+// a 142 characters string as "[REDACTED:142 chars]". The rest of the template is kept as it is written,
+// other verbs and "%%" included. The length is counted like WithLengthPreservingMask does,
+// and an empty template uses DefaultTruncateTemplate.
+func WithTruncateMask(template string) option {
+	if template == "" {
+		template = DefaultTruncateTemplate
+	}
+	return WithMaskFunc(func(field any) string {
+		return strings.ReplaceAll(template, "%d", strconv.Itoa(valueLength(field)))
+	})
+}
+
+// valueLength returns the number of characters of a field value: runes for strings,
+// the runes of the JSON encoding for other values and 0 for null.
+func valueLength(field any) int {
+	switch v := field.(type) {
+	case nil:
+		return 0
+	case string:
+		return utf8.RuneCountInString(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(v))
+		}
+		return utf8.RuneCount(encoded)
+	}
+}
+
+// WithHashMask masks values with the hex encoded SHA-256 hash of salt + fmt.Sprint(value).
+// The same value always produces the same hash, so masked fields can still be joined on.
+// Masking a masked document again hashes the hashes, see WithSkipMasked to keep them.
+func WithHashMask(salt string) option {
+	return WithMaskFunc(func(field any) string {
+		sum := sha256.Sum256([]byte(salt + fmt.Sprint(field)))
+		return hex.EncodeToString(sum[:])
+	})
+}
+
+// WithHMACMask masks values with the hex encoded HMAC-SHA256 of fmt.Sprint(value) using key.
+// Like WithHashMask the output is deterministic, but can't be reproduced without the key,
+// and masking it again changes it.
+func WithHMACMask(key []byte) option {
+	return WithMaskFunc(func(field any) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(fmt.Sprint(field)))
+		return hex.EncodeToString(mac.Sum(nil))
+	})
+}
+
+// WithReplacementMap masks values with the token replacements maps fmt.Sprint(value) to,
+// e.g. tokens kept in an external vault, so a value is tokenized the same way across documents.
+// Values missing from replacements are replaced with DefaultMaskString.
+func WithReplacementMap(replacements map[string]string) option {
+	return WithMaskFunc(func(field any) string {
+		if token, ok := replacements[fmt.Sprint(field)]; ok {
+			return token
+		}
+		return DefaultMaskString
+	})
+}
+
+// WithEmailMask masks email addresses keeping their domain and the first keep characters
+// of their local part, e.g. "john.doe@example.com" becomes "j***@example.com" with keep 1.
+// The rest of the local part, including any "+tag", is replaced with "***" regardless of its length.
+// Local parts that are not longer than keep are masked entirely, a negative keep being the same as 0,
+// and values that aren't email addresses are replaced with DefaultMaskString.
+func WithEmailMask(keep int) option {
+	keep = max(keep, 0)
+	return WithMaskFunc(func(field any) string {
+		str, ok := field.(string)
+		if !ok {
+			return DefaultMaskString
+		}
+		local, domain, ok := splitEmail(str)
+		if !ok {
+			return DefaultMaskString
+		}
+		runes := []rune(local)
+		if len(runes) <= keep {
+			return "***@" + domain
+		}
+		return string(runes[:keep]) + "***@" + domain
+	})
+}
+
+// cardBINLength is the number of leading digits of card numbers kept by WithCreditCardMask.
+const cardBINLength = 6
+
+// WithCreditCardMask masks credit card numbers, as matched by IsCreditCard, with fake numbers
+// that still pass format and Luhn validation: the BIN, the first 6 digits, is kept, the following
+// digits are replaced with zeros and the last one with a valid check digit, spaces and dashes
+// staying in place, e.g. "4111 1111 1111 1111" becomes "4111 1100 0000 0005".
+// Other values are replaced with DefaultMaskString.
+func WithCreditCardMask() option {
+	return WithMaskFunc(func(field any) string {
+		if !IsCreditCard(field) {
+			return DefaultMaskString
+		}
+		masked := []byte(field.(string))
+		var digits []int
+		for i, c := range masked {
+			if c >= '0' && c <= '9' {
+				digits = append(digits, i)
+			}
+		}
+		for _, i := range digits[cardBINLength:] {
+			masked[i] = '0'
+		}
+		number := make([]byte, 0, len(digits))
+		for _, i := range digits {
+			number = append(number, masked[i])
+		}
+		masked[digits[len(digits)-1]] = byte('0' + (10-luhnSum(number)%10)%10)
+		return string(masked)
+	})
+}
+
+// splitEmail splits an email shaped string into its local part and domain.
+// The domain must have at least two non-empty labels, e.g. "example.com".
+func splitEmail(str string) (string, string, bool) {
+	local, domain, ok := strings.Cut(str, "@")
+	if !ok || local == "" || strings.ContainsAny(str, " \t\r\n") || strings.Contains(domain, "@") {
+		return "", "", false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", "", false
+	}
+	for _, label := range labels {
+		if label == "" {
+			return "", "", false
+		}
+	}
+	return local, domain, true
+}
+
+// stringify returns the string representation of a field value.
+func stringify(field any) string {
+	if str, ok := field.(string); ok {
+		return str
+	}
+	return fmt.Sprint(field)
+}
@@ -0,0 +1,473 @@
+package masker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPartialMask(t *testing.T) {
+	testTable := []struct {
+		name     string
+		keep     int
+		field    any
+		expected string
+	}{
+		{
+			name:     "keeps the last characters",
+			keep:     4,
+			field:    "4111111111111234",
+			expected: "************1234",
+		},
+		{
+			name:     "short string is fully masked",
+			keep:     4,
+			field:    "123",
+			expected: "***",
+		},
+		{
+			name:     "exact length string is fully masked",
+			keep:     4,
+			field:    "1234",
+			expected: "****",
+		},
+		{
+			name:     "multibyte string",
+			keep:     2,
+			field:    "héllo",
+			expected: "***lo",
+		},
+		{
+			name:     "number",
+			keep:     2,
+			field:    float64(123456),
+			expected: "****56",
+		},
+		{
+			name:     "negative count",
+			keep:     -2,
+			field:    "1234",
+			expected: "****",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskFuncOf(WithPartialMask(tt.keep, '*'))(tt.field))
+		})
+	}
+}
+
+func TestMask_partialMask(t *testing.T) {
+	masker := NewMasker([]string{"$.card"}, WithPartialMask(4, '*'))
+	output, err := masker.Mask(`{"card":"4111111111111234"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"card":"************1234"}`, output)
+}
+
+func TestWithPartialMaskPrefix(t *testing.T) {
+	testTable := []struct {
+		name     string
+		show     int
+		field    any
+		expected string
+	}{
+		{
+			name:     "keeps the first characters",
+			show:     3,
+			field:    "abc-123-456",
+			expected: "abc********",
+		},
+		{
+			name:     "short string is fully masked",
+			show:     4,
+			field:    "abc",
+			expected: "***",
+		},
+		{
+			name:     "exact length string is fully masked",
+			show:     3,
+			field:    "abc",
+			expected: "***",
+		},
+		{
+			name:     "multibyte string",
+			show:     2,
+			field:    "héllo",
+			expected: "hé***",
+		},
+		{
+			name:     "number",
+			show:     2,
+			field:    float64(123456),
+			expected: "12****",
+		},
+		{
+			name:     "negative count",
+			show:     -2,
+			field:    "abc",
+			expected: "***",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskFuncOf(WithPartialMaskPrefix(tt.show, '*'))(tt.field))
+		})
+	}
+}
+
+func TestMask_partialMaskPrefix(t *testing.T) {
+	masker := NewMasker([]string{"$.requestId"}, WithPartialMaskPrefix(4, '*'))
+	output, err := masker.Mask(`{"requestId":"req-8f3a9c"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"requestId":"req-******"}`, output)
+}
+
+func TestWithHashMask(t *testing.T) {
+	// sha256("salt" + "john@example.com")
+	assert.Equal(t, "84275df39f6d1786a47398ad2d1fd49333063ed7a398902205210d4f068cfd2c", maskFuncOf(WithHashMask("salt"))("john@example.com"))
+	assert.Equal(t, maskFuncOf(WithHashMask("salt"))("john@example.com"), maskFuncOf(WithHashMask("salt"))("john@example.com"))
+	assert.NotEqual(t, maskFuncOf(WithHashMask("salt"))("john@example.com"), maskFuncOf(WithHashMask("pepper"))("john@example.com"))
+	assert.NotEqual(t, maskFuncOf(WithHashMask("salt"))("john@example.com"), maskFuncOf(WithHashMask("salt"))("jane@example.com"))
+	assert.Len(t, maskFuncOf(WithHashMask("salt"))(float64(42)), 64)
+}
+
+func TestWithHMACMask(t *testing.T) {
+	assert.Equal(t, maskFuncOf(WithHMACMask([]byte("key")))("john@example.com"), maskFuncOf(WithHMACMask([]byte("key")))("john@example.com"))
+	assert.NotEqual(t, maskFuncOf(WithHMACMask([]byte("key")))("john@example.com"), maskFuncOf(WithHMACMask([]byte("other")))("john@example.com"))
+	assert.Len(t, maskFuncOf(WithHMACMask([]byte("key")))("john@example.com"), 64)
+}
+
+func TestWithReplacementMap(t *testing.T) {
+	replacements := map[string]string{"john@example.com": "user-1", "42": "num-1"}
+	testTable := []struct {
+		name     string
+		field    any
+		expected string
+	}{
+		{
+			name:     "mapped string",
+			field:    "john@example.com",
+			expected: "user-1",
+		},
+		{
+			name:     "mapped number",
+			field:    json.Number("42"),
+			expected: "num-1",
+		},
+		{
+			name:     "unmapped value",
+			field:    "jane@example.com",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "null",
+			field:    nil,
+			expected: DefaultMaskString,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskFuncOf(WithReplacementMap(replacements))(tt.field))
+		})
+	}
+}
+
+func TestMask_replacementMap(t *testing.T) {
+	masker := NewMasker([]string{"$..email"}, WithReplacementMap(map[string]string{"john@example.com": "user-1"}))
+	output, err := masker.Mask(`[{"email":"john@example.com"},{"email":"jane@example.com"},{"email":"john@example.com"}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"email":"user-1"},{"email":"[REDACTED]"},{"email":"user-1"}]`, output)
+}
+
+func TestWithCreditCardMask(t *testing.T) {
+	testTable := []struct {
+		name     string
+		field    any
+		expected string
+	}{
+		{
+			name:     "visa",
+			field:    "4111111111111111",
+			expected: "4111110000000005",
+		},
+		{
+			name:     "grouped with spaces",
+			field:    "4111 1111 1111 1111",
+			expected: "4111 1100 0000 0005",
+		},
+		{
+			name:     "grouped with dashes",
+			field:    "5500-0000-0000-0004",
+			expected: "5500-0000-0000-0004",
+		},
+		{
+			name:     "amex",
+			field:    "378282246310005",
+			expected: "378282000000008",
+		},
+		{
+			name:     "failing luhn",
+			field:    "4111111111111112",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "not a card",
+			field:    "john",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "number",
+			field:    json.Number("4111111111111111"),
+			expected: DefaultMaskString,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masked := maskFuncOf(WithCreditCardMask())(tt.field)
+			assert.Equal(t, tt.expected, masked)
+			if str, ok := tt.field.(string); ok && masked != DefaultMaskString {
+				assert.Len(t, masked, len(str))
+				assert.Equal(t, str[:6], masked[:6])
+				assert.True(t, IsCreditCard(masked))
+			}
+		})
+	}
+}
+
+func TestMask_creditCardMask(t *testing.T) {
+	masker := NewMasker([]string{"$.card", "$.name"}, WithCreditCardMask())
+	output, err := masker.Mask(`{"card":"6011111111111117","name":"John"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"card":"6011110000000001","name":"[REDACTED]"}`, output)
+}
+
+func TestWithEmailMask(t *testing.T) {
+	testTable := []struct {
+		name     string
+		keep     int
+		field    any
+		expected string
+	}{
+		{
+			name:     "keeps the domain and first character",
+			keep:     1,
+			field:    "john.doe@example.com",
+			expected: "j***@example.com",
+		},
+		{
+			name:     "keeps more leading characters",
+			keep:     3,
+			field:    "john.doe@example.com",
+			expected: "joh***@example.com",
+		},
+		{
+			name:     "masks plus addressing",
+			keep:     1,
+			field:    "john+newsletter@mail.example.co.uk",
+			expected: "j***@mail.example.co.uk",
+		},
+		{
+			name:     "short local part is fully masked",
+			keep:     2,
+			field:    "jo@example.com",
+			expected: "***@example.com",
+		},
+		{
+			name:     "keeps nothing",
+			keep:     0,
+			field:    "john@example.com",
+			expected: "***@example.com",
+		},
+		{
+			name:     "multibyte local part",
+			keep:     1,
+			field:    "élodie@example.fr",
+			expected: "é***@example.fr",
+		},
+		{
+			name:     "not an email",
+			keep:     1,
+			field:    "john.doe",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "missing local part",
+			keep:     1,
+			field:    "@example.com",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "domain without dot",
+			keep:     1,
+			field:    "john@localhost",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "multiple at signs",
+			keep:     1,
+			field:    "a@b@example.com",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "spaces",
+			keep:     1,
+			field:    "john doe@example.com",
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "number",
+			keep:     1,
+			field:    float64(42),
+			expected: DefaultMaskString,
+		},
+		{
+			name:     "negative count",
+			keep:     -1,
+			field:    "john.doe@example.com",
+			expected: "***@example.com",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskFuncOf(WithEmailMask(tt.keep))(tt.field))
+		})
+	}
+}
+
+func TestMask_emailMask(t *testing.T) {
+	masker := NewMasker([]string{"$.users[].email"}, WithEmailMask(1))
+	output, err := masker.Mask(`{"users":[{"email":"john.doe@example.com"},{"email":"unknown"}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"users":[{"email":"j***@example.com"},{"email":"[REDACTED]"}]}`, output)
+}
+
+func TestWithLengthPreservingMask(t *testing.T) {
+	testTable := []struct {
+		name     string
+		field    any
+		expected string
+	}{
+		{
+			name:     "ascii string",
+			field:    "secret1234",
+			expected: "••••••••••",
+		},
+		{
+			name:     "multibyte string",
+			field:    "héllo wörld",
+			expected: "•••••••••••",
+		},
+		{
+			name:     "empty string",
+			field:    "",
+			expected: "",
+		},
+		{
+			name:     "json number",
+			field:    json.Number("12345.67"),
+			expected: "••••••••",
+		},
+		{
+			name:     "float",
+			field:    float64(42),
+			expected: "••",
+		},
+		{
+			name:     "boolean",
+			field:    false,
+			expected: "•••••",
+		},
+		{
+			name:     "null",
+			field:    nil,
+			expected: "",
+		},
+		{
+			name:     "object",
+			field:    map[string]any{"a": "b"},
+			expected: "•••••••••",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskFuncOf(WithLengthPreservingMask('•'))(tt.field))
+		})
+	}
+}
+
+func TestMask_lengthPreservingMask(t *testing.T) {
+	masker := NewMasker([]string{"$.password", "$.pin"}, WithLengthPreservingMask('*'))
+	output, err := masker.Mask(`{"password":"hunter2","pin":1234}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password":"*******","pin":"****"}`, output)
+}
+
+func TestWithTruncateMask(t *testing.T) {
+	testTable := []struct {
+		name     string
+		template string
+		field    any
+		expected string
+	}{
+		{
+			name:     "default template",
+			field:    strings.Repeat("a", 142),
+			expected: "[REDACTED:142 chars]",
+		},
+		{
+			name:     "multibyte string",
+			template: "<%d>",
+			field:    "héllo wörld 日本",
+			expected: "<14>",
+		},
+		{
+			name:     "empty string",
+			field:    "",
+			expected: "[REDACTED:0 chars]",
+		},
+		{
+			name:     "json number",
+			template: "len=%d",
+			field:    json.Number("12345.67"),
+			expected: "len=8",
+		},
+		{
+			name:     "null",
+			field:    nil,
+			expected: "[REDACTED:0 chars]",
+		},
+		{
+			name:     "template without length",
+			template: "[REDACTED]",
+			field:    "secret",
+			expected: "[REDACTED]",
+		},
+		{
+			name:     "template with other verbs",
+			template: "%s 100% %d/%d",
+			field:    "abc",
+			expected: "%s 100% 3/3",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskFuncOf(WithTruncateMask(tt.template))(tt.field))
+		})
+	}
+}
+
+func TestMask_truncateMask(t *testing.T) {
+	masker := NewMasker([]string{"$.token"}, WithTruncateMask(""))
+	output, err := masker.Mask(`{"token":"ünïcødé"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"token":"[REDACTED:7 chars]"}`, output)
+}
@@ -0,0 +1,114 @@
+package masker
+
+// Segment is a single segment of the path of a node in a document,
+// either an object key or an array index.
+type Segment struct {
+	// Key is the object key, empty for array indexes.
+	Key string
+	// Index is the array index, used only when IsIndex is set.
+	Index int
+	// IsIndex is set when the segment is an array index.
+	IsIndex bool
+}
+
+// PathMatcher decides which nodes of a document are masked, allowing custom path grammars.
+// Matches receives the path of every node visited from the root, the root itself
+// having an empty path, and returns true if the node should be masked as a whole.
+type PathMatcher interface {
+	Matches(path []Segment) bool
+}
+
+// WithMatcher adds a custom matcher, masking the nodes it matches along with the nodes
+// matched by the mask paths. Matchers are consulted for every node visited,
+// but not for the descendants of masked nodes.
+func WithMatcher(matcher PathMatcher) option {
+	return func(m *masker) {
+		m.matchers = append(m.matchers, matcher)
+	}
+}
+
+// WithMaskKeys masks the object members whose key is one of keys wherever they appear,
+// like a "$..key" path for each of them, but whatever the mask paths, including the paths
+// passed to MaskWithPaths. Keys are compared as they are, without glob patterns.
+func WithMaskKeys(keys ...string) option {
+	set := make(keySet, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return WithMatcher(set)
+}
+
+// keySet is the matcher of WithMaskKeys, matching the members whose key is in the set.
+type keySet map[string]bool
+
+func (s keySet) Matches(path []Segment) bool {
+	if len(path) == 0 {
+		return false
+	}
+	last := path[len(path)-1]
+	return !last.IsIndex && s[last.Key]
+}
+
+// segments converts the path to the segments handed to matchers, without the root.
+func (p nodePath) segments() []Segment {
+	segments := make([]Segment, 0, len(p))
+	for _, s := range p {
+		switch s.kind {
+		case keySegment:
+			segments = append(segments, Segment{Key: s.key})
+		case indexSegment:
+			segments = append(segments, Segment{Index: s.index, IsIndex: true})
+		}
+	}
+	return segments
+}
+
+// matches checks if the node at path should be masked as a whole,
+// by the mask paths or by one of the matchers of the masker.
+// With exclusions, the nodes matched above which an exclusion may match a descendant
+// aren't masked as a whole, their leaves that aren't excluded being masked instead, see inheritsMask.
+// As the exclusions with "**" or regular expressions may match below any node, the nodes they apply to
+// keep their structure, e.g. their keys.
+func (s *maskState) matches(path nodePath) bool {
+	if len(s.maskPaths.exclusions) == 0 {
+		return s.includes(path) || s.isAroundProtected
+	}
+	if s.maskPaths.excludes(path) || s.maskPaths.excludesBelow(path) {
+		return false
+	}
+	return s.includes(path) || s.isAroundProtected || s.inheritsMask(path)
+}
+
+// inheritsMask checks if the node at path is, or is below, a node matched by the mask paths
+// and isn't excluded, for the leaves to be masked when they or their ancestors weren't masked as a whole,
+// because of the exclusions or WithDeepMaskSubtrees. The leaves themselves matched aren't masked as a whole
+// when an exclusion with "**" may match below any node.
+func (s *maskState) inheritsMask(path nodePath) bool {
+	if (len(s.maskPaths.exclusions) == 0 && !s.isDeep) || s.maskPaths.excludes(path) {
+		return false
+	}
+	for i := len(path); i > 0; i-- {
+		if s.includes(path[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// includes checks if the node at path is matched by the mask paths or by one of the matchers,
+// regardless of the exclusions.
+func (s *maskState) includes(path nodePath) bool {
+	if s.maskPaths.matches(path) || s.matchesTagged(path) {
+		return true
+	}
+	if len(s.matchers) == 0 {
+		return false
+	}
+	segments := path.segments()
+	for _, matcher := range s.matchers {
+		if matcher.Matches(segments) {
+			return true
+		}
+	}
+	return false
+}
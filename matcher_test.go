@@ -0,0 +1,138 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// evenDepthMatcher masks every node at an even, non-zero depth.
+type evenDepthMatcher struct{}
+
+func (evenDepthMatcher) Matches(path []Segment) bool {
+	return len(path) > 0 && len(path)%2 == 0
+}
+
+// recordingMatcher records the paths it is asked about and matches nothing.
+type recordingMatcher struct {
+	paths [][]Segment
+}
+
+func (r *recordingMatcher) Matches(path []Segment) bool {
+	r.paths = append(r.paths, path)
+	return false
+}
+
+func TestWithMatcher(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:     "Test with nested objects",
+			input:    `{"a":{"b":{"c":1}},"d":1}`,
+			expected: `{"a":{"b":"[REDACTED]"},"d":1}`,
+		},
+		{
+			name:     "Test with arrays",
+			input:    `{"list":[1,[2,3]],"id":1}`,
+			expected: `{"list":["[REDACTED]","[REDACTED]"],"id":1}`,
+		},
+		{
+			name:      "Test with mask paths",
+			input:     `{"a":{"b":1},"d":1}`,
+			maskPaths: []string{"$.d"},
+			expected:  `{"a":{"b":"[REDACTED]"},"d":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, WithMatcher(evenDepthMatcher{}))
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with segments", func(t *testing.T) {
+		matcher := &recordingMatcher{}
+		_, err := NewMasker(nil, WithMatcher(matcher)).Mask(`{"users":[{"name":"a"}]}`)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]Segment{
+			{},
+			{{Key: "users"}},
+			{{Key: "users"}, {Index: 0, IsIndex: true}},
+			{{Key: "users"}, {Index: 0, IsIndex: true}, {Key: "name"}},
+		}, matcher.paths)
+	})
+}
+
+func TestWithMaskKeys(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name:     "Test with keys at different depths",
+			input:    `{"password":"a","user":{"name":"John","password":"b","token":"c"}}`,
+			expected: `{"password":"[REDACTED]","user":{"name":"John","password":"[REDACTED]","token":"[REDACTED]"}}`,
+		},
+		{
+			name:     "Test with keys inside arrays",
+			input:    `{"users":[{"password":"a"},{"id":1,"auth":[{"token":"b"}]}]}`,
+			expected: `{"users":[{"password":"[REDACTED]"},{"id":1,"auth":[{"token":"[REDACTED]"}]}]}`,
+		},
+		{
+			name:     "Test with masked object",
+			input:    `{"token":{"password":"a"}}`,
+			expected: `{"token":"[REDACTED]"}`,
+		},
+		{
+			name:     "Test with index not matching",
+			input:    `["password"]`,
+			expected: `["password"]`,
+		},
+		{
+			name:      "Test with mask paths",
+			input:     `{"password":"a","id":1}`,
+			maskPaths: []string{"$.id"},
+			expected:  `{"password":"[REDACTED]","id":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, WithMaskKeys("password", "token"))
+			output, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with call paths", func(t *testing.T) {
+		output, err := NewMasker(nil, WithMaskKeys("password")).MaskWithPaths(`{"password":"a","id":1}`, []string{"$.id"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"password":"[REDACTED]","id":"[REDACTED]"}`, output)
+	})
+
+	t.Run("Test with Go map", func(t *testing.T) {
+		masked, err := NewMasker(nil, WithMaskKeys("password")).MaskValue(map[string]any{"user": map[string]string{"password": "a"}}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"user": map[string]string{"password": "[REDACTED]"}}, masked)
+	})
+}
@@ -0,0 +1,114 @@
+package masker
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MaskingMiddleware returns a net/http middleware masking the JSON responses of the wrapped handler
+// with a masker created by NewMasker(maskPaths, opts...).
+// Responses whose Content-Type is application/json or ends with +json, e.g. application/problem+json,
+// are buffered until the handler returns, masked and written with the status and headers set by the handler,
+// Content-Length being updated to the length of the masked body. Other responses are written as they come.
+// The Content-Type has to be set before the handler calls WriteHeader or Write to be taken into account.
+// If a JSON response can't be masked, e.g. because it isn't valid JSON, it is replaced with an empty
+// 500 Internal Server Error response, so unmasked data never leaks.
+func MaskingMiddleware(maskPaths []string, opts ...option) func(http.Handler) http.Handler {
+	m := NewMasker(maskPaths, opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mw := &maskingResponseWriter{ResponseWriter: w, masker: m}
+			next.ServeHTTP(mw, r)
+			mw.finish()
+		})
+	}
+}
+
+// maskingResponseWriter buffers the JSON responses written to it, see MaskingMiddleware.
+type maskingResponseWriter struct {
+	http.ResponseWriter
+	masker Masker
+	// status is the status code set by the handler.
+	status      int
+	wroteHeader bool
+	// buffering is set if the response is JSON, its body being held in body until finish.
+	buffering bool
+	body      bytes.Buffer
+}
+
+// WriteHeader records the status code, sending it right away unless the response is JSON.
+func (w *maskingResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+	w.buffering = isJSONContentType(w.Header().Get("Content-Type"))
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write buffers the body of JSON responses and writes the others through.
+func (w *maskingResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the response written so far, like http.Flusher, unless the response is JSON,
+// whose body is only sent once masked. It is found by http.ResponseController before Unwrap,
+// so flushing never reaches the wrapped ResponseWriter while the body is buffered.
+func (w *maskingResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *maskingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish masks and writes the buffered JSON response once the handler returned.
+func (w *maskingResponseWriter) finish() {
+	if !w.buffering {
+		return
+	}
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+		return
+	}
+	masked, err := w.masker.MaskBytes(w.body.Bytes())
+	if err != nil {
+		w.Header().Del("Content-Length")
+		w.Header().Del("Content-Type")
+		w.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(masked)))
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(masked)
+}
+
+// isJSONContentType checks if the Content-Type header value is a JSON media type.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,120 @@
+package masker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingMiddleware(t *testing.T) {
+	testTable := []struct {
+		name            string
+		contentType     string
+		status          int
+		body            string
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:            "Test with JSON response",
+			contentType:     "application/json; charset=utf-8",
+			status:          http.StatusCreated,
+			body:            `{"name":"John","ssn":"123-45-6789"}`,
+			expectedStatus:  http.StatusCreated,
+			expectedBody:    `{"name":"John","ssn":"[REDACTED]"}`,
+			expectedHeaders: map[string]string{"Content-Type": "application/json; charset=utf-8", "Content-Length": "34"},
+		},
+		{
+			name:           "Test with JSON suffix",
+			contentType:    "application/problem+json",
+			body:           `{"ssn":"123"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ssn":"[REDACTED]"}`,
+		},
+		{
+			name:           "Test with non JSON response",
+			contentType:    "text/plain",
+			body:           `{"ssn":"123"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ssn":"123"}`,
+		},
+		{
+			name:           "Test with empty JSON response",
+			contentType:    "application/json",
+			status:         http.StatusNoContent,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Test with invalid JSON response",
+			contentType:    "application/json",
+			body:           `{"ssn":"123"`,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				// a stale length must be replaced with the length of the masked body
+				w.Header().Set("Content-Length", "999")
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				// write in two chunks to check the body is buffered as a whole
+				io.WriteString(w, tt.body[:len(tt.body)/2])
+				io.WriteString(w, tt.body[len(tt.body)/2:])
+			})
+
+			rec := httptest.NewRecorder()
+			MaskingMiddleware([]string{"$.ssn"})(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			for key, value := range tt.expectedHeaders {
+				assert.Equal(t, value, rec.Header().Get(key))
+			}
+		})
+	}
+}
+
+func TestMaskingMiddleware_flush(t *testing.T) {
+	testTable := []struct {
+		name            string
+		contentType     string
+		expectedBody    string
+		expectedFlushed bool
+	}{
+		{
+			name:         "Test with JSON response",
+			contentType:  "application/json",
+			expectedBody: `{"ssn":"[REDACTED]"}`,
+		},
+		{
+			name:            "Test with non JSON response",
+			contentType:     "text/plain",
+			expectedBody:    `{"ssn":"123"}`,
+			expectedFlushed: true,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				io.WriteString(w, `{"ssn":`)
+				assert.NoError(t, http.NewResponseController(w).Flush())
+				io.WriteString(w, `"123"}`)
+			})
+
+			rec := httptest.NewRecorder()
+			MaskingMiddleware([]string{"$.ssn"})(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			assert.Equal(t, tt.expectedFlushed, rec.Flushed)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package masker
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// WithNestedJSON makes the string values at nestedPaths be masked as the JSON documents they hold,
+// e.g. with "$.payload" a mask path "$.payload.ssn" masks ssn inside {"payload":"{\"ssn\":\"123\"}"}.
+// The mask paths continue from the nested path, and the masked document is encoded back into a string.
+// Strings that aren't valid JSON are kept as they are, unless WithStrictNestedJSON is used.
+// nestedPaths use the same syntax as the mask paths, regular expressions with WithRegexPaths.
+func WithNestedJSON(nestedPaths []string) option {
+	return func(m *masker) {
+		m.nestedPaths = nestedPaths
+	}
+}
+
+// WithStrictNestedJSON makes masking return an error when a string value at one of the paths
+// of WithNestedJSON isn't valid JSON, instead of keeping it as it is.
+func WithStrictNestedJSON() option {
+	return func(m *masker) {
+		m.isStrictNested = true
+	}
+}
+
+// isNestedJSON checks if the string at path holds a JSON document to mask, see WithNestedJSON.
+func (m *masker) isNestedJSON(path nodePath) bool {
+	return m.nested != nil && m.nested.matches(path)
+}
+
+// maskNestedJSON masks the JSON document held by the string at path, returning it encoded.
+// Strings that aren't valid JSON are returned as they are, unless WithStrictNestedJSON is used.
+func (m *masker) maskNestedJSON(str string, state *maskState, path nodePath) (string, error) {
+	value, err := decodeJSON([]byte(str), m.maxDepth, m.duplicates)
+	if err != nil {
+		if m.isStrictNested {
+			return "", withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal nested JSON at path %s: %w", path, err))
+		}
+		m.log("Keeping invalid nested JSON", logActionKeep, path)
+		return str, nil
+	}
+	masked, err := m.maskWithPaths(reflect.ValueOf(value), state, path)
+	if err != nil {
+		return "", err
+	}
+	if isDropped(masked) {
+		masked = nil
+	}
+	encoded, err := json.Marshal(masked)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal nested JSON at path %s: %w", path, err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,91 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask_nestedJSON(t *testing.T) {
+	testTable := []struct {
+		name        string
+		input       string
+		maskPaths   []string
+		nestedPaths []string
+		opts        []option
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:        "Test with nested object",
+			input:       `{"payload":"{\"ssn\":\"123\",\"name\":\"John\"}"}`,
+			maskPaths:   []string{"$.payload.ssn"},
+			nestedPaths: []string{"$.payload"},
+			expected:    `{"payload":"{\"ssn\":\"[REDACTED]\",\"name\":\"John\"}"}`,
+		},
+		{
+			name:        "Test with nested JSON in arrays",
+			input:       `{"events":[{"data":"[{\"card\":\"4111\"}]"},{"data":"[]"}]}`,
+			maskPaths:   []string{"$.events[].data[].card"},
+			nestedPaths: []string{"$.events[].data"},
+			expected:    `{"events":[{"data":"[{\"card\":\"[REDACTED]\"}]"},{"data":"[]"}]}`,
+		},
+		{
+			name:        "Test with doubly nested JSON",
+			input:       `{"a":"{\"b\":\"{\\\"c\\\":1}\"}"}`,
+			maskPaths:   []string{"$.a.b.c"},
+			nestedPaths: []string{"$.a", "$.a.b"},
+			expected:    `{"a":"{\"b\":\"{\\\"c\\\":\\\"[REDACTED]\\\"}\"}"}`,
+		},
+		{
+			name:        "Test with masked nested string",
+			input:       `{"payload":"{\"ssn\":\"123\"}"}`,
+			maskPaths:   []string{"$.payload"},
+			nestedPaths: []string{"$.payload"},
+			expected:    `{"payload":"[REDACTED]"}`,
+		},
+		{
+			name:        "Test with invalid nested JSON",
+			input:       `{"payload":"not json","other":"{\"ssn\":1}"}`,
+			maskPaths:   []string{"$.payload.ssn", "$.other.ssn"},
+			nestedPaths: []string{"$.payload"},
+			expected:    `{"payload":"not json","other":"{\"ssn\":1}"}`,
+		},
+		{
+			name:        "Test with invalid nested JSON in strict mode",
+			input:       `{"payload":"not json"}`,
+			nestedPaths: []string{"$.payload"},
+			opts:        []option{WithStrictNestedJSON()},
+			expectedErr: ErrInvalidJSON,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker(tt.maskPaths, append(tt.opts, WithNestedJSON(tt.nestedPaths))...)
+			output, err := m.Mask(tt.input)
+			var out bytes.Buffer
+			streamErr := m.MaskReader(strings.NewReader(tt.input), &out, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.ErrorIs(t, streamErr, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.NoError(t, streamErr)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go values", func(t *testing.T) {
+		type event struct {
+			Data string
+		}
+		masked, err := NewMasker(nil, WithNestedJSON([]string{"$.Data"})).MaskValue(event{Data: `{"ssn":"123"}`}, []string{"$.Data.ssn"})
+		assert.NoError(t, err)
+		assert.Equal(t, event{Data: `{"ssn":"[REDACTED]"}`}, masked)
+	})
+}
@@ -0,0 +1,84 @@
+package masker
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WithFieldNameNormalizer makes the keys of the mask paths and of the documents be compared
+// after being normalized by normalizer, e.g. with SnakeCaseFieldName the path "$.UserEmail"
+// matches the keys "userEmail", "UserEmail" and "user_email" alike.
+// It applies to every path of the masker, including the paths of WithMaskFuncForPath,
+// WithConditionalMask, WithKeepOnly and WithNestedJSON. With WithRegexPaths, only the keys
+// of the documents are normalized, the regular expressions having to match the normalized keys.
+// Reported paths and the paths handed to replacers and matchers keep the keys of the document.
+func WithFieldNameNormalizer(normalizer func(string) string) option {
+	return func(m *masker) {
+		m.normalizer = normalizer
+	}
+}
+
+// SnakeCaseFieldName converts a field name from camelCase, PascalCase, kebab-case or snake_case
+// to snake_case, e.g. "userEmail", "UserEmail" and "user-email" all become "user_email".
+// Acronyms are kept together, "UserID" becomes "user_id" and "HTTPServer" becomes "http_server".
+// It is meant to be used with WithFieldNameNormalizer.
+func SnakeCaseFieldName(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ':
+			sb.WriteByte('_')
+		case unicode.IsUpper(r):
+			if i > 0 && runes[i-1] != '_' && runes[i-1] != '-' &&
+				(!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// LowerCaseFieldName compares field names case-insensitively, e.g. "UserEmail" and "useremail"
+// are the same field. It is meant to be used with WithFieldNameNormalizer.
+func LowerCaseFieldName(name string) string {
+	return strings.ToLower(name)
+}
+
+// normalizeKeys returns a copy of path with its keys normalized by normalizer,
+// or path itself if normalizer is nil.
+func normalizeKeys(path []segment, normalizer func(string) string) []segment {
+	if normalizer == nil {
+		return path
+	}
+	normalized := make([]segment, len(path))
+	for i, s := range path {
+		if s.kind == keySegment || s.kind == globSegment {
+			s.key = normalizer(s.key)
+		}
+		normalized[i] = s
+	}
+	return normalized
+}
+
+// normalized returns the pattern with its keys normalized by normalizer.
+// Regular expressions are left as they are.
+func (p pathPattern) normalized(normalizer func(string) string) pathPattern {
+	if p.segments != nil {
+		p.segments = normalizeKeys(p.segments, normalizer)
+	}
+	return p
+}
+
+// pathFuncKey returns the key of the pathFuncs map for a path, see pathKey, its keys normalized
+// if WithFieldNameNormalizer is used.
+func (m *masker) pathFuncKey(path string) string {
+	segments, err := parsePath(path)
+	if err != nil {
+		return path
+	}
+	return formatSegments(normalizeKeys(withRoot(segments), m.normalizer), DefaultArrayToken)
+}
@@ -0,0 +1,113 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCaseFieldName(t *testing.T) {
+	testTable := []struct {
+		name     string
+		expected string
+	}{
+		{name: "userEmail", expected: "user_email"},
+		{name: "UserEmail", expected: "user_email"},
+		{name: "user_email", expected: "user_email"},
+		{name: "user-email", expected: "user_email"},
+		{name: "UserID", expected: "user_id"},
+		{name: "HTTPServer", expected: "http_server"},
+		{name: "id", expected: "id"},
+		{name: "", expected: ""},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SnakeCaseFieldName(tt.name))
+		})
+	}
+}
+
+func TestMask_fieldNameNormalizer(t *testing.T) {
+	input := `{"userEmail":"john@example.com","billingAddress":{"zipCode":"75001"},"cards":[{"cardNumber":"4111"}],"userId":1}`
+	testTable := []struct {
+		name       string
+		maskPaths  []string
+		normalizer func(string) string
+		opts       []option
+		expected   string
+	}{
+		{
+			name:       "Test with Pascal case paths",
+			maskPaths:  []string{"$.UserEmail", "$.BillingAddress.ZipCode", "$.Cards[].CardNumber"},
+			normalizer: SnakeCaseFieldName,
+			expected:   `{"userEmail":"[REDACTED]","billingAddress":{"zipCode":"[REDACTED]"},"cards":[{"cardNumber":"[REDACTED]"}],"userId":1}`,
+		},
+		{
+			name:       "Test with snake case paths and wildcards",
+			maskPaths:  []string{"$..zip_code", "$.user_*"},
+			normalizer: SnakeCaseFieldName,
+			expected:   `{"userEmail":"[REDACTED]","billingAddress":{"zipCode":"[REDACTED]"},"cards":[{"cardNumber":"4111"}],"userId":"[REDACTED]"}`,
+		},
+		{
+			name:       "Test with lower case",
+			maskPaths:  []string{"$.USEREMAIL"},
+			normalizer: LowerCaseFieldName,
+			expected:   `{"userEmail":"[REDACTED]","billingAddress":{"zipCode":"75001"},"cards":[{"cardNumber":"4111"}],"userId":1}`,
+		},
+		{
+			name:       "Test with mask function for path",
+			maskPaths:  []string{"$.UserEmail", "$.UserId"},
+			normalizer: SnakeCaseFieldName,
+			opts: []option{WithMaskFuncForPath("$.user_email", func(field any) string {
+				return "email"
+			})},
+			expected: `{"userEmail":"email","billingAddress":{"zipCode":"75001"},"cards":[{"cardNumber":"4111"}],"userId":"[REDACTED]"}`,
+		},
+		{
+			name:       "Test with regex paths",
+			maskPaths:  []string{`^\$\.user_email$`},
+			normalizer: SnakeCaseFieldName,
+			opts:       []option{WithRegexPaths()},
+			expected:   `{"userEmail":"[REDACTED]","billingAddress":{"zipCode":"75001"},"cards":[{"cardNumber":"4111"}],"userId":1}`,
+		},
+		{
+			name:      "Test without normalizer",
+			maskPaths: []string{"$.UserEmail"},
+			expected:  input,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if tt.normalizer != nil {
+				opts = append(opts, WithFieldNameNormalizer(tt.normalizer))
+			}
+			m := NewMasker(tt.maskPaths, opts...)
+			output, err := m.Mask(input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with report", func(t *testing.T) {
+		_, masked, err := NewMasker(nil, WithFieldNameNormalizer(SnakeCaseFieldName)).MaskWithReport(input, []string{"$.UserEmail"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.userEmail"}, masked)
+	})
+
+	t.Run("Test with conditional mask", func(t *testing.T) {
+		output, err := NewMasker(nil, WithFieldNameNormalizer(SnakeCaseFieldName), WithConditionalMask("$.Cards[].CardNumber", func(node map[string]any) bool {
+			return true
+		})).Mask(input)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"cardNumber":"[REDACTED]"`)
+	})
+}
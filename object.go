@@ -0,0 +1,218 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var (
+	objectType = reflect.TypeOf(&object{})
+	anyType    = reflect.TypeOf((*any)(nil)).Elem()
+	// rawMessageType values are masked as the JSON they encode rather than as byte slices.
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// object is a decoded JSON object that keeps its keys in document order.
+// keys and values are parallel, a key only appearing more than once with DuplicateKeysKeepAll.
+type object struct {
+	keys   []string
+	values []any
+	// index holds the position of the first occurrence of every key.
+	index map[string]int
+}
+
+func newObject() *object {
+	return &object{index: make(map[string]int)}
+}
+
+// set sets the value of key. New keys are appended after the existing keys,
+// existing keys keep their position.
+func (o *object) set(key string, value any) {
+	if i, ok := o.index[key]; ok {
+		o.values[i] = value
+		return
+	}
+	o.add(key, value)
+}
+
+// add appends a member to the object, even if its key already exists.
+func (o *object) add(key string, value any) {
+	if _, ok := o.index[key]; !ok {
+		o.index[key] = len(o.keys)
+	}
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// MarshalJSON encodes the object with its keys in document order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	return appendJSON(nil, o)
+}
+
+// appendJSON appends the JSON encoding of value to buf. Decoded objects and arrays are encoded
+// in place rather than by json.Marshal, which reformats the output of every nested MarshalJSON
+// and would make encoding quadratic in the depth of the document.
+func appendJSON(buf []byte, value any) ([]byte, error) {
+	var err error
+	switch v := value.(type) {
+	case *object:
+		if v == nil {
+			return append(buf, "null"...), nil
+		}
+		buf = append(buf, '{')
+		for i, key := range v.keys {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = appendJSON(buf, key); err != nil {
+				return nil, err
+			}
+			buf = append(buf, ':')
+			if buf, err = appendJSON(buf, v.values[i]); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	case []any:
+		if v == nil {
+			return append(buf, "null"...), nil
+		}
+		buf = append(buf, '[')
+		for i, elem := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = appendJSON(buf, elem); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return append(buf, encoded...), nil
+	}
+}
+
+// toPlain converts a decoded value to the types json.Unmarshal would produce with UseNumber,
+// so that values handed to mask functions don't expose the object type.
+func toPlain(value any) any {
+	switch v := value.(type) {
+	case *object:
+		plain := make(map[string]any, len(v.keys))
+		for i, key := range v.keys {
+			plain[key] = toPlain(v.values[i])
+		}
+		return plain
+	case []any:
+		plain := make([]any, len(v))
+		for i, elem := range v {
+			plain[i] = toPlain(elem)
+		}
+		return plain
+	default:
+		return value
+	}
+}
+
+// decodeJSON decodes a single JSON document like json.Unmarshal into an interface{} would,
+// except that objects are decoded as *object to keep their key order
+// and numbers as json.Number to keep their precision.
+// Duplicate keys are handled according to duplicates, see DuplicateKeyMode.
+// Documents nested deeper than maxDepth return an error.
+func decodeJSON(data []byte, maxDepth int, duplicates DuplicateKeyMode) (any, error) {
+	d := &decoder{
+		dec:        json.NewDecoder(bytes.NewReader(data)),
+		maxDepth:   maxDepth,
+		duplicates: duplicates,
+		path:       rootPath(),
+	}
+	d.dec.UseNumber()
+	value, err := d.decodeValue()
+	if err != nil {
+		return nil, err
+	}
+	offset := d.dec.InputOffset()
+	if _, err := d.dec.Token(); err != io.EOF {
+		if err == nil {
+			rest := bytes.TrimLeft(data[offset:], " \t\r\n")
+			err = fmt.Errorf("invalid character %q after top-level value", rest[0])
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// decoder holds the state of a single decodeJSON call.
+type decoder struct {
+	dec        *json.Decoder
+	maxDepth   int
+	duplicates DuplicateKeyMode
+	// path is the path of the value being decoded, segments are pushed and popped
+	// while descending so it is only copied when reporting an error.
+	path nodePath
+}
+
+// decodeValue decodes the next value from the decoder.
+func (d *decoder) decodeValue() (any, error) {
+	if err := checkDepth(d.path, d.maxDepth); err != nil {
+		return nil, err
+	}
+	token, err := d.dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		obj := newObject()
+		for d.dec.More() {
+			key, err := d.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			d.path = append(d.path, segment{kind: keySegment, key: key.(string)})
+			if _, ok := obj.index[key.(string)]; ok && d.duplicates == DuplicateKeysError {
+				return nil, fmt.Errorf("duplicate key at path %s", d.path)
+			}
+			value, err := d.decodeValue()
+			d.path = d.path[:len(d.path)-1]
+			if err != nil {
+				return nil, err
+			}
+			if d.duplicates == DuplicateKeysKeepAll {
+				obj.add(key.(string), value)
+			} else {
+				obj.set(key.(string), value)
+			}
+		}
+		if _, err := d.dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		values := []any{}
+		for d.dec.More() {
+			d.path = append(d.path, segment{kind: indexSegment, index: len(values)})
+			value, err := d.decodeValue()
+			d.path = d.path[:len(d.path)-1]
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if _, err := d.dec.Token(); err != nil {
+			return nil, err
+		}
+		return values, nil
+	default:
+		return token, nil
+	}
+}
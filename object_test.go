@@ -0,0 +1,77 @@
+package masker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	testTable := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "keeps key order",
+			input:    `{"b":1,"a":{"d":[1,{"z":1,"y":2}],"c":null}}`,
+			expected: `{"b":1,"a":{"d":[1,{"z":1,"y":2}],"c":null}}`,
+		},
+		{
+			name:     "duplicate keys keep the first position and the last value",
+			input:    `{"a":1,"b":2,"a":3}`,
+			expected: `{"a":3,"b":2}`,
+		},
+		{
+			name:     "keeps number precision",
+			input:    `{"id":9007199254740993,"f":1.10,"e":1e100}`,
+			expected: `{"id":9007199254740993,"f":1.10,"e":1e100}`,
+		},
+		{
+			name:     "empty containers",
+			input:    `{"a":{},"b":[]}`,
+			expected: `{"a":{},"b":[]}`,
+		},
+		{
+			name:        "max depth",
+			input:       strings.Repeat(`{"a":`, DefaultMaxDepth+1) + "1" + strings.Repeat("}", DefaultMaxDepth+1),
+			expectedErr: "maximum depth of 1000 exceeded at path $" + strings.Repeat(".a", DefaultMaxDepth+1),
+		},
+		{
+			name:        "trailing data",
+			input:       `{"a":1} {"b":2}`,
+			expectedErr: "invalid character '{' after top-level value",
+		},
+		{
+			name:        "empty input",
+			input:       ``,
+			expectedErr: "unexpected EOF",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := decodeJSON([]byte(tt.input), DefaultMaxDepth, DuplicateKeysLastWins)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			output, err := json.Marshal(value)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}
+
+func TestToPlain(t *testing.T) {
+	value, err := decodeJSON([]byte(`{"a":[{"b":1}],"c":"d"}`), DefaultMaxDepth, DuplicateKeysLastWins)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": []any{map[string]any{"b": json.Number("1")}},
+		"c": "d",
+	}, toPlain(value))
+}
@@ -0,0 +1,27 @@
+package masker
+
+// WithOnMask calls hook every time a value is masked, with the concrete path of the node,
+// e.g. "$.items[3].card", and its original value as MaskDryRun reports it, e.g. to count
+// masked fields in metrics independently of how they are masked.
+// Masked keys are reported with the ~ suffix and the key as their value, and dropped values,
+// see WithDropMaskedFields, are reported as well. The hook isn't called by MaskDryRun,
+// and is called concurrently by MaskBatch with WithBatchConcurrency.
+func WithOnMask(hook func(path string, original any)) option {
+	return func(m *masker) {
+		m.onMask = hook
+	}
+}
+
+// notifyMasked calls the hook of WithOnMask, if any, for the value masked at path.
+func (m *masker) notifyMasked(path nodePath, original any) {
+	if m.onMask != nil {
+		m.onMask(path.String(), original)
+	}
+}
+
+// notifyMaskedKey calls the hook of WithOnMask, if any, for the key masked at path.
+func (m *masker) notifyMaskedKey(path nodePath, key string) {
+	if m.onMask != nil {
+		m.onMask(path.String()+keySuffix, key)
+	}
+}
@@ -0,0 +1,74 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOnMask(t *testing.T) {
+	input := `{"name":"John","ssn":"123","cards":[{"number":"4111"},{"number":"5500"}],"accounts":{"acc1":1}}`
+	maskPaths := []string{"$.ssn", "$.cards[].number", "$.accounts.*~"}
+	expectedCalls := []MaskHit{
+		{Path: "$.ssn", OriginalValue: "123"},
+		{Path: "$.cards[0].number", OriginalValue: "4111"},
+		{Path: "$.cards[1].number", OriginalValue: "5500"},
+		{Path: "$.accounts.acc1~", OriginalValue: "acc1"},
+	}
+	testTable := []struct {
+		name     string
+		opts     []option
+		expected string
+	}{
+		{
+			name:     "Test with replaced values",
+			expected: `{"name":"John","ssn":"[REDACTED]","cards":[{"number":"[REDACTED]"},{"number":"[REDACTED]"}],"accounts":{"[REDACTED]":1}}`,
+		},
+		{
+			name:     "Test with dropped values",
+			opts:     []option{WithDropMaskedFields()},
+			expected: `{"name":"John","cards":[{},{}],"accounts":{"[REDACTED]":1}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []MaskHit
+			hook := func(path string, original any) {
+				calls = append(calls, MaskHit{Path: path, OriginalValue: original})
+			}
+			masker := NewMasker(maskPaths, append(tt.opts, WithOnMask(hook))...)
+			output, err := masker.Mask(input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.Equal(t, expectedCalls, calls)
+
+			calls = nil
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+			assert.Equal(t, expectedCalls, calls)
+		})
+	}
+
+	t.Run("Test with masked object", func(t *testing.T) {
+		var originals []any
+		masker := NewMasker([]string{"$.user"}, WithOnMask(func(path string, original any) {
+			originals = append(originals, original)
+		}))
+		_, err := masker.Mask(`{"user":{"age":30}}`)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{map[string]any{"age": json.Number("30")}}, originals)
+	})
+
+	t.Run("Test with dry run", func(t *testing.T) {
+		count := 0
+		masker := NewMasker([]string{"$.ssn"}, WithOnMask(func(path string, original any) { count++ }))
+		_, err := masker.MaskDryRun(`{"ssn":"123"}`, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
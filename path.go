@@ -0,0 +1,852 @@
+package masker
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// keySuffix marks mask paths whose matching object keys are masked instead of their values,
+// e.g. "$.accounts.*~" masks the keys of the accounts object.
+const keySuffix = "~"
+
+// excludePrefix marks mask paths excluding the nodes they match, and the nodes below them,
+// from the nodes matched by the other paths, e.g. "!$.users.*.id".
+const excludePrefix = "!"
+
+// lastIndex is the index of the last element of an array, "[last]", like "[-1]".
+const lastIndex = "last"
+
+// quotedKeyReplacer escapes keys formatted as bracket-quoted segments.
+var quotedKeyReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// segmentKind is the kind of a single path segment.
+type segmentKind int
+
+const (
+	// rootSegment is the document root, "$".
+	rootSegment segmentKind = iota
+	// keySegment is an object key, ".key" or "['key']".
+	keySegment
+	// globSegment matches the object keys matching a glob pattern, ".secret*",
+	// where "*" matches any sequence of characters and "?" a single character.
+	globSegment
+	// indexSegment is a single array index, "[3]". Negative indexes count from the end of the array,
+	// "[-1]" or "[last]" being the last element.
+	indexSegment
+	// anyIndexSegment matches every array index, "[]".
+	anyIndexSegment
+	// rangeSegment matches the array indexes from index up to, not including, end,
+	// "[start:end]". Either bound can be omitted, an omitted end is stored as -1.
+	rangeSegment
+	// wildcardSegment matches exactly one object key or array index, "*", never descending further.
+	wildcardSegment
+	// descendantSegment matches any number of object keys or array indexes,
+	// including none. "$..key" is shorthand for "$.**.key".
+	descendantSegment
+)
+
+// segment is a single segment of a path.
+type segment struct {
+	kind  segmentKind
+	key   string
+	index int
+	end   int
+	// length is the length of the array of a concrete index segment, to match negative indexes,
+	// 0 when unknown.
+	length int
+}
+
+// matches checks if the pattern segment matches a single concrete segment.
+// descendantSegment is handled by matchSegments as it can match many segments.
+func (p segment) matches(s segment) bool {
+	switch p.kind {
+	case keySegment:
+		return s.kind == keySegment && s.key == p.key
+	case globSegment:
+		return s.kind == keySegment && matchGlob(p.key, s.key)
+	case indexSegment:
+		if p.index < 0 {
+			return s.kind == indexSegment && s.length > 0 && s.index == s.length+p.index
+		}
+		return s.kind == indexSegment && s.index == p.index
+	case anyIndexSegment:
+		return s.kind == indexSegment
+	case rangeSegment:
+		return s.kind == indexSegment && s.index >= p.index && (p.end < 0 || s.index < p.end)
+	case wildcardSegment:
+		return s.kind == keySegment || s.kind == indexSegment
+	default:
+		return s.kind == p.kind
+	}
+}
+
+// nodePath is the concrete path of a node in a document,
+// made only of the root, object keys and array indexes.
+type nodePath []segment
+
+func rootPath() nodePath {
+	return nodePath{{kind: rootSegment}}
+}
+
+// key returns the path of the child under key.
+// Like the paths of index, it may share the segments of p to keep descending linear
+// in the depth of the document, so it is only valid until the next child of p is created.
+// Paths kept longer must be copied.
+func (p nodePath) key(key string) nodePath {
+	return append(p, segment{kind: keySegment, key: key})
+}
+
+// index returns the path of the array element at index, see key.
+// Negative indexes don't match it, see element.
+func (p nodePath) index(index int) nodePath {
+	return append(p, segment{kind: indexSegment, index: index})
+}
+
+// element returns the path of the element at index of an array of the given length, see key.
+func (p nodePath) element(index, length int) nodePath {
+	return append(p, segment{kind: indexSegment, index: index, length: length})
+}
+
+// lastKey returns the key of the last key segment of the path, e.g. "cards" for "$.cards[2][0]",
+// or an empty string if it has none.
+func (p nodePath) lastKey() string {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i].kind == keySegment {
+			return p[i].key
+		}
+	}
+	return ""
+}
+
+// String formats the path, e.g. "$.users[2]['first.name']".
+func (p nodePath) String() string {
+	return formatSegments(p, "")
+}
+
+// pathSet is the set of mask paths used during a single Mask call.
+// Paths without wildcards are looked up in a trie, paths with wildcards
+// are matched segment by segment.
+type pathSet struct {
+	exact    *pathTrie
+	patterns []pathPattern
+	// keyPatterns holds the paths whose keys are masked, see keySuffix.
+	keyPatterns []pathPattern
+	// all holds every path of the set, including the exact ones, but not the exclusions.
+	all []pathPattern
+	// exclusions holds the paths excluding nodes from the set, see excludePrefix.
+	exclusions []pathPattern
+	// normalizer is applied to the keys of the paths before matching, see WithFieldNameNormalizer.
+	normalizer func(string) string
+}
+
+// newPathSet builds a pathSet from the provided maskPaths.
+func newPathSet(maskPaths []string) pathSet {
+	patterns := make([]pathPattern, 0, len(maskPaths))
+	for _, path := range maskPaths {
+		patterns = append(patterns, compilePattern(path))
+	}
+	return newPatternSet(patterns)
+}
+
+// newPatternSet builds a pathSet from compiled patterns.
+func newPatternSet(patterns []pathPattern) pathSet {
+	set := pathSet{exact: &pathTrie{}}
+	for _, pattern := range patterns {
+		if pattern.exclude {
+			if pattern.re != nil || pattern.segments != nil {
+				set.exclusions = append(set.exclusions, pattern)
+			}
+			continue
+		}
+		set.all = append(set.all, pattern)
+		switch {
+		case pattern.re != nil:
+			set.patterns = append(set.patterns, pattern)
+		case pattern.segments == nil:
+			// invalid paths never match
+		case pattern.keys:
+			set.keyPatterns = append(set.keyPatterns, pattern)
+		case pattern.isExact():
+			set.exact.insert(pattern.segments)
+		default:
+			set.patterns = append(set.patterns, pattern)
+		}
+	}
+	return set
+}
+
+// newRegexPathSet builds a pathSet from maskPaths written as regular expressions,
+// matched against the normalized paths of the nodes, their array indexes collapsed to arrayToken.
+func newRegexPathSet(maskPaths []string, arrayToken string) (pathSet, error) {
+	set := pathSet{exact: &pathTrie{}}
+	for _, path := range maskPaths {
+		exclude := strings.HasPrefix(path, excludePrefix)
+		re, err := regexp.Compile(strings.TrimPrefix(path, excludePrefix))
+		if err != nil {
+			return pathSet{}, withKind(ErrInvalidPath, fmt.Errorf("invalid mask path regex %q: %w", path, err))
+		}
+		pattern := pathPattern{path: path, re: re, arrayToken: arrayToken, exclude: exclude}
+		if exclude {
+			set.exclusions = append(set.exclusions, pattern)
+			continue
+		}
+		set.patterns = append(set.patterns, pattern)
+		set.all = append(set.all, pattern)
+	}
+	return set, nil
+}
+
+// withNormalizer returns a copy of the set with the keys of its paths normalized by normalizer,
+// the keys of the matched paths being normalized as well.
+func (s pathSet) withNormalizer(normalizer func(string) string) pathSet {
+	patterns := make([]pathPattern, 0, len(s.all)+len(s.exclusions))
+	for _, pattern := range append(s.all[:len(s.all):len(s.all)], s.exclusions...) {
+		patterns = append(patterns, pattern.normalized(normalizer))
+	}
+	set := newPatternSet(patterns)
+	set.normalizer = normalizer
+	return set
+}
+
+// with returns a copy of the set along with patterns, written in the path syntax,
+// their keys normalized like the ones of the set.
+func (s pathSet) with(patterns ...pathPattern) pathSet {
+	all := make([]pathPattern, 0, len(s.all)+len(s.exclusions)+len(patterns))
+	all = append(append(all, s.all...), s.exclusions...)
+	for _, pattern := range patterns {
+		if s.normalizer != nil {
+			pattern = pattern.normalized(s.normalizer)
+		}
+		all = append(all, pattern)
+	}
+	set := newPatternSet(all)
+	set.normalizer = s.normalizer
+	return set
+}
+
+// normalize returns the path with its keys normalized by the normalizer of the set.
+func (s pathSet) normalize(path nodePath) nodePath {
+	return normalizeKeys(path, s.normalizer)
+}
+
+// matches checks if the path matches any of the paths in the set.
+func (s pathSet) matches(path nodePath) bool {
+	path = s.normalize(path)
+	if s.exact.matches(path) {
+		return true
+	}
+	for _, pattern := range s.patterns {
+		if pattern.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesKey checks if the key of the node at path should be masked.
+func (s pathSet) matchesKey(path nodePath) bool {
+	path = s.normalize(path)
+	for _, pattern := range s.keyPatterns {
+		if pattern.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// covering returns the paths of the set that match the path
+// or any of its descendants.
+func (s pathSet) covering(path nodePath) []string {
+	path = s.normalize(path)
+	var paths []string
+	for _, pattern := range s.all {
+		if pattern.matchPrefix(path) {
+			paths = append(paths, pattern.path)
+		}
+	}
+	return paths
+}
+
+// excludes checks if the node at path, or one of its ancestors, is matched by an exclusion.
+func (s pathSet) excludes(path nodePath) bool {
+	path = s.normalize(path)
+	for _, pattern := range s.exclusions {
+		for i := len(path); i > 0; i-- {
+			if pattern.match(path[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// excludesBelow checks if an exclusion may match a descendant of the node at path.
+// Regular expressions can't be matched partially, so they may match below any node.
+func (s pathSet) excludesBelow(path nodePath) bool {
+	path = s.normalize(path)
+	for _, pattern := range s.exclusions {
+		if pattern.re != nil || pattern.matchPrefix(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexesFromEnd checks if the elements of the array at path may be matched by a negative index
+// of a path of the set, including its exclusions.
+func (s pathSet) indexesFromEnd(path nodePath) bool {
+	path = s.normalize(path)
+	for _, pattern := range append(s.all[:len(s.all):len(s.all)], s.exclusions...) {
+		if pattern.indexesFromEnd(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingAncestors returns the paths of the set that match one of the ancestors of path.
+func (s pathSet) matchingAncestors(path nodePath) []string {
+	path = s.normalize(path)
+	var paths []string
+	for _, pattern := range s.all {
+		for i := len(path) - 1; i > 0; i-- {
+			if pattern.match(path[:i]) {
+				paths = append(paths, pattern.path)
+				break
+			}
+		}
+	}
+	return paths
+}
+
+// pathTrie is a trie of the segments of the paths without wildcards, see pathPattern.isExact.
+// Nodes are matched by walking their path down the trie, without formatting it.
+type pathTrie struct {
+	// keys holds the children for object keys.
+	keys map[string]*pathTrie
+	// anyIndex is the child for "[]", matching every array index.
+	anyIndex *pathTrie
+	// end is set when a path ends at this node.
+	end bool
+}
+
+// insert adds the segments of a path to the trie. The root segment is implied.
+func (t *pathTrie) insert(segments []segment) {
+	node := t
+	for _, s := range segments {
+		switch s.kind {
+		case keySegment:
+			child, ok := node.keys[s.key]
+			if !ok {
+				if node.keys == nil {
+					node.keys = make(map[string]*pathTrie)
+				}
+				child = &pathTrie{}
+				node.keys[s.key] = child
+			}
+			node = child
+		case anyIndexSegment:
+			if node.anyIndex == nil {
+				node.anyIndex = &pathTrie{}
+			}
+			node = node.anyIndex
+		}
+	}
+	node.end = true
+}
+
+// matches checks if the path is one of the paths of the trie,
+// array indexes matching "[]".
+func (t *pathTrie) matches(path nodePath) bool {
+	if t == nil {
+		return false
+	}
+	node := t
+	for _, s := range path {
+		switch s.kind {
+		case keySegment:
+			node = node.keys[s.key]
+		case indexSegment:
+			node = node.anyIndex
+		}
+		if node == nil {
+			return false
+		}
+	}
+	return node.end
+}
+
+// normalizePath formats the path with its array indexes collapsed, e.g. "$.a[2]" becomes "$.a[]".
+func normalizePath(path nodePath) string {
+	return formatSegments(path, DefaultArrayToken)
+}
+
+// pathKey returns the normalized form of a configured path,
+// matching normalizePath of the concrete paths it applies to.
+func pathKey(path string) string {
+	segments, err := parsePath(path)
+	if err != nil {
+		return path
+	}
+	return formatSegments(withRoot(segments), DefaultArrayToken)
+}
+
+// withRoot prepends the root to configured paths written without it, so "user.email"
+// is the same path as "$.user.email" and "[0]" as "$[0]". Empty paths stay empty.
+func withRoot(segments []segment) []segment {
+	if len(segments) == 0 || segments[0].kind == rootSegment {
+		return segments
+	}
+	return append([]segment{{kind: rootSegment}}, segments...)
+}
+
+// formatSegments formats segments as a path.
+// Keys that can't be written with the dot notation are bracket-quoted.
+// If indexToken is set, array indexes are collapsed to it, as "[]" segments are,
+// otherwise they are formatted with their index.
+func formatSegments(segments []segment, indexToken string) string {
+	var sb strings.Builder
+	for i, s := range segments {
+		switch s.kind {
+		case rootSegment:
+			sb.WriteString("$")
+		case keySegment:
+			if isPlainKey(s.key) {
+				if i > 0 {
+					sb.WriteString(".")
+				}
+				sb.WriteString(s.key)
+			} else {
+				sb.WriteString("['")
+				sb.WriteString(quotedKeyReplacer.Replace(s.key))
+				sb.WriteString("']")
+			}
+		case globSegment:
+			if i > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(s.key)
+		case indexSegment:
+			if indexToken != "" {
+				sb.WriteString(indexToken)
+			} else {
+				sb.WriteString("[" + strconv.Itoa(s.index) + "]")
+			}
+		case anyIndexSegment:
+			if indexToken != "" {
+				sb.WriteString(indexToken)
+			} else {
+				sb.WriteString(DefaultArrayToken)
+			}
+		case rangeSegment:
+			sb.WriteString("[" + strconv.Itoa(s.index) + ":")
+			if s.end >= 0 {
+				sb.WriteString(strconv.Itoa(s.end))
+			}
+			sb.WriteString("]")
+		case wildcardSegment:
+			sb.WriteString(".*")
+		case descendantSegment:
+			sb.WriteString(".**")
+		}
+	}
+	return sb.String()
+}
+
+// isPlainKey reports whether the key can be written with the dot notation.
+func isPlainKey(key string) bool {
+	if key == "" || key == "*" || key == "**" {
+		return false
+	}
+	return !strings.ContainsAny(key, `.[]'"\*?`)
+}
+
+// parsePath parses a mask path into its segments.
+// Keys are separated by dots, e.g. "$.users.name", and can be bracket-quoted
+// when they contain special characters, e.g. "$['user.email']" or "$.['user.email']".
+// Array indexes are written in brackets, "[]" matching every index, "[3]" a single index
+// and "[start:end]" the indexes from start up to, not including, end. Either bound can be omitted.
+func parsePath(path string) ([]segment, error) {
+	var segments []segment
+	i := 0
+	if strings.HasPrefix(path, "$") {
+		segments = append(segments, segment{kind: rootSegment})
+		i++
+	}
+	for i < len(path) {
+		switch path[i] {
+		case '.':
+			i++
+			if i < len(path) && path[i] == '.' {
+				// recursive descent, the second dot starts the next key
+				segments = append(segments, segment{kind: descendantSegment})
+				continue
+			}
+			if i < len(path) && path[i] == '[' {
+				// bracket-quoted key after a dot, e.g. "$.['user.email']"
+				continue
+			}
+			s, end, err := parseKey(path, i)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+			i = end
+		case '[':
+			s, end, err := parseBracket(path, i)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+			i = end
+		default:
+			if i > 0 {
+				return nil, fmt.Errorf("expected . or [ at offset %d", i)
+			}
+			// paths may start with a key instead of the root, e.g. "user.name"
+			s, end, err := parseKey(path, i)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+			i = end
+		}
+	}
+	return segments, nil
+}
+
+// parseKey parses the dot notation key starting at path[start],
+// returning the segment and the offset right after the key.
+// Keys containing "*" or "?" are glob patterns, unless the characters are
+// escaped with a backslash, e.g. "$.secret*" matches "secretKey" while `$.a\*b` matches "a*b".
+func parseKey(path string, start int) (segment, int, error) {
+	end := start
+	for end < len(path) && path[end] != '.' && path[end] != '[' {
+		switch path[end] {
+		case '\\':
+			if end+1 < len(path) {
+				end++
+			}
+		case ']':
+			return segment{}, 0, fmt.Errorf("unexpected ] at offset %d", end)
+		}
+		end++
+	}
+	switch key := path[start:end]; key {
+	case "":
+		return segment{}, 0, fmt.Errorf("empty key at offset %d", start)
+	case "*":
+		return segment{kind: wildcardSegment}, end, nil
+	case "**":
+		return segment{kind: descendantSegment}, end, nil
+	default:
+		if isGlob(key) {
+			return segment{kind: globSegment, key: key}, end, nil
+		}
+		return segment{kind: keySegment, key: unescapeKey(key)}, end, nil
+	}
+}
+
+// isGlob reports whether the key contains unescaped glob metacharacters.
+func isGlob(key string) bool {
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '\\':
+			i++
+		case '*', '?':
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeKey removes the backslashes escaping the characters of a dot notation key.
+func unescapeKey(key string) string {
+	if !strings.Contains(key, `\`) {
+		return key
+	}
+	var sb strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] == '\\' && i+1 < len(key) {
+			i++
+		}
+		sb.WriteByte(key[i])
+	}
+	return sb.String()
+}
+
+// matchGlob checks if the key matches the glob pattern, "*" matching any sequence
+// of characters, "?" a single character and a backslash escaping the next character.
+func matchGlob(pattern, key string) bool {
+	// the last "*" seen and the key position it was tried at, to backtrack on mismatches
+	star, starKey := -1, 0
+	p, k := 0, 0
+	for k < len(key) {
+		if p < len(pattern) {
+			switch c := pattern[p]; c {
+			case '*':
+				star, starKey = p, k
+				p++
+				continue
+			case '?':
+				_, size := utf8.DecodeRuneInString(key[k:])
+				p, k = p+1, k+size
+				continue
+			case '\\':
+				if p+1 < len(pattern) {
+					c = pattern[p+1]
+					if key[k] == c {
+						p, k = p+2, k+1
+						continue
+					}
+				}
+			default:
+				if key[k] == c {
+					p, k = p+1, k+1
+					continue
+				}
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		// let the last "*" match one more character
+		_, size := utf8.DecodeRuneInString(key[starKey:])
+		starKey += size
+		p, k = star+1, starKey
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// parseBracket parses the bracket segment starting at path[start],
+// returning the segment and the offset right after the closing bracket.
+func parseBracket(path string, start int) (segment, int, error) {
+	i := start + 1
+	if i < len(path) && (path[i] == '\'' || path[i] == '"') {
+		quote := path[i]
+		var key strings.Builder
+		for i++; i < len(path); i++ {
+			switch c := path[i]; {
+			case c == '\\' && i+1 < len(path):
+				i++
+				key.WriteByte(path[i])
+			case c == quote:
+				if i+1 >= len(path) || path[i+1] != ']' {
+					return segment{}, 0, fmt.Errorf("expected ] after quoted key at offset %d", i+1)
+				}
+				return segment{kind: keySegment, key: key.String()}, i + 2, nil
+			default:
+				key.WriteByte(c)
+			}
+		}
+		return segment{}, 0, fmt.Errorf("unterminated quoted key at offset %d", start)
+	}
+	end := strings.IndexByte(path[i:], ']')
+	if end < 0 {
+		return segment{}, 0, fmt.Errorf("unterminated bracket at offset %d", start)
+	}
+	content := path[i : i+end]
+	next := i + end + 1
+	if content == "" {
+		return segment{kind: anyIndexSegment}, next, nil
+	}
+	if from, to, ok := strings.Cut(content, ":"); ok {
+		rangeStart, rangeEnd := 0, -1
+		var err error
+		if from != "" {
+			if rangeStart, err = parseIndex(from); err != nil {
+				return segment{}, 0, fmt.Errorf("invalid array range %q at offset %d", content, start)
+			}
+		}
+		if to != "" {
+			if rangeEnd, err = parseIndex(to); err != nil {
+				return segment{}, 0, fmt.Errorf("invalid array range %q at offset %d", content, start)
+			}
+		}
+		if rangeStart == 0 && rangeEnd < 0 {
+			return segment{kind: anyIndexSegment}, next, nil
+		}
+		return segment{kind: rangeSegment, index: rangeStart, end: rangeEnd}, next, nil
+	}
+	if content == lastIndex {
+		return segment{kind: indexSegment, index: -1}, next, nil
+	}
+	// unlike the bounds of ranges, indexes may count from the end of the array
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return segment{}, 0, fmt.Errorf("invalid array index %q at offset %d", content, start)
+	}
+	return segment{kind: indexSegment, index: index}, next, nil
+}
+
+// parseIndex parses a non-negative array index.
+func parseIndex(s string) (int, error) {
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 {
+		return 0, fmt.Errorf("negative index %d", index)
+	}
+	return index, nil
+}
+
+// pathPattern is a compiled mask path that may contain wildcards.
+type pathPattern struct {
+	path string
+	// segments is nil when the path is invalid.
+	segments []segment
+	// keys is set when the path masks the keys it matches instead of their values.
+	keys bool
+	// exclude is set when the path excludes the nodes it matches, see excludePrefix.
+	exclude bool
+	// re is set when the path is a regular expression, see WithRegexPaths,
+	// matched against paths with their array indexes collapsed to arrayToken.
+	re         *regexp.Regexp
+	arrayToken string
+}
+
+// compilePattern compiles a mask path into a pathPattern, invalid paths never matching.
+func compilePattern(path string) pathPattern {
+	pattern, err := parsePattern(path)
+	if err != nil {
+		return pathPattern{path: path, exclude: strings.HasPrefix(path, excludePrefix)}
+	}
+	return pattern
+}
+
+// parsePattern compiles a mask path into a pathPattern, returning an error if the path is invalid.
+func parsePattern(path string) (pathPattern, error) {
+	exclude := strings.HasPrefix(path, excludePrefix)
+	keys := strings.HasSuffix(path, keySuffix)
+	if exclude && keys {
+		return pathPattern{}, fmt.Errorf("%s prefix can't be used with the %s suffix", excludePrefix, keySuffix)
+	}
+	segments, err := parsePath(strings.TrimSuffix(strings.TrimPrefix(path, excludePrefix), keySuffix))
+	if err != nil {
+		return pathPattern{}, err
+	}
+	segments = withRoot(segments)
+	if keys && (len(segments) == 0 || segments[len(segments)-1].kind == rootSegment) {
+		return pathPattern{}, fmt.Errorf("%s suffix must follow a key", keySuffix)
+	}
+	return pathPattern{path: path, segments: segments, keys: keys, exclude: exclude}, nil
+}
+
+// isExact reports whether the pattern matches the paths of a single shape,
+// the only varying part being array indexes matched by [],
+// so it can be looked up directly.
+func (p pathPattern) isExact() bool {
+	for _, s := range p.segments {
+		if s.kind != rootSegment && s.kind != keySegment && s.kind != anyIndexSegment {
+			return false
+		}
+	}
+	return true
+}
+
+// hasWildcards reports whether the pattern matches keys or indexes other than a single one,
+// "[]" aside, e.g. with a glob, a range, "*" or "**".
+func (p pathPattern) hasWildcards() bool {
+	for _, s := range p.segments {
+		switch s.kind {
+		case globSegment, rangeSegment, wildcardSegment, descendantSegment:
+			return true
+		}
+	}
+	return false
+}
+
+// specificity scores how specific the pattern is, to pick the most specific of several
+// patterns matching the same node: each key or index counts for 4, each glob or range for 2,
+// each "*" or "[]" for 1 and "**" for nothing.
+func (p pathPattern) specificity() int {
+	score := 0
+	for _, s := range p.segments {
+		switch s.kind {
+		case keySegment, indexSegment:
+			score += 4
+		case globSegment, rangeSegment:
+			score += 2
+		case wildcardSegment, anyIndexSegment:
+			score++
+		}
+	}
+	return score
+}
+
+// match checks if the path matches the pattern.
+func (p pathPattern) match(path nodePath) bool {
+	if p.re != nil {
+		return p.re.MatchString(formatSegments(path, p.arrayToken))
+	}
+	return p.segments != nil && matchSegments(p.segments, path)
+}
+
+// matchPrefix checks if the path matches the pattern or one of its prefixes,
+// meaning the pattern can match the path or one of its descendants.
+// Regular expressions can't be matched partially, so they only match the path itself.
+func (p pathPattern) matchPrefix(path nodePath) bool {
+	if p.re != nil {
+		return p.match(path)
+	}
+	return p.segments != nil && matchSegmentsPrefix(p.segments, path)
+}
+
+// indexesFromEnd checks if the elements of the array at path may be matched by a negative index
+// of the pattern, which needs the length of the array.
+func (p pathPattern) indexesFromEnd(path nodePath) bool {
+	for i, s := range p.segments {
+		if s.kind == indexSegment && s.index < 0 && matchSegments(p.segments[:i], path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments checks if the path segments match the pattern segments.
+// A "*" pattern segment matches any single key or array index,
+// a "[]" pattern segment matches any single array index and
+// a "**" pattern segment matches any number of segments, including none.
+func matchSegments(pattern, segments []segment) bool {
+	for len(pattern) > 0 {
+		p := pattern[0]
+		if p.kind == descendantSegment {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 || !p.matches(segments[0]) {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// matchSegmentsPrefix checks if the path segments match a prefix of the pattern segments.
+func matchSegmentsPrefix(pattern, segments []segment) bool {
+	for len(segments) > 0 {
+		if len(pattern) == 0 {
+			return false
+		}
+		p := pattern[0]
+		if p.kind == descendantSegment {
+			return true
+		}
+		if !p.matches(segments[0]) {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return true
+}
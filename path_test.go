@@ -0,0 +1,414 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathTrie_matches(t *testing.T) {
+
+	testTable := []struct {
+		name      string
+		path      string
+		maskPaths []string
+		expected  bool
+	}{
+		{
+			name:      "mask by path",
+			path:      "someField.subField",
+			maskPaths: []string{"someField.subField"},
+			expected:  true,
+		},
+		{
+			name:      "mask by path with index",
+			path:      "someField[2].subField",
+			maskPaths: []string{"someField[].subField"},
+			expected:  true,
+		},
+		{
+			name:      "mask by path with nested indexes",
+			path:      "someField[1][2].subField",
+			maskPaths: []string{"someField[][].subField"},
+			expected:  true,
+		},
+		{
+			name:      "nested indexes not matching a single index",
+			path:      "someField[1][2]",
+			maskPaths: []string{"someField[]"},
+			expected:  false,
+		},
+		{
+			name:      "prefix of a path not matching",
+			path:      "someField",
+			maskPaths: []string{"someField.subField"},
+			expected:  false,
+		},
+		{
+			name:      "not matching",
+			path:      "someField.subField",
+			maskPaths: []string{"test"},
+			expected:  false,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			trie := &pathTrie{}
+			for _, path := range tt.maskPaths {
+				trie.insert(parseNodePath(t, path))
+			}
+			ok := trie.matches(parseNodePath(t, tt.path))
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+}
+
+// parseNodePath parses a concrete path, e.g. "$.users[2].name", for tests.
+func parseNodePath(t *testing.T, path string) nodePath {
+	segments, err := parsePath(path)
+	if err != nil {
+		t.Fatalf("invalid path %q: %v", path, err)
+	}
+	return segments
+}
+
+func TestParsePath(t *testing.T) {
+	testTable := []struct {
+		name        string
+		path        string
+		expected    []segment
+		expectedErr string
+	}{
+		{
+			name:     "root",
+			path:     "$",
+			expected: []segment{{kind: rootSegment}},
+		},
+		{
+			name:     "nested keys",
+			path:     "$.users.u1.ssn",
+			expected: []segment{{kind: rootSegment}, {kind: keySegment, key: "users"}, {kind: keySegment, key: "u1"}, {kind: keySegment, key: "ssn"}},
+		},
+		{
+			name:     "array indexes",
+			path:     "$[1].list[].name",
+			expected: []segment{{kind: rootSegment}, {kind: indexSegment, index: 1}, {kind: keySegment, key: "list"}, {kind: anyIndexSegment}, {kind: keySegment, key: "name"}},
+		},
+		{
+			name:     "array ranges",
+			path:     "$[0:2][3:][:4][:]",
+			expected: []segment{{kind: rootSegment}, {kind: rangeSegment, index: 0, end: 2}, {kind: rangeSegment, index: 3, end: -1}, {kind: rangeSegment, index: 0, end: 4}, {kind: anyIndexSegment}},
+		},
+		{
+			name:     "indexes from the end",
+			path:     "$.a[-1][last][-3]",
+			expected: []segment{{kind: rootSegment}, {kind: keySegment, key: "a"}, {kind: indexSegment, index: -1}, {kind: indexSegment, index: -1}, {kind: indexSegment, index: -3}},
+		},
+		{
+			name:        "negative range bound",
+			path:        "$.a[-2:]",
+			expectedErr: `invalid array range "-2:" at offset 3`,
+		},
+		{
+			name:        "invalid array index",
+			path:        "$.a[x]",
+			expectedErr: `invalid array index "x" at offset 3`,
+		},
+		{
+			name:        "invalid array range",
+			path:        "$.a[1:x]",
+			expectedErr: `invalid array range "1:x" at offset 3`,
+		},
+		{
+			name:     "wildcards",
+			path:     "$.*..name.**",
+			expected: []segment{{kind: rootSegment}, {kind: wildcardSegment}, {kind: descendantSegment}, {kind: keySegment, key: "name"}, {kind: descendantSegment}},
+		},
+		{
+			name:     "bracket-quoted keys",
+			path:     `$['user.email'].["a[]"].['it\'s']`,
+			expected: []segment{{kind: rootSegment}, {kind: keySegment, key: "user.email"}, {kind: keySegment, key: "a[]"}, {kind: keySegment, key: "it's"}},
+		},
+		{
+			name:     "bracket-quoted wildcard is a key",
+			path:     "$['*']",
+			expected: []segment{{kind: rootSegment}, {kind: keySegment, key: "*"}},
+		},
+		{
+			name:     "glob keys",
+			path:     "$.secret*.?id",
+			expected: []segment{{kind: rootSegment}, {kind: globSegment, key: "secret*"}, {kind: globSegment, key: "?id"}},
+		},
+		{
+			name:     "escaped glob characters",
+			path:     `$.a\*b.c\?`,
+			expected: []segment{{kind: rootSegment}, {kind: keySegment, key: "a*b"}, {kind: keySegment, key: "c?"}},
+		},
+		{
+			name:     "without root",
+			path:     "someField.subField",
+			expected: []segment{{kind: keySegment, key: "someField"}, {kind: keySegment, key: "subField"}},
+		},
+		{
+			name:        "empty key",
+			path:        "$.a.",
+			expectedErr: "empty key at offset 4",
+		},
+		{
+			name:        "unterminated bracket",
+			path:        "$.a[1",
+			expectedErr: "unterminated bracket at offset 3",
+		},
+		{
+			name:        "unterminated quoted key",
+			path:        "$['a",
+			expectedErr: "unterminated quoted key at offset 1",
+		},
+		{
+			name:        "missing dot",
+			path:        "$['a']b",
+			expectedErr: "expected . or [ at offset 6",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := parsePath(tt.path)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, segments)
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	testTable := []struct {
+		pattern  string
+		key      string
+		expected bool
+	}{
+		{pattern: "secret*", key: "secretKey", expected: true},
+		{pattern: "secret*", key: "secret", expected: true},
+		{pattern: "secret*", key: "mySecret", expected: false},
+		{pattern: "*Token", key: "accessToken", expected: true},
+		{pattern: "*Token", key: "accessTokens", expected: false},
+		{pattern: "*_*_id", key: "a_b_c_id", expected: true},
+		{pattern: "pin?", key: "pin1", expected: true},
+		{pattern: "pin?", key: "pin", expected: false},
+		{pattern: "pin?", key: "pin12", expected: false},
+		{pattern: "??", key: "éü", expected: true},
+		{pattern: `a\*`, key: "a*", expected: true},
+		{pattern: `a\*`, key: "ab", expected: false},
+		{pattern: `\?*`, key: "?x", expected: true},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.pattern+" "+tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchGlob(tt.pattern, tt.key))
+		})
+	}
+}
+
+func TestNodePath_String(t *testing.T) {
+	path := rootPath().key("users").index(2).key("first.name").key("it's").key("*")
+	assert.Equal(t, `$.users[2]['first.name']['it\'s']['*']`, path.String())
+	assert.Equal(t, path, parseNodePath(t, path.String()))
+}
+
+func TestPathSet_matches(t *testing.T) {
+	testTable := []struct {
+		name      string
+		maskPaths []string
+		path      string
+		expected  bool
+	}{
+		{
+			name:      "exact path",
+			maskPaths: []string{"$.users.u1.ssn"},
+			path:      "$.users.u1.ssn",
+			expected:  true,
+		},
+		{
+			name:      "wildcard map key",
+			maskPaths: []string{"$.users.*.ssn"},
+			path:      "$.users.u2.ssn",
+			expected:  true,
+		},
+		{
+			name:      "wildcard array element",
+			maskPaths: []string{"$.users.*.ssn"},
+			path:      "$.users[3].ssn",
+			expected:  true,
+		},
+		{
+			name:      "wildcard matches a single level only",
+			maskPaths: []string{"$.users.*.ssn"},
+			path:      "$.users.u1.details.ssn",
+			expected:  false,
+		},
+		{
+			name:      "wildcard with array syntax",
+			maskPaths: []string{"$.*.tags[]"},
+			path:      "$.u1.tags[0]",
+			expected:  true,
+		},
+		{
+			name:      "recursive descent shorthand at top level",
+			maskPaths: []string{"$..password"},
+			path:      "$.password",
+			expected:  true,
+		},
+		{
+			name:      "recursive descent shorthand nested",
+			maskPaths: []string{"$..password"},
+			path:      "$.a.b[2].c.password",
+			expected:  true,
+		},
+		{
+			name:      "recursive descent segment",
+			maskPaths: []string{"$.**.password"},
+			path:      "$.a.password",
+			expected:  true,
+		},
+		{
+			name:      "recursive descent in the middle",
+			maskPaths: []string{"$.users.**.tokens[]"},
+			path:      "$.users[1].auth.tokens[4]",
+			expected:  true,
+		},
+		{
+			name:      "recursive descent requires the final key",
+			maskPaths: []string{"$..password"},
+			path:      "$.a.passwords",
+			expected:  false,
+		},
+		{
+			name:      "bracket-quoted key with dots",
+			maskPaths: []string{"$.['user.email']"},
+			path:      "$['user.email']",
+			expected:  true,
+		},
+		{
+			name:      "bracket-quoted key does not match nested keys",
+			maskPaths: []string{"$['user.email']"},
+			path:      "$.user.email",
+			expected:  false,
+		},
+		{
+			name:      "dotted path does not match key with dots",
+			maskPaths: []string{"$.user.email"},
+			path:      "$['user.email']",
+			expected:  false,
+		},
+		{
+			name:      "bracket-quoted key with array syntax",
+			maskPaths: []string{"$['a.b'][]['c[0]']"},
+			path:      "$['a.b'][3]['c[0]']",
+			expected:  true,
+		},
+		{
+			name:      "explicit index",
+			maskPaths: []string{"$.items[3].token"},
+			path:      "$.items[3].token",
+			expected:  true,
+		},
+		{
+			name:      "explicit index not matching",
+			maskPaths: []string{"$.items[3].token"},
+			path:      "$.items[2].token",
+			expected:  false,
+		},
+		{
+			name:      "range start is inclusive",
+			maskPaths: []string{"$.items[1:3]"},
+			path:      "$.items[1]",
+			expected:  true,
+		},
+		{
+			name:      "range end is exclusive",
+			maskPaths: []string{"$.items[1:3]"},
+			path:      "$.items[3]",
+			expected:  false,
+		},
+		{
+			name:      "open ended range",
+			maskPaths: []string{"$.items[2:]"},
+			path:      "$.items[100]",
+			expected:  true,
+		},
+		{
+			name:      "invalid path never matches",
+			maskPaths: []string{"$.a["},
+			path:      "$.a",
+			expected:  false,
+		},
+		{
+			name:      "array syntax does not match map keys",
+			maskPaths: []string{"$.users[].ssn"},
+			path:      "$.users.u1.ssn",
+			expected:  false,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newPathSet(tt.maskPaths).matches(parseNodePath(t, tt.path)))
+		})
+	}
+}
+
+func TestPathPattern_matchPrefix(t *testing.T) {
+	testTable := []struct {
+		name     string
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{
+			name:     "full match",
+			pattern:  "$.a.b",
+			path:     "$.a.b",
+			expected: true,
+		},
+		{
+			name:     "ancestor",
+			pattern:  "$.a.b",
+			path:     "$.a",
+			expected: true,
+		},
+		{
+			name:     "descendant",
+			pattern:  "$.a",
+			path:     "$.a.b",
+			expected: false,
+		},
+		{
+			name:     "array element",
+			pattern:  "$.items[].card",
+			path:     "$.items[0]",
+			expected: true,
+		},
+		{
+			name:     "recursive descent",
+			pattern:  "$..password",
+			path:     "$.a.b",
+			expected: true,
+		},
+		{
+			name:     "different key",
+			pattern:  "$.a.b",
+			path:     "$.c",
+			expected: false,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compilePattern(tt.pattern).matchPrefix(parseNodePath(t, tt.path)))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package masker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pointerUnescaper unescapes the reference tokens of JSON Pointers, "~1" standing for "/" and "~0" for "~".
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// WithJSONPointerPaths makes the mask paths JSON Pointers (RFC 6901), e.g. "/users/0/ssn",
+// in place of the path syntax, the paths of WithKeepOnly and WithNestedJSON included.
+// "~1" and "~0" in a reference token stand for "/" and "~", so "/a~1b" is the key "a/b".
+// Tokens that are array indexes, such as "0" or "12", only match array elements,
+// and the array token, "[]" unless WithArrayToken is used, matches every index, e.g. "/users/[]/ssn".
+// The empty pointer "" is the whole document, and pointers prefixed with "!" are exclusions.
+// Every mask call returns an error wrapping ErrInvalidPath if one of the pointers is invalid.
+// It takes precedence over WithRegexPaths, and paths compiled with CompilePaths keep the path syntax.
+func WithJSONPointerPaths() option {
+	return func(m *masker) {
+		m.isJSONPointer = true
+	}
+}
+
+// newPointerPathSet builds a pathSet from maskPaths written as JSON Pointers,
+// keeping the pointers as they are written for the errors of WithStrictPaths.
+func newPointerPathSet(maskPaths []string, arrayToken string) (pathSet, error) {
+	patterns := make([]pathPattern, 0, len(maskPaths))
+	for _, path := range maskPaths {
+		exclude := strings.HasPrefix(path, excludePrefix)
+		segments, err := parsePointer(strings.TrimPrefix(path, excludePrefix), arrayToken)
+		if err != nil {
+			return pathSet{}, withKind(ErrInvalidPath, fmt.Errorf("invalid mask path JSON pointer %q: %w", path, err))
+		}
+		patterns = append(patterns, pathPattern{path: path, segments: segments, exclude: exclude})
+	}
+	return newPatternSet(patterns), nil
+}
+
+// parsePointer parses a JSON Pointer into segments, see WithJSONPointerPaths.
+func parsePointer(pointer, arrayToken string) ([]segment, error) {
+	segments := []segment{{kind: rootSegment}}
+	if pointer == "" {
+		return segments, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("must be empty or start with /")
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		for i := 0; i < len(token); i++ {
+			if token[i] != '~' {
+				continue
+			}
+			if i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1') {
+				return nil, fmt.Errorf("invalid escape in reference token %q, ~ must be followed by 0 or 1", token)
+			}
+			i++
+		}
+		switch {
+		case token == arrayToken:
+			segments = append(segments, segment{kind: anyIndexSegment})
+		case isPointerIndex(token):
+			index, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q: %w", token, err)
+			}
+			segments = append(segments, segment{kind: indexSegment, index: index})
+		default:
+			segments = append(segments, segment{kind: keySegment, key: pointerUnescaper.Replace(token)})
+		}
+	}
+	return segments, nil
+}
+
+// isPointerIndex checks if the reference token is an array index,
+// digits without a leading zero unless the index is 0.
+func isPointerIndex(token string) bool {
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return false
+	}
+	return strings.Trim(token, "0123456789") == ""
+}
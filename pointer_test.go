@@ -0,0 +1,164 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePointer(t *testing.T) {
+	testTable := []struct {
+		name        string
+		pointer     string
+		arrayToken  string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "Test with root pointer",
+			pointer:  "",
+			expected: "$",
+		},
+		{
+			name:     "Test with keys and index",
+			pointer:  "/users/0/ssn",
+			expected: "$.users[0].ssn",
+		},
+		{
+			name:     "Test with escaped slash and tilde",
+			pointer:  "/a~1b/c~0d/~01",
+			expected: "$.a/b.c~d.~1",
+		},
+		{
+			name:     "Test with array token",
+			pointer:  "/users/[]/ssn",
+			expected: "$.users[].ssn",
+		},
+		{
+			name:       "Test with custom array token",
+			pointer:    "/users/[*]/ssn",
+			arrayToken: "[*]",
+			expected:   "$.users[].ssn",
+		},
+		{
+			name:     "Test with leading zero key",
+			pointer:  "/codes/01",
+			expected: "$.codes.01",
+		},
+		{
+			name:     "Test with empty key",
+			pointer:  "/",
+			expected: "$['']",
+		},
+		{
+			name:        "Test without leading slash",
+			pointer:     "users/0",
+			expectedErr: "must be empty or start with /",
+		},
+		{
+			name:        "Test with invalid escape",
+			pointer:     "/a~2b",
+			expectedErr: `invalid escape in reference token "a~2b", ~ must be followed by 0 or 1`,
+		},
+		{
+			name:        "Test with trailing tilde",
+			pointer:     "/a~",
+			expectedErr: `invalid escape in reference token "a~", ~ must be followed by 0 or 1`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			arrayToken := tt.arrayToken
+			if arrayToken == "" {
+				arrayToken = DefaultArrayToken
+			}
+			segments, err := parsePointer(tt.pointer, arrayToken)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, formatSegments(segments, ""))
+		})
+	}
+}
+
+func TestWithJSONPointerPaths(t *testing.T) {
+	testTable := []struct {
+		name        string
+		input       string
+		maskPaths   []string
+		opts        []option
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:      "Test with array index",
+			input:     `{"users":[{"ssn":"1"},{"ssn":"2"}]}`,
+			maskPaths: []string{"/users/0/ssn"},
+			expected:  `{"users":[{"ssn":"[REDACTED]"},{"ssn":"2"}]}`,
+		},
+		{
+			name:      "Test with every array index",
+			input:     `{"users":[{"ssn":"1"},{"ssn":"2"}]}`,
+			maskPaths: []string{"/users/[]/ssn"},
+			expected:  `{"users":[{"ssn":"[REDACTED]"},{"ssn":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with escaped keys",
+			input:     `{"a/b":1,"c~d":2,"e":3}`,
+			maskPaths: []string{"/a~1b", "/c~0d"},
+			expected:  `{"a/b":"[REDACTED]","c~d":"[REDACTED]","e":3}`,
+		},
+		{
+			name:      "Test with index token on an object",
+			input:     `{"codes":{"0":"a"},"list":["b"]}`,
+			maskPaths: []string{"/codes/0", "/list/0"},
+			expected:  `{"codes":{"0":"a"},"list":["[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with root pointer",
+			input:     `{"a":1}`,
+			maskPaths: []string{""},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:      "Test with exclusion",
+			input:     `{"user":{"id":1,"ssn":"2"}}`,
+			maskPaths: []string{"/user", "!/user/id"},
+			expected:  `{"user":{"id":1,"ssn":"[REDACTED]"}}`,
+		},
+		{
+			name:        "Test with strict paths",
+			input:       `{"a":1}`,
+			maskPaths:   []string{"/a", "/b~1c"},
+			opts:        []option{WithStrictPaths()},
+			expectedErr: "mask paths matched nothing: /b~1c",
+		},
+		{
+			name:        "Test with invalid pointer",
+			input:       `{"a":1}`,
+			maskPaths:   []string{"a"},
+			expectedErr: `invalid mask path JSON pointer "a": must be empty or start with /`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]option{WithJSONPointerPaths()}, tt.opts...)
+			masked, err := NewMasker(tt.maskPaths, opts...).Mask(tt.input)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+		})
+	}
+
+	t.Run("Test with invalid pointer passed to a mask call", func(t *testing.T) {
+		_, err := NewMasker(nil, WithJSONPointerPaths()).MaskWithPaths(`{"a":1}`, []string{"/a~"})
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+}
@@ -0,0 +1,148 @@
+package masker
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// WithProtectedKeys makes the values under keys never be masked, in the objects at any depth,
+// whatever the other rules, e.g. the ids of records masked by a broad path:
+//
+//	NewMasker([]string{"$.users[].*"}, WithProtectedKeys("id"))
+//
+// masks every field of the users but their id. Protected values are kept as they are with
+// everything below them. The matched objects and arrays holding a protected key at any depth
+// are masked leaf by leaf instead of as a whole, e.g. {"user":{"id":1,"name":"a"}} masked by "$.user"
+// keeps the id and masks the name, while the matched ones without any are still masked as a whole.
+// Unlike exclusions, e.g. "!$..id", the protection also applies
+// to the options masking or transforming values whatever the mask paths, e.g. WithValueMatcher,
+// WithKeepOnly, WithConditionalMask or WithNumericBucket, and the protected keys aren't masked by key paths.
+// It applies to JSON documents and to the map keys and struct fields of the values passed to MaskValue.
+// Keys are compared normalized if WithFieldNameNormalizer is used.
+func WithProtectedKeys(keys ...string) option {
+	return func(m *masker) {
+		if m.protectedKeys == nil {
+			m.protectedKeys = make(map[string]bool, len(keys))
+		}
+		for _, key := range keys {
+			m.protectedKeys[key] = true
+		}
+	}
+}
+
+// isProtectedKey checks if the key is protected, see WithProtectedKeys.
+func (m *masker) isProtectedKey(key string) bool {
+	if len(m.protectedKeys) == 0 {
+		return false
+	}
+	if m.normalizer == nil {
+		return m.protectedKeys[key]
+	}
+	key = m.normalizer(key)
+	for protected := range m.protectedKeys {
+		if m.normalizer(protected) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// isProtected checks if the node at path is the value of a protected key, see WithProtectedKeys.
+func (m *masker) isProtected(path nodePath) bool {
+	last := path[len(path)-1]
+	return last.kind == keySegment && m.isProtectedKey(last.key)
+}
+
+// holdsProtected checks if a protected key is below the value, in the objects, maps or structs at any depth,
+// in which case it isn't masked as a whole when it is matched, see WithProtectedKeys.
+func (m *masker) holdsProtected(value reflect.Value) bool {
+	if len(m.protectedKeys) == 0 {
+		return false
+	}
+	return m.holdsProtectedIn(value, make(map[visit]bool))
+}
+
+// holdsProtectedIn checks if a protected key is below the value, visited holding the pointers,
+// maps and slices already searched, so cyclic and shared values are searched once.
+func (m *masker) holdsProtectedIn(value reflect.Value, visited map[visit]bool) bool {
+	for value.IsValid() && (value.Kind() == reflect.Interface || (value.Kind() == reflect.Ptr && value.Type() != objectType)) {
+		if value.IsNil() {
+			return false
+		}
+		if value.Kind() == reflect.Ptr {
+			v := visit{typ: value.Type(), ptr: value.Pointer()}
+			if visited[v] {
+				return false
+			}
+			visited[v] = true
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() || isOpaque(value.Type()) {
+		return false
+	}
+	switch {
+	case value.Type() == objectType:
+		obj := value.Interface().(*object)
+		for i, key := range obj.keys {
+			if m.isProtectedKey(key) || m.holdsProtectedIn(reflect.ValueOf(obj.values[i]), visited) {
+				return true
+			}
+		}
+		return false
+	case value.Type() == rawMessageType:
+		var decoded any
+		if err := json.Unmarshal(value.Interface().(json.RawMessage), &decoded); err != nil {
+			return false
+		}
+		return m.holdsProtectedIn(reflect.ValueOf(decoded), visited)
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.IsExported() && (m.isProtectedKey(field.Name) || m.holdsProtectedIn(value.Field(i), visited)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		if value.IsNil() || visited[visit{typ: value.Type(), ptr: value.Pointer()}] {
+			return false
+		}
+		visited[visit{typ: value.Type(), ptr: value.Pointer()}] = true
+		iter := value.MapRange()
+		for iter.Next() {
+			if m.isProtectedKey(mapKey(iter.Key())) || m.holdsProtectedIn(iter.Value(), visited) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		if value.Kind() == reflect.Slice {
+			v := visit{typ: value.Type(), ptr: value.Pointer(), len: value.Len()}
+			if value.IsNil() || visited[v] {
+				return false
+			}
+			visited[v] = true
+		}
+		for i := 0; i < value.Len(); i++ {
+			if m.holdsProtectedIn(value.Index(i), visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copyProtected writes the next value of the decoder, which is protected, without masking it.
+func (s *streamMasker) copyProtected(path nodePath) error {
+	var raw json.RawMessage
+	if err := s.dec.Decode(&raw); err != nil {
+		return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	s.masker.log("Keeping protected value", logActionKeep, path)
+	return s.write(raw)
+}
@@ -0,0 +1,159 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProtectedKeys(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with wildcard rule",
+			input:     `{"users":[{"id":1,"name":"John","ssn":"123"},{"id":2,"name":"Jane"}]}`,
+			maskPaths: []string{"$.users[].*"},
+			expected:  `{"users":[{"id":1,"name":"[REDACTED]","ssn":"[REDACTED]"},{"id":2,"name":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with object matched as a whole",
+			input:     `{"user":{"id":"u1","profile":{"id":"p1","email":"a@b.c"}}}`,
+			maskPaths: []string{"$.user"},
+			expected:  `{"user":{"id":"u1","profile":{"id":"p1","email":"[REDACTED]"}}}`,
+		},
+		{
+			name:      "Test with object matched as a whole without protected keys",
+			input:     `{"user":{"name":"a"},"empty":{},"list":[{"name":"b"}]}`,
+			maskPaths: []string{"$.user", "$.empty", "$.list"},
+			expected:  `{"user":"[REDACTED]","empty":"[REDACTED]","list":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with nested object without protected keys",
+			input:     `{"user":{"id":"u1","profile":{"email":"a@b.c"},"cards":[{"id":"c1","number":"4111"}]}}`,
+			maskPaths: []string{"$.user"},
+			expected:  `{"user":{"id":"u1","profile":"[REDACTED]","cards":[{"id":"c1","number":"[REDACTED]"}]}}`,
+		},
+		{
+			name:  "Test with slice predicate",
+			input: `{"orders":[{"id":1,"total":5},{"id":2,"total":500}]}`,
+			opts: []option{WithSlicePredicate("$.orders", func(elem any) bool {
+				return elem.(map[string]any)["total"] == json.Number("5")
+			})},
+			expected: `{"orders":[{"id":1,"total":5},{"id":2,"total":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with root matched",
+			input:     `{"id":1,"token":"abc"}`,
+			maskPaths: []string{"$"},
+			expected:  `{"id":1,"token":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with protected object",
+			input:     `{"id":{"value":"x","kind":"uuid"},"card":"4111111111111111"}`,
+			maskPaths: []string{"$..value", "$..card"},
+			expected:  `{"id":{"value":"x","kind":"uuid"},"card":"[REDACTED]"}`,
+		},
+		{
+			name:     "Test with value matcher",
+			input:    `{"id":"4111111111111111","card":"4111111111111111"}`,
+			opts:     []option{WithValueMatcher(IsCreditCard)},
+			expected: `{"id":"4111111111111111","card":"[REDACTED]"}`,
+		},
+		{
+			name:     "Test with keep only",
+			input:    `{"id":1,"name":"John","nested":{"id":2,"secret":"s"}}`,
+			opts:     []option{WithKeepOnly([]string{"$.name"})},
+			expected: `{"id":1,"name":"John","nested":{"id":2,"secret":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with key paths",
+			input:     `{"ids":{"id":1,"acc":2}}`,
+			maskPaths: []string{"$.ids.*~"},
+			expected:  `{"ids":{"id":1,"[REDACTED]":2}}`,
+		},
+		{
+			name:      "Test with dropped fields",
+			input:     `{"id":1,"name":"John"}`,
+			maskPaths: []string{"$.*"},
+			opts:      []option{WithDropMaskedFields()},
+			expected:  `{"id":1}`,
+		},
+		{
+			name:      "Test with conditional mask",
+			input:     `{"payment":{"type":"card","id":"p1","number":"4111"}}`,
+			maskPaths: []string{},
+			opts: []option{WithConditionalMask("$.payment.*", func(node map[string]any) bool {
+				return node["type"] == "card"
+			})},
+			expected: `{"payment":{"type":"[REDACTED]","id":"p1","number":"[REDACTED]"}}`,
+		},
+		{
+			name:      "Test with field name normalizer",
+			input:     `{"userId":1,"user_name":"John"}`,
+			maskPaths: []string{"$.*"},
+			opts:      []option{WithFieldNameNormalizer(SnakeCaseFieldName)},
+			expected:  `{"userId":1,"user_name":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			protected := "id"
+			if strings.Contains(tt.name, "normalizer") {
+				protected = "UserID"
+			}
+			opts := append([]option{WithProtectedKeys(protected)}, tt.opts...)
+			masker := NewMasker(tt.maskPaths, opts...)
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		type user struct {
+			ID   int
+			Name string
+		}
+		masked, err := NewMasker([]string{"$.*"}, WithProtectedKeys("ID")).MaskValue(user{ID: 7, Name: "John"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, user{ID: 7, Name: "[REDACTED]"}, masked)
+	})
+
+	t.Run("Test with Go map", func(t *testing.T) {
+		input := map[string]any{"user": map[string]any{"id": 7, "name": "John"}, "card": map[string]any{"number": "4111"}}
+		masked, err := NewMasker([]string{"$.user", "$.card"}, WithProtectedKeys("id")).MaskValue(input, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"user": map[string]any{"id": 7, "name": "[REDACTED]"}, "card": "[REDACTED]"}, masked)
+	})
+
+	t.Run("Test with cyclic Go value", func(t *testing.T) {
+		type node struct {
+			Next *node
+			Name string
+		}
+		cyclic := &node{Name: "a"}
+		cyclic.Next = cyclic
+		masked, err := NewMasker([]string{"$"}, WithProtectedKeys("id")).MaskValue(cyclic, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "[REDACTED]", masked)
+	})
+
+	t.Run("Test with mask paths of the call", func(t *testing.T) {
+		masked, err := NewMasker(nil, WithProtectedKeys("id")).MaskWithPaths(`{"a":{"id":1,"b":2}}`, []string{"$.a"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":{"id":1,"b":"[REDACTED]"}}`, masked)
+	})
+}
@@ -0,0 +1,77 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// WithRawPassthrough makes Mask and the other calls masking a JSON document walk it token by token
+// like MaskReader does, copying the subtrees in which nothing may be masked verbatim instead of
+// decoding and encoding them. Untouched parts keep their formatting, e.g. their whitespace, number
+// representations and duplicate keys, and large documents in which little is masked are masked faster.
+// Only the branches leading to the nodes that may be masked are decoded, so the masked keys are made unique
+// like MaskReader does, and WithConcurrency doesn't apply. As for MaskReader, the limit of WithMaxDepth and
+// WithDuplicateKeys don't apply inside the subtrees copied verbatim, which are still validated.
+// With WithIndent, the copied subtrees keep their formatting rather than being indented.
+func WithRawPassthrough() option {
+	return func(m *masker) {
+		m.isRawPassthrough = true
+	}
+}
+
+// maskRaw masks the input like mask does, walking it like MaskReader does, see WithRawPassthrough.
+func (m *masker) maskRaw(input []byte, state *maskState) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.maskStream(bytes.NewReader(input), &buf, state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// passesThrough checks if the value at path can be copied verbatim, nothing at or below it being masked.
+func (s *streamMasker) passesThrough(path nodePath) bool {
+	return s.masker.isRawPassthrough && (!s.masker.mayMatchBelow(s.state, path) || s.state.outsideSubtree(path, false))
+}
+
+// copyRaw copies the next value of the decoder to the output as it is in the input.
+func (s *streamMasker) copyRaw(path nodePath) error {
+	var raw json.RawMessage
+	if err := s.dec.Decode(&raw); err != nil {
+		return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	s.masker.log("Copying value", logActionKeep, path)
+	if _, err := s.out.Write(s.masker.encodeOutput(raw)); err != nil {
+		return fmt.Errorf("failed to write masked object: %w", err)
+	}
+	return nil
+}
+
+// transformsBelow checks if a string or number at or below path may be transformed without being
+// matched by a mask path, see WithNestedJSON, WithBase64JSON, WithCSVField, WithRegexValueMask
+// and WithNumericBucket.
+func (m *masker) transformsBelow(path nodePath) bool {
+	if (m.nested != nil && len(m.nested.covering(path)) > 0) || (m.base64 != nil && len(m.base64.covering(path)) > 0) {
+		return true
+	}
+	if len(m.csvFields) == 0 && len(m.regexValues) == 0 && len(m.buckets) == 0 {
+		return false
+	}
+	path = normalizeKeys(path, m.normalizer)
+	for _, field := range m.csvFields {
+		if field.pattern.matchPrefix(path) {
+			return true
+		}
+	}
+	for _, r := range m.regexValues {
+		if r.pattern.matchPrefix(path) {
+			return true
+		}
+	}
+	for _, b := range m.buckets {
+		if b.pattern.matchPrefix(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package masker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRawPassthrough(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with untouched subtrees",
+			input:     `{"user": {"ssn": "123", "name": "John"}, "meta": {"ids": [1.50, 2e3],  "note": "a&b"}}`,
+			maskPaths: []string{"$.user.ssn"},
+			expected:  `{"user":{"ssn":"[REDACTED]","name":"John"},"meta":{"ids": [1.50, 2e3],  "note": "a&b"}}`,
+		},
+		{
+			name:      "Test with duplicate keys in untouched subtree",
+			input:     `{"a":{"x":1,"x":2},"b":"secret"}`,
+			maskPaths: []string{"$.b"},
+			expected:  `{"a":{"x":1,"x":2},"b":"[REDACTED]"}`,
+		},
+		{
+			name:      "Test with masked subtree",
+			input:     "{\n  \"card\": {\"number\": \"4111\"},\n  \"items\": [ {\"id\": 1}, {\"id\": 2} ]\n}",
+			maskPaths: []string{"$.card", "$.items[1].id"},
+			expected:  `{"card":"[REDACTED]","items":[{"id": 1},{"id":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "Test with nothing masked",
+			input:    ` [1, {"a" : true}] `,
+			expected: `[1, {"a" : true}]`,
+		},
+		{
+			name:      "Test with transformed values",
+			input:     `{"age": 37, "log": {"line": "token=abc"}, "other": { }}`,
+			maskPaths: []string{},
+			opts:      []option{WithNumericBucket("$.age", 10), WithCSVField("$.log.line", []int{0})},
+			expected:  `{"age":30,"log":{"line":"[REDACTED]"},"other":{ }}`,
+		},
+		{
+			name:      "Test with leaves masked by kind",
+			input:     `{"a": {"b": "x", "c": 1}}`,
+			maskPaths: []string{},
+			opts:      []option{WithMaskAllStrings()},
+			expected:  `{"a":{"b":"[REDACTED]","c":1}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]option{WithRawPassthrough()}, tt.opts...)
+			masked, err := NewMasker(tt.maskPaths, opts...).Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+		})
+	}
+
+	t.Run("Test with same output as Mask", func(t *testing.T) {
+		input := largeArray(200)
+		maskPaths := []string{"$[10].ssn", "$[].tags[0]", "$[150]"}
+		expected, err := NewMasker(maskPaths).Mask(input)
+		assert.NoError(t, err)
+		masked, err := NewMasker(maskPaths, WithRawPassthrough()).Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, masked)
+	})
+
+	t.Run("Test with report", func(t *testing.T) {
+		_, paths, err := NewMasker([]string{"$..ssn"}, WithRawPassthrough()).MaskWithReport(`{"a":{"ssn":1},"b":[{"ssn":2}]}`, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.a.ssn", "$.b[0].ssn"}, paths)
+	})
+
+	t.Run("Test with invalid untouched subtree", func(t *testing.T) {
+		_, err := NewMasker([]string{"$.a"}, WithRawPassthrough()).Mask(`{"a":1,"b":{"c":}}`)
+		assert.ErrorIs(t, err, ErrInvalidJSON)
+	})
+
+	t.Run("Test with canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := NewMasker([]string{"$[].ssn"}, WithRawPassthrough()).MaskContext(ctx, largeArray(1000), nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func BenchmarkMask_rawPassthrough(b *testing.B) {
+	records := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		records = append(records, fmt.Sprintf(`{"id":%d,"profile":{"name":"user%d","tags":["a","b","c"],"scores":[1,2,3,4,5]}}`, i, i))
+	}
+	input := `{"ssn":"123","records":[` + strings.Join(records, ",") + `]}`
+	for _, bm := range []struct {
+		name string
+		opts []option
+	}{
+		{name: "decoded"},
+		{name: "passthrough", opts: []option{WithRawPassthrough()}},
+	} {
+		m := NewMasker([]string{"$.ssn"}, bm.opts...)
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				if _, err := m.Mask(input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package masker
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schemaMaskKeyword is the JSON Schema keyword marking sensitive values.
+const schemaMaskKeyword = "x-mask"
+
+// NewMaskerFromSchema creates a masker masking the values marked with "x-mask": true
+// in a JSON Schema, e.g. {"properties":{"ssn":{"type":"string","x-mask":true}}} masks "$.ssn".
+// The schema is walked through properties, additionalProperties and patternProperties
+// (matching any key), items and prefixItems, allOf, anyOf and oneOf, and local $ref
+// references such as "#/$defs/card". It returns an error if the schema isn't valid JSON
+// or references a definition that doesn't exist. The paths of the schema match whatever the path
+// syntax set by opts, e.g. WithJSONPointerPaths or WithRegexPaths.
+func NewMaskerFromSchema(schema []byte, opts ...option) (Masker, error) {
+	var root any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	w := &schemaWalker{root: root, seen: make(map[string]bool), resolving: make(map[string]bool)}
+	if err := w.walk(root, []segment{{kind: rootSegment}}); err != nil {
+		return nil, err
+	}
+	m := NewMasker(nil, opts...).(*masker)
+	if m.err != nil {
+		return m, nil
+	}
+	// the paths of the schema are written in the path syntax, whatever the syntax of the options
+	patterns := make([]pathPattern, 0, len(w.paths))
+	for _, path := range w.paths {
+		patterns = append(patterns, compilePattern(path))
+	}
+	m.maskPaths, m.paths = w.paths, m.paths.with(patterns...)
+	return m, nil
+}
+
+// schemaWalker collects the mask paths of a JSON Schema.
+type schemaWalker struct {
+	root  any
+	paths []string
+	// seen holds the paths already collected.
+	seen map[string]bool
+	// resolving holds the references being walked, to stop at recursive schemas.
+	resolving map[string]bool
+}
+
+// walk collects the mask paths of the schema describing the values at path.
+func (w *schemaWalker) walk(schema any, path []segment) error {
+	node, ok := schema.(map[string]any)
+	if !ok {
+		// boolean schemas have nothing to mask
+		return nil
+	}
+	if mask, _ := node[schemaMaskKeyword].(bool); mask {
+		w.add(path)
+		// the whole value is masked, there is no need to look deeper
+		return nil
+	}
+	if ref, ok := node["$ref"].(string); ok {
+		if err := w.walkRef(ref, path); err != nil {
+			return err
+		}
+	}
+	properties, _ := node["properties"].(map[string]any)
+	for _, key := range sortedKeys(properties) {
+		if err := w.walk(properties[key], appendSegment(path, segment{kind: keySegment, key: key})); err != nil {
+			return err
+		}
+	}
+	if additional, ok := node["additionalProperties"]; ok {
+		if err := w.walk(additional, appendSegment(path, segment{kind: wildcardSegment})); err != nil {
+			return err
+		}
+	}
+	patterns, _ := node["patternProperties"].(map[string]any)
+	for _, pattern := range sortedKeys(patterns) {
+		if err := w.walk(patterns[pattern], appendSegment(path, segment{kind: wildcardSegment})); err != nil {
+			return err
+		}
+	}
+	switch items := node["items"].(type) {
+	case map[string]any:
+		if err := w.walk(items, appendSegment(path, segment{kind: anyIndexSegment})); err != nil {
+			return err
+		}
+	case []any:
+		// tuple validation of older drafts, each item describes a single index
+		if err := w.walkTuple(items, path); err != nil {
+			return err
+		}
+	}
+	if prefixItems, ok := node["prefixItems"].([]any); ok {
+		if err := w.walkTuple(prefixItems, path); err != nil {
+			return err
+		}
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		schemas, _ := node[keyword].([]any)
+		for _, subschema := range schemas {
+			if err := w.walk(subschema, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkTuple collects the mask paths of schemas describing the array elements at their index.
+func (w *schemaWalker) walkTuple(items []any, path []segment) error {
+	for i, item := range items {
+		if err := w.walk(item, appendSegment(path, segment{kind: indexSegment, index: i})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkRef collects the mask paths of the schema referenced by a local JSON pointer, e.g. "#/$defs/card".
+// References to other documents can't be resolved and are ignored,
+// and recursive references are only followed once.
+func (w *schemaWalker) walkRef(ref string, path []segment) error {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	if w.resolving[ref] {
+		return nil
+	}
+	target := w.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		node, ok := target.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid schema reference %q", ref)
+		}
+		if target, ok = node[token]; !ok {
+			return fmt.Errorf("invalid schema reference %q", ref)
+		}
+	}
+	w.resolving[ref] = true
+	defer delete(w.resolving, ref)
+	return w.walk(target, path)
+}
+
+// sortedKeys returns the keys of the schema map in sorted order, for the mask paths to be deterministic.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// add adds the path to the mask paths, if it wasn't already.
+func (w *schemaWalker) add(path []segment) {
+	formatted := formatSegments(path, "")
+	if !w.seen[formatted] {
+		w.seen[formatted] = true
+		w.paths = append(w.paths, formatted)
+	}
+}
+
+// appendSegment returns the path followed by s, without modifying path.
+func appendSegment(path []segment, s segment) []segment {
+	return append(path[:len(path):len(path)], s)
+}
@@ -0,0 +1,123 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMaskerFromSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"ssn": {"type": "string", "x-mask": true},
+			"user.email": {"type": "string", "x-mask": true},
+			"address": {
+				"type": "object",
+				"properties": {
+					"city": {"type": "string"},
+					"street": {"type": "string", "x-mask": true}
+				}
+			},
+			"cards": {
+				"type": "array",
+				"items": {"$ref": "#/$defs/card"}
+			},
+			"tokens": {
+				"type": "object",
+				"additionalProperties": {"type": "string", "x-mask": true}
+			},
+			"pair": {
+				"type": "array",
+				"prefixItems": [{"type": "string", "x-mask": true}, {"type": "string"}]
+			},
+			"contact": {
+				"oneOf": [
+					{"properties": {"phone": {"type": "string", "x-mask": true}}},
+					{"properties": {"email": {"type": "string"}}}
+				]
+			},
+			"manager": {"$ref": "#"}
+		},
+		"$defs": {
+			"card": {
+				"type": "object",
+				"properties": {
+					"number": {"type": "string", "x-mask": true},
+					"type": {"type": "string"}
+				}
+			}
+		}
+	}`
+
+	m, err := NewMaskerFromSchema([]byte(schema))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"$.address.street",
+		"$.cards[].number",
+		"$.contact.phone",
+		"$.manager.address.street",
+		"$.manager.cards[].number",
+		"$.manager.contact.phone",
+		"$.manager.pair[0]",
+		"$.manager.ssn",
+		"$.manager.tokens.*",
+		"$.manager['user.email']",
+		"$.pair[0]",
+		"$.ssn",
+		"$.tokens.*",
+		"$['user.email']",
+	}, m.(*masker).maskPaths)
+
+	output, err := m.Mask(`{"name":"John","ssn":"123","user.email":"a@b.c","address":{"city":"Paris","street":"1 rue"},` +
+		`"cards":[{"number":"4111","type":"visa"}],"tokens":{"a":"x"},"pair":["k","v"],"contact":{"phone":"555"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John","ssn":"[REDACTED]","user.email":"[REDACTED]","address":{"city":"Paris","street":"[REDACTED]"},`+
+		`"cards":[{"number":"[REDACTED]","type":"visa"}],"tokens":{"a":"[REDACTED]"},"pair":["[REDACTED]","v"],"contact":{"phone":"[REDACTED]"}}`, output)
+}
+
+func TestNewMaskerFromSchema_options(t *testing.T) {
+	masker, err := NewMaskerFromSchema([]byte(`{"properties":{"ssn":{"x-mask":true}}}`), WithFixedMaskString("***"))
+	assert.NoError(t, err)
+	output, err := masker.Mask(`{"ssn":"123"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ssn":"***"}`, output)
+}
+
+func TestNewMaskerFromSchema_pathSyntax(t *testing.T) {
+	schema := []byte(`{"properties":{"ssn":{"x-mask":true},"cards":{"items":{"properties":{"number":{"x-mask":true}}}}}}`)
+	input := `{"ssn":"123","name":"John","cards":[{"number":"4111","type":"visa"}]}`
+
+	testTable := []struct {
+		name string
+		opt  option
+	}{
+		{
+			name: "Test with WithJSONPointerPaths",
+			opt:  WithJSONPointerPaths(),
+		},
+		{
+			name: "Test with WithRegexPaths",
+			opt:  WithRegexPaths(),
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker, err := NewMaskerFromSchema(schema, tt.opt)
+			assert.NoError(t, err)
+			output, err := masker.Mask(input)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"ssn":"[REDACTED]","name":"John","cards":[{"number":"[REDACTED]","type":"visa"}]}`, output)
+		})
+	}
+}
+
+func TestNewMaskerFromSchema_invalid(t *testing.T) {
+	_, err := NewMaskerFromSchema([]byte(`{"properties":`))
+	assert.EqualError(t, err, "failed to unmarshal schema: unexpected end of JSON input")
+
+	_, err = NewMaskerFromSchema([]byte(`{"properties":{"card":{"$ref":"#/$defs/card"}}}`))
+	assert.EqualError(t, err, `invalid schema reference "#/$defs/card"`)
+}
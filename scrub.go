@@ -0,0 +1,46 @@
+package masker
+
+import "regexp"
+
+// regexValueMask scrubs the substrings matching a regular expression from the strings at a path,
+// see WithRegexValueMask.
+type regexValueMask struct {
+	pattern     pathPattern
+	re          *regexp.Regexp
+	replacement string
+}
+
+// WithRegexValueMask scrubs the substrings of the string values at path matching pattern,
+// replacing them with replacement instead of masking the whole value, e.g. a token embedded in a message:
+//
+//	WithRegexValueMask("$.message", regexp.MustCompile(`token=\w+`), "token=[REDACTED]")
+//
+// replacement may refer to the submatches of pattern, see regexp.Regexp.ReplaceAllString.
+// The strings at path don't need to be matched by a mask path, which would mask them as a whole,
+// and aren't reported by MaskWithReport, MaskWithVault or WithStrictPaths. Other values at path are kept.
+// path uses the path syntax, and the first WithRegexValueMask whose path matches a string is used.
+func WithRegexValueMask(path string, pattern *regexp.Regexp, replacement string) option {
+	return withOptionPath(path, func(m *masker, _ string, compiled pathPattern) {
+		m.regexValues = append(m.regexValues, regexValueMask{pattern: compiled, re: pattern, replacement: replacement})
+	})
+}
+
+// regexValueMask returns the regular expression scrubbing the string at path, or nil, see WithRegexValueMask.
+func (m *masker) regexValueMask(path nodePath) *regexValueMask {
+	if len(m.regexValues) == 0 {
+		return nil
+	}
+	path = normalizeKeys(path, m.normalizer)
+	for i := range m.regexValues {
+		if m.regexValues[i].pattern.match(path) {
+			return &m.regexValues[i]
+		}
+	}
+	return nil
+}
+
+// scrub replaces the substrings of the string at path matching the regular expression of r.
+func (m *masker) scrub(r *regexValueMask, str string, path nodePath) string {
+	m.log("Scrubbing value", logActionMask, path)
+	return r.re.ReplaceAllString(str, r.replacement)
+}
@@ -0,0 +1,82 @@
+package masker
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRegexValueMask(t *testing.T) {
+	token := regexp.MustCompile(`token=[A-Za-z0-9]+`)
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:     "Test with embedded token",
+			input:    `{"message":"GET /api?token=abc123 page=2 failed","token":"abc123"}`,
+			opts:     []option{WithRegexValueMask("$.message", token, "token=[REDACTED]")},
+			expected: `{"message":"GET /api?token=[REDACTED] page=2 failed","token":"abc123"}`,
+		},
+		{
+			name:     "Test with several matches and wildcard path",
+			input:    `{"logs":[{"line":"token=a then token=b"},{"line":"no secret"}]}`,
+			opts:     []option{WithRegexValueMask("$.logs[].line", token, "")},
+			expected: `{"logs":[{"line":" then "},{"line":"no secret"}]}`,
+		},
+		{
+			name:  "Test with submatch",
+			input: `{"dsn":"postgres://admin:s3cr3t@db:5432/app"}`,
+			opts: []option{
+				WithRegexValueMask("$.dsn", regexp.MustCompile(`://([^:]+):[^@]+@`), "://$1:***@"),
+			},
+			expected: `{"dsn":"postgres://admin:***@db:5432/app"}`,
+		},
+		{
+			name:     "Test with non string value",
+			input:    `{"message":{"text":"token=abc"},"count":3}`,
+			opts:     []option{WithRegexValueMask("$.message", token, ""), WithRegexValueMask("$.count", token, "")},
+			expected: `{"message":{"text":"token=abc"},"count":3}`,
+		},
+		{
+			name:      "Test with masked path",
+			input:     `{"message":"token=abc","note":"token=def"}`,
+			maskPaths: []string{"$.message"},
+			opts:      []option{WithRegexValueMask("$..*", token, "token=***")},
+			expected:  `{"message":"[REDACTED]","note":"token=***"}`,
+		},
+		{
+			name:     "Test with field name normalizer",
+			input:    `{"errorMessage":"token=abc"}`,
+			opts:     []option{WithRegexValueMask("$.error_message", token, "token=***"), WithFieldNameNormalizer(SnakeCaseFieldName)},
+			expected: `{"errorMessage":"token=***"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, tt.opts...)
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		type message string
+		input := map[string]message{"text": "retry with token=abc"}
+		masked, err := NewMasker(nil, WithRegexValueMask("$.text", token, "token=***")).MaskValue(input, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]message{"text": "retry with token=***"}, masked)
+	})
+}
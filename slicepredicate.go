@@ -0,0 +1,71 @@
+package masker
+
+import "reflect"
+
+// slicePredicate masks the elements of the arrays matching a path for which keep returns false,
+// see WithSlicePredicate.
+type slicePredicate struct {
+	pattern pathPattern
+	keep    func(elem any) bool
+}
+
+// WithSlicePredicate masks the elements of the arrays at path for which keep returns false,
+// e.g. only the transactions over a threshold:
+//
+//	WithSlicePredicate("$.transactions", func(elem any) bool {
+//		amount, _ := elem.(map[string]any)["amount"].(json.Number).Float64()
+//		return amount <= 1000
+//	})
+//
+// keep receives every element of the arrays, decoded like the values passed to mask functions,
+// e.g. objects as map[string]any and numbers as json.Number, and masked elements are masked as a whole
+// like the nodes matched by a mask path, e.g. dropped with WithDropMaskedFields.
+// Only the direct elements of the arrays are passed to keep: the elements of nested arrays are passed
+// as the arrays they are, and the elements of the nested arrays are reached by a path ending with "[]",
+// e.g. "$.matrix[]" for the rows of {"matrix":[[1,2],[3]]}. Paths through arrays, e.g. "$.orders[].items",
+// apply to the array of every element. The path doesn't need to be one of the mask paths, and a mask path
+// matching an element masks it regardless of keep. The values at path that aren't arrays aren't affected.
+func WithSlicePredicate(path string, keep func(elem any) bool) option {
+	return withOptionPath(path, func(m *masker, _ string, pattern pathPattern) {
+		if pattern.keys || pattern.exclude {
+			return
+		}
+		m.slicePredicates = append(m.slicePredicates, slicePredicate{pattern: pattern, keep: keep})
+	})
+}
+
+// failsSlicePredicate checks if the array element at path is masked by a slice predicate, see WithSlicePredicate.
+func (m *masker) failsSlicePredicate(elem reflect.Value, path nodePath) bool {
+	if len(m.slicePredicates) == 0 || len(path) < 2 || path[len(path)-1].kind != indexSegment {
+		return false
+	}
+	parent := normalizeKeys(path[:len(path)-1], m.normalizer)
+	var value any
+	converted := false
+	for _, p := range m.slicePredicates {
+		if !p.pattern.match(parent) {
+			continue
+		}
+		if !converted && elem.IsValid() {
+			value, converted = toPlain(elem.Interface()), true
+		}
+		if !p.keep(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSlicePredicate checks if the elements of the array at path may be masked by a slice predicate.
+func (m *masker) hasSlicePredicate(path nodePath) bool {
+	if len(m.slicePredicates) == 0 {
+		return false
+	}
+	path = normalizeKeys(path, m.normalizer)
+	for _, p := range m.slicePredicates {
+		if p.pattern.match(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSlicePredicate(t *testing.T) {
+	underLimit := func(elem any) bool {
+		obj, ok := elem.(map[string]any)
+		if !ok {
+			return true
+		}
+		amount, _ := obj["amount"].(json.Number).Float64()
+		return amount <= 1000
+	}
+	positive := func(elem any) bool {
+		n, ok := elem.(json.Number)
+		return !ok || !strings.HasPrefix(n.String(), "-")
+	}
+	testTable := []struct {
+		name      string
+		input     string
+		path      string
+		keep      func(elem any) bool
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:     "Test with some elements masked",
+			input:    `{"transactions":[{"id":1,"amount":50},{"id":2,"amount":5000},{"id":3,"amount":1000}]}`,
+			path:     "$.transactions",
+			keep:     underLimit,
+			expected: `{"transactions":[{"id":1,"amount":50},"[REDACTED]",{"id":3,"amount":1000}]}`,
+		},
+		{
+			name:     "Test with dropped elements",
+			input:    `{"transactions":[{"amount":5000},{"amount":1}]}`,
+			path:     "$.transactions",
+			keep:     underLimit,
+			opts:     []option{WithDropMaskedFields()},
+			expected: `{"transactions":[{"amount":1}]}`,
+		},
+		{
+			name:     "Test with path through arrays",
+			input:    `{"accounts":[{"transactions":[{"amount":5000}]},{"transactions":[{"amount":2}]}]}`,
+			path:     "$.accounts[].transactions",
+			keep:     underLimit,
+			expected: `{"accounts":[{"transactions":["[REDACTED]"]},{"transactions":[{"amount":2}]}]}`,
+		},
+		{
+			name:     "Test with nested arrays",
+			input:    `{"matrix":[[1,-2],[-3]]}`,
+			path:     "$.matrix[]",
+			keep:     positive,
+			expected: `{"matrix":[[1,"[REDACTED]"],["[REDACTED]"]]}`,
+		},
+		{
+			name:  "Test with nested arrays passed as arrays",
+			input: `{"matrix":[[1,2],[3]]}`,
+			path:  "$.matrix",
+			keep: func(elem any) bool {
+				return len(elem.([]any)) > 1
+			},
+			expected: `{"matrix":[[1,2],"[REDACTED]"]}`,
+		},
+		{
+			name:      "Test with mask path",
+			input:     `{"values":[1,-1],"other":2}`,
+			path:      "$.values",
+			keep:      positive,
+			maskPaths: []string{"$.values[0]"},
+			expected:  `{"values":["[REDACTED]","[REDACTED]"],"other":2}`,
+		},
+		{
+			name:     "Test with non array value",
+			input:    `{"values":{"0":-1},"other":[-1]}`,
+			path:     "$.values",
+			keep:     positive,
+			expected: `{"values":{"0":-1},"other":[-1]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]option{WithSlicePredicate(tt.path, tt.keep)}, tt.opts...)
+			masker := NewMasker(tt.maskPaths, opts...)
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with Go value", func(t *testing.T) {
+		type transaction struct {
+			Amount int
+		}
+		input := map[string][]transaction{"Transactions": {{Amount: 5}, {Amount: 5000}}}
+		masked, err := NewMasker(nil, WithSlicePredicate("$.Transactions", func(elem any) bool {
+			return elem.(transaction).Amount <= 1000
+		})).MaskValue(input, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"Transactions": []any{transaction{Amount: 5}, "[REDACTED]"}}, masked)
+	})
+}
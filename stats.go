@@ -0,0 +1,45 @@
+package masker
+
+// MaskWithStats masks the input JSON string like MaskWithPaths and additionally returns, for every mask path,
+// the number of nodes it matched, e.g. to find the paths of a batch of documents that never match anything
+// and prune them: paths with a count of 0 are candidates for removal. Unlike WithStrictPaths,
+// unmatched paths aren't errors. A node matched by several paths counts for each of them,
+// the leaves masked below a node matched by a path, with exclusions or WithDeepMaskSubtrees, count for it,
+// and the nulls and empty values kept by WithKeepNulls or WithMaskEmptyAsEmpty count as matched.
+// Key paths count the keys they matched, and exclusions aren't counted.
+func (m *masker) MaskWithStats(input string, maskPaths []string) (string, map[string]int, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return "", nil, err
+	}
+	state.counts = make(map[string]int, len(state.maskPaths.all))
+	for _, pattern := range state.maskPaths.all {
+		state.counts[pattern.path] = 0
+	}
+	maskedBytes, err := m.mask([]byte(input), state)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(maskedBytes), state.counts, nil
+}
+
+// recordCount counts the node at path, or its key, for the mask paths matching it for MaskWithStats,
+// or for those matching the nearest of its ancestors if none does.
+func (s *maskState) recordCount(path nodePath, key bool) {
+	if s.counts == nil {
+		return
+	}
+	path = s.maskPaths.normalize(path)
+	for i := len(path); i > 0; i-- {
+		counted := false
+		for _, pattern := range s.maskPaths.all {
+			if pattern.keys == key && pattern.match(path[:i]) {
+				s.counts[pattern.path]++
+				counted = true
+			}
+		}
+		if counted || key {
+			return
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskWithStats(t *testing.T) {
+	input := `{"users":[{"ssn":"1","email":"a@b.c"},{"ssn":"2","phone":null}],"ids":{"u1":1,"u2":2},"meta":{"token":"t","tags":["x","y"]}}`
+	testTable := []struct {
+		name           string
+		maskPaths      []string
+		opts           []option
+		expected       string
+		expectedCounts map[string]int
+	}{
+		{
+			name:           "Test with matched and stale paths",
+			maskPaths:      []string{"$.users[].ssn", "$..email", "$.password", "$.ids.*~"},
+			expected:       `{"users":[{"ssn":"[REDACTED]","email":"[REDACTED]"},{"ssn":"[REDACTED]","phone":null}],"ids":{"[REDACTED]":1,"[REDACTED]_2":2},"meta":{"token":"t","tags":["x","y"]}}`,
+			expectedCounts: map[string]int{"$.users[].ssn": 2, "$..email": 1, "$.password": 0, "$.ids.*~": 2},
+		},
+		{
+			name:           "Test with overlapping paths",
+			maskPaths:      []string{"$.users[0].ssn", "$..ssn", "$.meta"},
+			expected:       `{"users":[{"ssn":"[REDACTED]","email":"a@b.c"},{"ssn":"[REDACTED]","phone":null}],"ids":{"u1":1,"u2":2},"meta":"[REDACTED]"}`,
+			expectedCounts: map[string]int{"$.users[0].ssn": 1, "$..ssn": 2, "$.meta": 1},
+		},
+		{
+			name:           "Test with exclusions",
+			maskPaths:      []string{"$.meta", "!$.meta.token"},
+			expected:       `{"users":[{"ssn":"1","email":"a@b.c"},{"ssn":"2","phone":null}],"ids":{"u1":1,"u2":2},"meta":{"token":"t","tags":"[REDACTED]"}}`,
+			expectedCounts: map[string]int{"$.meta": 1},
+		},
+		{
+			name:           "Test with deep masking",
+			maskPaths:      []string{"$.meta"},
+			opts:           []option{WithDeepMaskSubtrees()},
+			expected:       `{"users":[{"ssn":"1","email":"a@b.c"},{"ssn":"2","phone":null}],"ids":{"u1":1,"u2":2},"meta":{"token":"[REDACTED]","tags":["[REDACTED]","[REDACTED]"]}}`,
+			expectedCounts: map[string]int{"$.meta": 3},
+		},
+		{
+			name:           "Test with kept nulls",
+			maskPaths:      []string{"$.users[].phone"},
+			opts:           []option{WithKeepNulls()},
+			expected:       `{"users":[{"ssn":"1","email":"a@b.c"},{"ssn":"2","phone":null}],"ids":{"u1":1,"u2":2},"meta":{"token":"t","tags":["x","y"]}}`,
+			expectedCounts: map[string]int{"$.users[].phone": 1},
+		},
+		{
+			name:           "Test with regex paths",
+			maskPaths:      []string{`^\$\.users\[\]\.(ssn|email)$`, `^\$\.nothing$`},
+			opts:           []option{WithRegexPaths()},
+			expected:       `{"users":[{"ssn":"[REDACTED]","email":"[REDACTED]"},{"ssn":"[REDACTED]","phone":null}],"ids":{"u1":1,"u2":2},"meta":{"token":"t","tags":["x","y"]}}`,
+			expectedCounts: map[string]int{`^\$\.users\[\]\.(ssn|email)$`: 3, `^\$\.nothing$`: 0},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, counts, err := NewMasker(nil, tt.opts...).MaskWithStats(input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+			assert.Equal(t, tt.expectedCounts, counts)
+		})
+	}
+
+	t.Run("Test with concurrency", func(t *testing.T) {
+		_, counts, err := NewMasker([]string{"$[].id", "$[].name"}, WithConcurrency(4)).MaskWithStats(largeArray(500), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"$[].id": 500, "$[].name": 0}, counts)
+	})
+
+	t.Run("Test with invalid input", func(t *testing.T) {
+		_, counts, err := NewMasker([]string{"$.a"}).MaskWithStats(`{"a":`, nil)
+		assert.Error(t, err)
+		assert.Nil(t, counts)
+	})
+}
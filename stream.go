@@ -0,0 +1,352 @@
+package masker
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// MaskReader reads a JSON document from r, masks it based on the provided maskPaths
+// and writes the masked document to w.
+// Unlike Mask, the document is never fully loaded into memory: it is walked token by token,
+// so memory stays bounded by the nesting depth and the size of the masked values.
+// The options masking nodes based on their content buffer the nodes they apply to: the parent objects
+// of WithConditionalMask, the arrays of WithSlicePredicate and of the paths with negative indexes,
+// and the matched nodes with WithDeepMaskSubtrees or WithProtectedKeys. As any object may carry a tag,
+// WithTypeTagRule buffers the whole document, like WithDropMaskedFields along with options such as
+// exclusions, WithKeepNulls or WithProtectedKeys.
+// Object keys are written in the order they appear in the input and numbers keep their precision.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+// If an error is returned, part of the masked document may already have been written to w.
+func (m *masker) MaskReader(r io.Reader, w io.Writer, maskPaths []string) error {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return err
+	}
+	return m.maskStream(r, w, state)
+}
+
+// maskStream masks the JSON document read from r using the provided state, writing it to w, see MaskReader.
+func (m *masker) maskStream(r io.Reader, w io.Writer, state *maskState) error {
+	if m.isLenient {
+		r = newLenientReader(r)
+	}
+	s := &streamMasker{
+		masker: m,
+		dec:    json.NewDecoder(r),
+		out:    bufio.NewWriter(m.limitOutput(w)),
+		state:  state,
+	}
+	s.dec.UseNumber()
+	if err := s.maskValue(rootPath()); err != nil {
+		return err
+	}
+	if _, err := s.dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("invalid character after top-level value")
+		}
+		return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	if err := s.state.unmatchedErr(); err != nil {
+		return err
+	}
+	if err := s.out.Flush(); err != nil {
+		return fmt.Errorf("failed to write masked object: %w", err)
+	}
+	return nil
+}
+
+// streamMasker holds the state of a single MaskReader call.
+type streamMasker struct {
+	masker *masker
+	dec    *json.Decoder
+	out    *bufio.Writer
+	state  *maskState
+	// depth is the depth of the value being written, to indent it, see WithIndent.
+	depth int
+}
+
+// maskValue reads the next value from the decoder and writes its masked form.
+// path is the current path of the value in the JSON.
+func (s *streamMasker) maskValue(path nodePath) error {
+	s.depth = len(path) - 1
+	s.masker.log("Processing path", logActionProcess, path)
+	if err := checkDepth(path, s.masker.maxDepth); err != nil {
+		return err
+	}
+	if err := s.state.checkContext(); err != nil {
+		return err
+	}
+	if s.masker.isProtected(path) {
+		return s.copyProtected(path)
+	}
+	if s.state.matches(path) {
+		if s.masker.isDeepMask || len(s.masker.protectedKeys) > 0 {
+			// matched objects and arrays are traversed to mask their leaves, or to keep their protected keys
+			return s.maskBuffered(path)
+		}
+		if s.masker.isDrop {
+			// only the root gets here, masked members and elements are skipped by their parent
+			if err := s.skipValue(path); err != nil {
+				return err
+			}
+			return s.write(nil)
+		}
+		var value interface{}
+		if err := s.dec.Decode(&value); err != nil {
+			return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+		}
+		return s.maskDecoded(value, path)
+	}
+	if s.passesThrough(path) {
+		return s.copyRaw(path)
+	}
+
+	if s.masker.isConditionParent(path) || s.masker.hasSlicePredicate(path) || s.state.maskPaths.indexesFromEnd(path) || len(s.masker.typeTags) > 0 || (s.masker.isDrop && (s.masker.masksLeaves() || s.masker.isKeepNulls ||
+		s.masker.isKeepEmpty || s.masker.maskedMarker != "" || s.masker.isDeepMask || len(s.state.maskPaths.exclusions) > 0 ||
+		len(s.masker.protectedKeys) > 0)) {
+		return s.maskBuffered(path)
+	}
+
+	token, err := s.dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	switch token {
+	case json.Delim('{'):
+		return s.maskObject(path)
+	case json.Delim('['):
+		return s.maskArray(path)
+	default:
+		if s.state.outsideSubtree(path, true) {
+			return s.write(token)
+		}
+		if str, ok := token.(string); ok && s.masker.isNestedJSON(path) {
+			masked, err := s.masker.maskNestedJSON(str, s.state, path)
+			if err != nil {
+				return fmt.Errorf("failed to mask object: %w", err)
+			}
+			return s.write(masked)
+		}
+		if str, ok := token.(string); ok && s.masker.isBase64JSON(path) {
+			masked, err := s.masker.maskBase64JSON(str, s.state, path)
+			if err != nil {
+				return fmt.Errorf("failed to mask object: %w", err)
+			}
+			return s.write(masked)
+		}
+		if str, ok := token.(string); ok {
+			if field := s.masker.csvField(path); field != nil {
+				masked, err := s.masker.maskCSV(field, str, path)
+				if err != nil {
+					return fmt.Errorf("failed to mask object: %w", err)
+				}
+				return s.write(masked)
+			}
+			if r := s.masker.regexValueMask(path); r != nil {
+				return s.write(s.masker.scrub(r, str, path))
+			}
+		}
+		if b := s.masker.numericBucket(reflect.ValueOf(token), path); b != nil {
+			return s.write(s.masker.bucketNumber(b, reflect.ValueOf(token), path))
+		}
+		if s.masker.masksLeaf(reflect.ValueOf(token), s.state, path) {
+			return s.maskDecoded(token, path)
+		}
+		return s.write(token)
+	}
+}
+
+// maskDecoded writes the masked form of the value at path, which was already read from the decoder.
+func (s *streamMasker) maskDecoded(value any, path nodePath) error {
+	if s.masker.keepsMatched(reflect.ValueOf(value)) {
+		s.masker.log("Keeping matched value", logActionKeep, path)
+		s.state.recordKept(path)
+		return s.write(value)
+	}
+	s.masker.log("Masking path", logActionMask, path)
+	s.state.recordMasked(path)
+	s.masker.notifyMasked(path, value)
+	masked, err := s.masker.maskedValue(value, path, value == nil)
+	if err != nil {
+		return fmt.Errorf("failed to mask object: %w", err)
+	}
+	return s.write(masked)
+}
+
+// maskBuffered reads the whole next value and masks it in memory like Mask does.
+// It is used for the values whose masking depends on their own content,
+// such as the parent objects of conditional masks.
+func (s *streamMasker) maskBuffered(path nodePath) error {
+	d := &decoder{dec: s.dec, maxDepth: s.masker.maxDepth, duplicates: s.masker.duplicates, path: append(nodePath{}, path...)}
+	value, err := d.decodeValue()
+	if err != nil {
+		return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	maskedValue, err := s.masker.maskWithPaths(reflect.ValueOf(value), s.state, path)
+	if err != nil {
+		return fmt.Errorf("failed to mask object: %w", err)
+	}
+	if isDropped(maskedValue) {
+		// only a dropped root gets here, members and elements being buffered with their parent
+		maskedValue = nil
+	}
+	return s.write(maskedValue)
+}
+
+// maskObject writes the members of the object whose opening brace was just read.
+// As the object isn't buffered, masked keys are only made unique among the keys written
+// before them, unlike Mask which also considers the keys that follow.
+func (s *streamMasker) maskObject(path nodePath) error {
+	var written, seen map[string]bool
+	if len(s.state.maskPaths.keyPatterns) > 0 {
+		written = make(map[string]bool)
+	}
+	if s.masker.duplicates == DuplicateKeysError {
+		seen = make(map[string]bool)
+	}
+	s.out.WriteByte('{')
+	first := true
+	for s.dec.More() {
+		token, err := s.dec.Token()
+		if err != nil {
+			return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+		}
+		key := token.(string)
+		if seen != nil {
+			if seen[key] {
+				return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: duplicate key at path %s", path.key(key)))
+			}
+			seen[key] = true
+		}
+		if s.drops(path.key(key)) {
+			if err := s.skipValue(path.key(key)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !first {
+			s.out.WriteByte(',')
+		}
+		first = false
+		s.newline(len(path))
+		outKey := key
+		if written != nil {
+			if s.state.maskPaths.matchesKey(path.key(key)) && s.masker.isMasked(key) {
+				s.masker.log("Keeping masked key", logActionKeep, path.key(key))
+				s.state.recordKeptKey(path.key(key))
+			} else if s.state.maskPaths.matchesKey(path.key(key)) && !s.masker.isProtectedKey(key) {
+				s.masker.log("Masking key", logActionMaskKey, path.key(key))
+				s.state.recordMaskedKey(path.key(key))
+				s.masker.notifyMaskedKey(path.key(key), key)
+				maskedKey, err := s.masker.maskedKey(key, path.key(key))
+				if err != nil {
+					return fmt.Errorf("failed to mask object: %w", err)
+				}
+				outKey = uniqueKey(maskedKey, written)
+			}
+			written[outKey] = true
+		}
+		if err := s.write(outKey); err != nil {
+			return err
+		}
+		s.out.WriteByte(':')
+		if s.masker.isIndent {
+			s.out.WriteByte(' ')
+		}
+		if err := s.maskValue(path.key(key)); err != nil {
+			return err
+		}
+	}
+	if !first {
+		s.newline(len(path) - 1)
+	}
+	return s.closeDelim('}')
+}
+
+// maskArray writes the elements of the array whose opening bracket was just read.
+func (s *streamMasker) maskArray(path nodePath) error {
+	s.out.WriteByte('[')
+	i, written := 0, 0
+	for ; s.dec.More(); i++ {
+		if s.drops(path.index(i)) {
+			if err := s.skipValue(path.index(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if written > 0 {
+			s.out.WriteByte(',')
+		}
+		written++
+		s.newline(len(path))
+		if err := s.maskValue(path.index(i)); err != nil {
+			return err
+		}
+	}
+	if written > 0 {
+		s.newline(len(path) - 1)
+	}
+	if i == 0 {
+		s.state.recordEmptyArray(path)
+	}
+	return s.closeDelim(']')
+}
+
+// drops checks if the node at path is masked and should be removed, see WithDropMaskedFields.
+func (s *streamMasker) drops(path nodePath) bool {
+	return s.masker.isDrop && s.state.matches(path)
+}
+
+// skipValue reads the masked value at path without writing it.
+func (s *streamMasker) skipValue(path nodePath) error {
+	s.masker.log("Masking path", logActionMask, path)
+	s.state.recordMasked(path)
+	if s.masker.onMask != nil {
+		// the hook receives the decoded value
+		var value any
+		if err := s.dec.Decode(&value); err != nil {
+			return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+		}
+		s.masker.notifyMasked(path, value)
+		return nil
+	}
+	var value json.RawMessage
+	if err := s.dec.Decode(&value); err != nil {
+		return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	return nil
+}
+
+// closeDelim consumes the closing delimiter of the current object or array and writes it.
+func (s *streamMasker) closeDelim(delim byte) error {
+	if _, err := s.dec.Token(); err != nil {
+		return withKind(ErrInvalidJSON, fmt.Errorf("failed to unmarshal input: %w", err))
+	}
+	return s.out.WriteByte(delim)
+}
+
+// write marshals a single value to the output.
+func (s *streamMasker) write(value any) error {
+	var bytes []byte
+	var err error
+	if s.masker.isIndent {
+		bytes, err = json.MarshalIndent(value, s.masker.indentPrefix+strings.Repeat(s.masker.indent, s.depth), s.masker.indent)
+	} else {
+		bytes, err = json.Marshal(value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal masked object: %w", err)
+	}
+	if _, err := s.out.Write(s.masker.encodeOutput(bytes)); err != nil {
+		return fmt.Errorf("failed to write masked object: %w", err)
+	}
+	return nil
+}
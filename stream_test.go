@@ -0,0 +1,87 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskReader(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "Test with nested object",
+			input:     `{"name":"John","jobs":[{"id":1,"name":"dev","list":["a","b"]}]}`,
+			maskPaths: []string{"$.name", "$.jobs[].list[]"},
+			expected:  `{"name":"[REDACTED]","jobs":[{"id":1,"name":"dev","list":["[REDACTED]","[REDACTED]"]}]}`,
+		},
+		{
+			name:      "Test with masked subtree",
+			input:     `{"a":{"b":{"c":1}},"d":2}`,
+			maskPaths: []string{"$.a"},
+			expected:  `{"a":"[REDACTED]","d":2}`,
+		},
+		{
+			name:      "Test with top level array",
+			input:     `[1, 2, {"x": true}]`,
+			maskPaths: []string{"$[].x"},
+			expected:  `[1,2,{"x":"[REDACTED]"}]`,
+		},
+		{
+			name:      "Test with top level scalar",
+			input:     `"secret"`,
+			maskPaths: []string{"$"},
+			expected:  `"[REDACTED]"`,
+		},
+		{
+			name:     "Test with top level scalar and no mask",
+			input:    `null`,
+			expected: `null`,
+		},
+		{
+			name:      "Test with invalid json",
+			input:     `{"a":`,
+			expectErr: true,
+		},
+		{
+			name:      "Test with trailing data",
+			input:     `{"a":1} {"b":2}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			var out bytes.Buffer
+			err := masker.MaskReader(strings.NewReader(tt.input), &out, nil)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestMaskReader_matchesMask(t *testing.T) {
+	input := largeDocument(1 << 20)
+	maskPaths := []string{"$[].email", "$[].tags[]", "$.*.name"}
+	masker := NewMasker(maskPaths)
+
+	var out bytes.Buffer
+	err := masker.MaskReader(bytes.NewReader(input), &out, nil)
+	assert.NoError(t, err)
+
+	expected, err := masker.MaskBytes(input)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expected), out.String())
+}
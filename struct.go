@@ -0,0 +1,200 @@
+package masker
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maskTag is the struct tag marking fields to be masked by MaskStruct.
+const maskTag = "mask"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// MaskStruct marshals v to JSON like json.Marshal, masking the struct fields tagged with `mask`:
+//
+//	`mask:"true"`    masks the field like the nodes matched by the mask paths
+//	`mask:"partial"` masks the field keeping its last 4 characters, see WithPartialMask
+//	`mask:"hash"`    masks the field with its unsalted SHA-256 hash, see WithHashMask
+//
+// Tagged fields are found in nested and embedded structs, through pointers
+// and in slices, arrays and maps. The paths passed to NewMasker are masked as well,
+// in the path syntax of the masker, e.g. JSON Pointers with WithJSONPointerPaths, while the tags
+// apply whatever the syntax.
+func (m *masker) MaskStruct(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input: %w", err)
+	}
+	tagged := *m
+	tagged.pathFuncs = make(map[string]func(field any) string, len(m.pathFuncs))
+	for path, maskFunc := range m.pathFuncs {
+		tagged.pathFuncs[path] = maskFunc
+	}
+	paths := taggedPaths{seen: map[string]struct{}{}}
+	if err := tagged.collectTaggedPaths(reflect.ValueOf(v), rootPath(), &paths); err != nil {
+		return nil, err
+	}
+	if m.err == nil {
+		tagged.paths = m.paths.with(paths.patterns...)
+	}
+	return tagged.maskBytes(data, nil)
+}
+
+// taggedPaths are the patterns of the tagged fields collected by MaskStruct.
+type taggedPaths struct {
+	patterns []pathPattern
+	// seen holds the normalized paths already compiled, so the elements
+	// of a slice of structs share the patterns of their fields.
+	seen map[string]struct{}
+}
+
+// add compiles the normalized path of a tagged field, unless it was already added.
+func (t *taggedPaths) add(path string) {
+	if _, ok := t.seen[path]; ok {
+		return
+	}
+	t.seen[path] = struct{}{}
+	t.patterns = append(t.patterns, compilePattern(path))
+}
+
+// collectTaggedPaths walks the value the way json.Marshal does, adding the patterns of the fields
+// tagged with `mask` to paths, whatever the path syntax of the masker, along with the mask functions
+// of the tags masking them in their own way. Array indexes are collapsed, so a tagged field is masked
+// in every element of a slice.
+func (m *masker) collectTaggedPaths(value reflect.Value, path nodePath, paths *taggedPaths) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if implementsMarshaler(value.Type()) {
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return m.collectStructPaths(value, path, paths)
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			// byte slices are marshaled as base64 strings
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := m.collectTaggedPaths(value.Index(i), path.index(i), paths); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := m.collectTaggedPaths(iter.Value(), path.key(mapKey(iter.Key())), paths); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectStructPaths collects the tagged paths of the fields of a struct.
+// Fields of embedded structs without a json name are promoted to the struct itself.
+func (m *masker) collectStructPaths(value reflect.Value, path nodePath, paths *taggedPaths) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			if err := m.collectTaggedPaths(value.Field(i), path, paths); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldPath := path.key(name)
+		tag, tagged := field.Tag.Lookup(maskTag)
+		if !tagged {
+			if err := m.collectTaggedPaths(value.Field(i), fieldPath, paths); err != nil {
+				return err
+			}
+			continue
+		}
+		maskFunc, masked, err := tagMaskFunc(tag)
+		if err != nil {
+			return fmt.Errorf("invalid mask tag on field %s: %w", field.Name, err)
+		}
+		if !masked {
+			continue
+		}
+		paths.add(normalizePath(fieldPath))
+		if maskFunc != nil {
+			m.pathFuncs[normalizePath(normalizeKeys(fieldPath, m.normalizer))] = maskFunc
+		}
+	}
+	return nil
+}
+
+// tagMaskFunc returns the mask function for a `mask` tag value, and whether the field is masked.
+// The mask function is nil for the fields masked like the other matched nodes, e.g. with WithReplacer.
+func tagMaskFunc(tag string) (func(field any) string, bool, error) {
+	switch tag {
+	case "true":
+		return nil, true, nil
+	case "false", "":
+		return nil, false, nil
+	case "partial":
+		return maskFuncOf(WithPartialMask(4, '*')), true, nil
+	case "hash":
+		return maskFuncOf(WithHashMask("")), true, nil
+	default:
+		return nil, false, fmt.Errorf("unknown value %q", tag)
+	}
+}
+
+// jsonFieldName returns the name of a struct field in its json tag, empty if it has none.
+// It returns false if the field isn't marshaled by encoding/json.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// implementsMarshaler reports whether values of the type encode themselves,
+// in which case their fields don't map to JSON keys.
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// isOpaque reports whether values of the type are leaves for MaskValue even though they are structs,
+// arrays, slices or maps, e.g. time.Time or net.IP: their fields or elements aren't what they encode to.
+func isOpaque(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		return implementsMarshaler(t) || t.Implements(stringerType) || reflect.PointerTo(t).Implements(stringerType)
+	default:
+		return false
+	}
+}
+
+// maskFuncOf returns the mask function installed by a mask function option.
+func maskFuncOf(opt option) func(field any) string {
+	m := &masker{}
+	opt(m)
+	return m.maskFunc
+}
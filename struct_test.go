@@ -0,0 +1,226 @@
+package masker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskStruct(t *testing.T) {
+	type Card struct {
+		Number string `json:"number" mask:"partial"`
+		Holder string `json:"holder"`
+	}
+	type Audit struct {
+		CreatedBy string `json:"created_by" mask:"hash"`
+	}
+	type Account struct {
+		Audit
+		ID        int       `json:"id"`
+		SSN       string    `json:"ssn" mask:"true"`
+		Password  *string   `json:"password,omitempty" mask:"true"`
+		Cards     []Card    `json:"cards"`
+		Primary   *Card     `json:"primary,omitempty"`
+		Notes     string    `json:"-" mask:"true"`
+		Untagged  string    `mask:"false"`
+		CreatedAt time.Time `json:"created_at"`
+		Cards2    map[string]Card
+	}
+
+	password := "hunter2"
+	createdAt, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	testTable := []struct {
+		name      string
+		input     any
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name: "Test with nested, embedded and pointer fields",
+			input: Account{
+				Audit:     Audit{CreatedBy: "admin"},
+				ID:        1,
+				SSN:       "123-45-6789",
+				Password:  &password,
+				Cards:     []Card{{Number: "4111111111111234", Holder: "John"}, {Number: "5500000000005678", Holder: "Jane"}},
+				Primary:   &Card{Number: "4111111111111234", Holder: "John"},
+				Notes:     "hidden",
+				Untagged:  "visible",
+				CreatedAt: createdAt,
+				Cards2:    map[string]Card{"backup": {Number: "4000000000009999", Holder: "John"}},
+			},
+			expected: `{"created_by":"8c6976e5b5410415bde908bd4dee15dfb167a9c873fc4bb8a81f6f2ab448a918","id":1,"ssn":"[REDACTED]","password":"[REDACTED]","cards":[{"number":"************1234","holder":"John"},{"number":"************5678","holder":"Jane"}],"primary":{"number":"************1234","holder":"John"},"Untagged":"visible","created_at":"2021-01-01T00:00:00Z","Cards2":{"backup":{"number":"************9999","holder":"John"}}}`,
+		},
+		{
+			name:     "Test with nil pointer fields",
+			input:    &Account{ID: 2, CreatedAt: createdAt},
+			expected: `{"created_by":"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855","id":2,"ssn":"[REDACTED]","cards":null,"Untagged":"","created_at":"2021-01-01T00:00:00Z","Cards2":null}`,
+		},
+		{
+			name:      "Test with slice of structs and mask paths",
+			input:     []Card{{Number: "4111111111111234", Holder: "John"}},
+			maskPaths: []string{"$[].holder"},
+			expected:  `[{"number":"************1234","holder":"[REDACTED]"}]`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths)
+			output, err := masker.MaskStruct(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}
+
+func TestMaskStruct_sharedPatterns(t *testing.T) {
+	type Card struct {
+		Number string `json:"number" mask:"true"`
+	}
+	m := NewMasker(nil).(*masker)
+	paths := taggedPaths{seen: map[string]struct{}{}}
+	cards := []Card{{Number: "1"}, {Number: "2"}, {Number: "3"}}
+	assert.NoError(t, m.collectTaggedPaths(reflect.ValueOf(cards), rootPath(), &paths))
+	assert.Len(t, paths.patterns, 1)
+	assert.Equal(t, "$[].number", paths.patterns[0].path)
+}
+
+func TestMaskStruct_invalidTag(t *testing.T) {
+	type invalid struct {
+		Field string `mask:"sometimes"`
+	}
+	_, err := NewMasker(nil).MaskStruct(invalid{Field: "a"})
+	assert.EqualError(t, err, `invalid mask tag on field Field: unknown value "sometimes"`)
+}
+
+func TestMaskStruct_maskOptions(t *testing.T) {
+	type User struct {
+		Name  string  `json:"name" mask:"true"`
+		Age   int     `json:"age" mask:"true"`
+		Email *string `json:"email" mask:"true"`
+		Card  string  `json:"card" mask:"partial"`
+	}
+	user := User{Name: "John", Age: 42, Card: "4111111111111234"}
+
+	testTable := []struct {
+		name     string
+		opt      option
+		expected string
+	}{
+		{
+			name: "Test with WithReplacer",
+			opt: WithReplacer(func(value any, path string) any {
+				return path
+			}),
+			expected: `{"name":"$.name","age":"$.age","email":"$.email","card":"************1234"}`,
+		},
+		{
+			name: "Test with WithMaskFuncForType",
+			opt: WithMaskFuncForType(reflect.Float64, func(value any) any {
+				return 0
+			}),
+			expected: `{"name":"[REDACTED]","age":0,"email":"[REDACTED]","card":"************1234"}`,
+		},
+		{
+			name: "Test with WithMaskFuncContext",
+			opt: WithMaskFuncContext(func(field any, path string) string {
+				return "masked " + path
+			}),
+			expected: `{"name":"masked $.name","age":"masked $.age","email":"masked $.email","card":"************1234"}`,
+		},
+		{
+			name: "Test with WithKeyMaskFunc",
+			opt: WithKeyMaskFunc(func(field any, key string) string {
+				return "masked " + key
+			}),
+			expected: `{"name":"masked name","age":"masked age","email":"masked email","card":"************1234"}`,
+		},
+		{
+			name: "Test with WithMaskFuncError",
+			opt: WithMaskFuncError(func(field any) (string, error) {
+				return "***", nil
+			}),
+			expected: `{"name":"***","age":"***","email":"***","card":"************1234"}`,
+		},
+		{
+			name: "Test with WithMaskNullsDistinctly",
+			opt: WithMaskNullsDistinctly(func(field any, isNull bool) string {
+				if isNull {
+					return "null"
+				}
+				return "***"
+			}),
+			expected: `{"name":"***","age":"***","email":"null","card":"************1234"}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(nil, tt.opt)
+			output, err := masker.MaskStruct(user)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}
+
+func TestMaskStruct_pathSyntax(t *testing.T) {
+	type Card struct {
+		Number string `json:"number" mask:"partial"`
+		Holder string `json:"holder"`
+		CVV    string `json:"cvv" mask:"true"`
+	}
+	type Account struct {
+		ID    int    `json:"id"`
+		SSN   string `json:"ssn" mask:"true"`
+		Cards []Card `json:"cards"`
+	}
+	account := Account{ID: 1, SSN: "123-45-6789", Cards: []Card{{Number: "4111111111111234", Holder: "John", CVV: "123"}}}
+	compiled, err := CompilePaths([]string{"$.id"})
+	assert.NoError(t, err)
+
+	testTable := []struct {
+		name      string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with WithJSONPointerPaths",
+			maskPaths: []string{"/cards/0/holder"},
+			opts:      []option{WithJSONPointerPaths()},
+			expected:  `{"id":1,"ssn":"[REDACTED]","cards":[{"number":"************1234","holder":"[REDACTED]","cvv":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with WithRegexPaths",
+			maskPaths: []string{`^\$\.cards\[\]\.holder$`},
+			opts:      []option{WithRegexPaths()},
+			expected:  `{"id":1,"ssn":"[REDACTED]","cards":[{"number":"************1234","holder":"[REDACTED]","cvv":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "Test with WithCompiledPaths",
+			opts:     []option{WithCompiledPaths(compiled)},
+			expected: `{"id":"[REDACTED]","ssn":"[REDACTED]","cards":[{"number":"************1234","holder":"John","cvv":"[REDACTED]"}]}`,
+		},
+		{
+			name:      "Test with WithFieldNameNormalizer",
+			maskPaths: []string{"$.ID"},
+			opts:      []option{WithFieldNameNormalizer(strings.ToLower)},
+			expected:  `{"id":"[REDACTED]","ssn":"[REDACTED]","cards":[{"number":"************1234","holder":"John","cvv":"[REDACTED]"}]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, tt.opts...)
+			output, err := masker.MaskStruct(account)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}
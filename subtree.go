@@ -0,0 +1,111 @@
+package masker
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// subtreeRoot is the pattern matching the roots of the subtrees masked by MaskSubtree.
+type subtreeRoot struct {
+	pattern    pathPattern
+	normalizer func(string) string
+}
+
+// MaskSubtree masks the input JSON string like MaskWithPaths, but only within the subtrees rooted
+// at the nodes matched by rootPath, relPaths being relative to them, e.g. "user.ssn" below "$.data"
+// masks "$.data.user.ssn". rootPath may contain wildcards, e.g. "$.items[]", to mask every item.
+// relPaths are written like the paths of WithTypeTagRule, e.g. "user.ssn", "[0].ssn" or "..ssn",
+// or from the root of the subtree, e.g. "$.user.ssn", and may be key or exclusion paths.
+// The nodes outside the subtrees are left untouched, including by the options masking values
+// whatever the paths, e.g. WithValueMatcher or WithKeepOnly. The paths reported by WithStrictPaths
+// are absolute. The paths passed to NewMasker don't apply, and the default path syntax
+// must be used: MaskSubtree returns an error wrapping ErrInvalidPath with WithRegexPaths
+// and WithJSONPointerPaths.
+func (m *masker) MaskSubtree(input string, rootPath string, relPaths []string) (string, error) {
+	if m.isRegex || m.isJSONPointer {
+		return "", withKind(ErrInvalidPath, errors.New("subtree paths require the default path syntax"))
+	}
+	pattern, err := parsePattern(rootPath)
+	if err == nil && (pattern.keys || pattern.exclude) {
+		err = errors.New("root path must match values")
+	}
+	if err != nil {
+		return "", withKind(ErrInvalidPath, fmt.Errorf("invalid root path %s: %w", rootPath, err))
+	}
+	paths := make([]string, 0, len(relPaths))
+	for _, relPath := range relPaths {
+		paths = append(paths, joinPaths(rootPath, relPath))
+	}
+	state, err := m.newMaskState(paths)
+	if err != nil {
+		return "", err
+	}
+	state.subtree = &subtreeRoot{pattern: pattern.normalized(m.normalizer), normalizer: m.normalizer}
+	masked, err := m.mask([]byte(input), state)
+	if err != nil {
+		return "", err
+	}
+	return string(masked), nil
+}
+
+// rootedPath returns the relative path written from the root, e.g. "$.user.ssn" for "user.ssn",
+// "$[0]" for "[0]" and "$..ssn" for "..ssn". Paths starting with "$" are returned as they are.
+func rootedPath(path string) string {
+	switch {
+	case strings.HasPrefix(path, "$"):
+		return path
+	case strings.HasPrefix(path, "[") || strings.HasPrefix(path, "."):
+		return "$" + path
+	default:
+		return "$." + path
+	}
+}
+
+// joinPaths returns the path of relPath below the nodes matched by rootPath, keeping its exclusion prefix.
+func joinPaths(rootPath, relPath string) string {
+	relPath, exclude := strings.CutPrefix(relPath, excludePrefix)
+	joined := rootPath + strings.TrimPrefix(rootedPath(relPath), "$")
+	if exclude {
+		return excludePrefix + joined
+	}
+	return joined
+}
+
+// contains checks if the node at path is a root of the subtrees or is below one.
+func (r *subtreeRoot) contains(path nodePath) bool {
+	path = normalizeKeys(path, r.normalizer)
+	for i := len(path); i > 0; i-- {
+		if r.pattern.match(path[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// outsideSubtree checks if the node at path is left untouched by MaskSubtree, being neither in a subtree
+// nor above one, leaf being set for the nodes that have nothing below them.
+func (s *maskState) outsideSubtree(path nodePath, leaf bool) bool {
+	if s.subtree == nil || s.subtree.contains(path) {
+		return false
+	}
+	return leaf || !s.subtree.pattern.matchPrefix(normalizeKeys(path, s.subtree.normalizer))
+}
+
+// isContainer checks if the value may have nodes below it: an object, an array, a map or a struct.
+func isContainer(value reflect.Value) bool {
+	if !value.IsValid() {
+		return false
+	}
+	if value.Type() == objectType {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Array:
+		return true
+	case reflect.Slice:
+		return value.Type() != rawMessageType
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package masker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskSubtree(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		rootPath string
+		relPaths []string
+		opts     []option
+		expected string
+	}{
+		{
+			name:     "Test with relative path",
+			input:    `{"data":{"user":{"ssn":"123","name":"John"}},"user":{"ssn":"456"}}`,
+			rootPath: "$.data",
+			relPaths: []string{"user.ssn"},
+			expected: `{"data":{"user":{"ssn":"[REDACTED]","name":"John"}},"user":{"ssn":"456"}}`,
+		},
+		{
+			name:     "Test with relative path written from the root",
+			input:    `{"data":{"user":{"ssn":"123"}}}`,
+			rootPath: "$.data",
+			relPaths: []string{"$.user.ssn"},
+			expected: `{"data":{"user":{"ssn":"[REDACTED]"}}}`,
+		},
+		{
+			name:     "Test with root of the subtree",
+			input:    `{"data":{"ssn":"123"},"other":1}`,
+			rootPath: "$.data",
+			relPaths: []string{"$"},
+			expected: `{"data":"[REDACTED]","other":1}`,
+		},
+		{
+			name:     "Test with recursive descent",
+			input:    `{"data":{"ssn":"1","a":[{"ssn":"2"}]},"ssn":"3"}`,
+			rootPath: "$.data",
+			relPaths: []string{"..ssn"},
+			expected: `{"data":{"ssn":"[REDACTED]","a":[{"ssn":"[REDACTED]"}]},"ssn":"3"}`,
+		},
+		{
+			name:     "Test with wildcard root",
+			input:    `{"items":[{"card":"4111"},"4111",{"card":"4222"}],"card":"4333"}`,
+			rootPath: "$.items[]",
+			relPaths: []string{"card"},
+			expected: `{"items":[{"card":"[REDACTED]"},"4111",{"card":"[REDACTED]"}],"card":"4333"}`,
+		},
+		{
+			name:     "Test with array index",
+			input:    `{"data":[{"ssn":"1"},{"ssn":"2"}]}`,
+			rootPath: "$.data",
+			relPaths: []string{"[1].ssn"},
+			expected: `{"data":[{"ssn":"1"},{"ssn":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "Test with keys and exclusions",
+			input:    `{"data":{"ids":{"acc1":1},"user":{"ssn":"1","name":"John"}}}`,
+			rootPath: "$.data",
+			relPaths: []string{"ids.*~", "user.*", "!user.name"},
+			expected: `{"data":{"ids":{"[REDACTED]":1},"user":{"ssn":"[REDACTED]","name":"John"}}}`,
+		},
+		{
+			name:     "Test with value matcher outside of the subtree",
+			input:    `{"data":{"card":"4111111111111111"},"card":"4111111111111111"}`,
+			rootPath: "$.data",
+			relPaths: []string{},
+			opts:     []option{WithValueMatcher(IsCreditCard)},
+			expected: `{"data":{"card":"[REDACTED]"},"card":"4111111111111111"}`,
+		},
+		{
+			name:     "Test with missing root",
+			input:    `{"other":{"ssn":"1"}}`,
+			rootPath: "$.data",
+			relPaths: []string{"ssn"},
+			expected: `{"other":{"ssn":"1"}}`,
+		},
+		{
+			name:     "Test with field name normalizer",
+			input:    `{"Data":{"User":{"SSN":"1"}}}`,
+			rootPath: "$.data",
+			relPaths: []string{"user.ssn"},
+			opts:     []option{WithFieldNameNormalizer(LowerCaseFieldName)},
+			expected: `{"Data":{"User":{"SSN":"[REDACTED]"}}}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masked, err := NewMasker([]string{"$..name"}, tt.opts...).MaskSubtree(tt.input, tt.rootPath, tt.relPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			opts := append([]option{WithRawPassthrough()}, tt.opts...)
+			masked, err = NewMasker([]string{"$..name"}, opts...).MaskSubtree(tt.input, tt.rootPath, tt.relPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+		})
+	}
+
+	t.Run("Test with strict paths", func(t *testing.T) {
+		_, err := NewMasker(nil, WithStrictPaths()).MaskSubtree(`{"data":{}}`, "$.data", []string{"ssn"})
+		assert.EqualError(t, err, "mask paths matched nothing: $.data.ssn")
+	})
+
+	t.Run("Test with invalid root path", func(t *testing.T) {
+		_, err := NewMasker(nil).MaskSubtree(`{}`, "$.data~", []string{"ssn"})
+		assert.EqualError(t, err, "invalid root path $.data~: root path must match values")
+		assert.True(t, errors.Is(err, ErrInvalidPath))
+		_, err = NewMasker(nil, WithJSONPointerPaths()).MaskSubtree(`{}`, "/data", []string{"/ssn"})
+		assert.True(t, errors.Is(err, ErrInvalidPath))
+	})
+}
@@ -0,0 +1,76 @@
+package masker
+
+// typeTagRule masks a path relative to the objects whose discriminator has a value, see WithTypeTagRule.
+type typeTagRule struct {
+	tagField, tagValue string
+	// pattern is the relative path rooted at the tagged object.
+	pattern pathPattern
+}
+
+// taggedPath is the pattern of a typeTagRule applying below a tagged object, base being the length of its path.
+type taggedPath struct {
+	base       int
+	pattern    pathPattern
+	normalizer func(string) string
+}
+
+// WithTypeTagRule masks maskPath in the objects whose tagField member is the string tagValue,
+// e.g. the data of the card elements of a polymorphic array:
+//
+//	WithTypeTagRule("type", "card", "data")
+//
+// masks data in {"type":"card","data":{...}} but not in {"type":"bank","data":{...}}.
+// maskPath is relative to the tagged object, e.g. "data.number" or "items[].number",
+// and may also be written from the root of the object, e.g. "$.data". Key paths aren't supported.
+// Tagged objects are found at any depth of the documents, the structs passed to MaskValue aside,
+// and MaskReader buffers the documents it masks to read their tags.
+func WithTypeTagRule(tagField string, tagValue string, maskPath string) option {
+	return withOptionPath(rootedPath(maskPath), func(m *masker, _ string, pattern pathPattern) {
+		if pattern.keys || pattern.exclude {
+			return
+		}
+		m.typeTags = append(m.typeTags, typeTagRule{tagField: tagField, tagValue: tagValue, pattern: pattern})
+	})
+}
+
+// taggedPaths returns the patterns of the type tag rules matching the object at path.
+func (m *masker) taggedPaths(obj *object, path nodePath) []taggedPath {
+	var tagged []taggedPath
+	for _, rule := range m.typeTags {
+		if tag, ok := m.member(obj, rule.tagField).(string); ok && tag == rule.tagValue {
+			tagged = append(tagged, taggedPath{base: len(path), pattern: rule.pattern, normalizer: m.normalizer})
+		}
+	}
+	return tagged
+}
+
+// member returns the value of the member key of obj, comparing the keys normalized if WithFieldNameNormalizer is used.
+func (m *masker) member(obj *object, key string) any {
+	if m.normalizer == nil {
+		if i, ok := obj.index[key]; ok {
+			return obj.values[i]
+		}
+		return nil
+	}
+	key = m.normalizer(key)
+	for i, k := range obj.keys {
+		if m.normalizer(k) == key {
+			return obj.values[i]
+		}
+	}
+	return nil
+}
+
+// matchesTagged checks if the node at path is matched by the type tag rules of the objects above it.
+func (s *maskState) matchesTagged(path nodePath) bool {
+	for _, tagged := range s.tagged {
+		if len(path) <= tagged.base {
+			continue
+		}
+		relative := append(rootPath(), path[tagged.base:]...)
+		if tagged.pattern.match(normalizeKeys(relative, tagged.normalizer)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package masker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTypeTagRule(t *testing.T) {
+	payments := `{"payments":[` +
+		`{"type":"card","data":{"number":"4111","expiry":"12/30"}},` +
+		`{"type":"bank","data":{"iban":"DE89"}},` +
+		`{"data":{"number":"5500"},"type":"card"}` +
+		`]}`
+
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:  "Test with heterogeneous array",
+			input: payments,
+			opts:  []option{WithTypeTagRule("type", "card", "data")},
+			expected: `{"payments":[` +
+				`{"type":"card","data":"[REDACTED]"},` +
+				`{"type":"bank","data":{"iban":"DE89"}},` +
+				`{"data":"[REDACTED]","type":"card"}` +
+				`]}`,
+		},
+		{
+			name:  "Test with nested relative path",
+			input: payments,
+			opts:  []option{WithTypeTagRule("type", "card", "data.number"), WithTypeTagRule("type", "bank", "$.data.iban")},
+			expected: `{"payments":[` +
+				`{"type":"card","data":{"number":"[REDACTED]","expiry":"12/30"}},` +
+				`{"type":"bank","data":{"iban":"[REDACTED]"}},` +
+				`{"data":{"number":"[REDACTED]"},"type":"card"}` +
+				`]}`,
+		},
+		{
+			name:     "Test with nested tagged objects",
+			input:    `{"type":"order","items":[{"type":"card","data":"1"},{"type":"gift","data":"2"}],"data":"3"}`,
+			opts:     []option{WithTypeTagRule("type", "card", "data"), WithTypeTagRule("type", "order", "items[0].data")},
+			expected: `{"type":"order","items":[{"type":"card","data":"[REDACTED]"},{"type":"gift","data":"2"}],"data":"3"}`,
+		},
+		{
+			name:     "Test with non string tag",
+			input:    `[{"type":1,"data":"1"},{"type":["card"],"data":"2"}]`,
+			opts:     []option{WithTypeTagRule("type", "1", "data"), WithTypeTagRule("type", "card", "data")},
+			expected: `[{"type":1,"data":"1"},{"type":["card"],"data":"2"}]`,
+		},
+		{
+			name:      "Test with exclusion",
+			input:     `[{"type":"card","data":{"number":"4111","brand":"visa"}}]`,
+			maskPaths: []string{"!$[].data.brand"},
+			opts:      []option{WithTypeTagRule("type", "card", "data")},
+			expected:  `[{"type":"card","data":{"number":"[REDACTED]","brand":"visa"}}]`,
+		},
+		{
+			name:     "Test with field name normalizer",
+			input:    `[{"Kind":"card","cardData":"1"}]`,
+			opts:     []option{WithTypeTagRule("kind", "card", "card_data"), WithFieldNameNormalizer(SnakeCaseFieldName)},
+			expected: `[{"Kind":"card","cardData":"[REDACTED]"}]`,
+		},
+		{
+			name:     "Test with array token",
+			input:    `{"type":"card","numbers":["1","2"]}`,
+			opts:     []option{WithTypeTagRule("type", "card", "numbers[*]"), WithArrayToken("[*]")},
+			expected: `{"type":"card","numbers":["[REDACTED]","[REDACTED]"]}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masker := NewMasker(tt.maskPaths, tt.opts...)
+			masked, err := masker.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+
+			var out bytes.Buffer
+			assert.NoError(t, masker.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	t.Run("Test with concurrency", func(t *testing.T) {
+		elements := make([]string, 300)
+		expected := make([]string, len(elements))
+		for i := range elements {
+			if i%2 == 0 {
+				elements[i], expected[i] = `{"type":"card","data":"x"}`, `{"type":"card","data":"[REDACTED]"}`
+			} else {
+				elements[i], expected[i] = `{"type":"bank","data":"x"}`, `{"type":"bank","data":"x"}`
+			}
+		}
+		input := `{"type":"card","list":[` + strings.Join(elements, ",") + `]}`
+		masked, err := NewMasker(nil, WithTypeTagRule("type", "card", "data"), WithConcurrency(4)).Mask(input)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"type":"card","list":[`+strings.Join(expected, ",")+`]}`, masked)
+	})
+
+	t.Run("Test with report", func(t *testing.T) {
+		_, paths, err := NewMasker(nil, WithTypeTagRule("type", "card", "data")).MaskWithReport(payments, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"$.payments[0].data", "$.payments[2].data"}, paths)
+	})
+}
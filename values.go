@@ -0,0 +1,85 @@
+package masker
+
+import "reflect"
+
+// WithValueMatcher masks every leaf value for which matcher returns true, whatever its path,
+// e.g. IsCreditCard masks the credit card numbers found anywhere in the documents.
+// Leaves are strings, booleans, numbers, as json.Number for JSON documents, and null, as nil.
+// Several matchers can be registered, a leaf being masked if any of them or any mask path matches it.
+func WithValueMatcher(matcher func(value any) bool) option {
+	return func(m *masker) {
+		m.valueMatchers = append(m.valueMatchers, matcher)
+	}
+}
+
+// IsEmail reports whether value is a string shaped like an email address, e.g. "john@example.com",
+// to be used with WithValueMatcher.
+func IsEmail(value any) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, _, ok = splitEmail(str)
+	return ok
+}
+
+// IsCreditCard reports whether value is a string of 13 to 19 digits passing the Luhn checksum,
+// like credit card numbers, to be used with WithValueMatcher. Digits may be grouped with spaces
+// or dashes, e.g. "4111 1111 1111 1111". Numbers aren't matched, as they can't hold card numbers
+// without losing leading zeros.
+func IsCreditCard(value any) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+	digits := make([]byte, 0, len(str))
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == ' ' || c == '-':
+		default:
+			return false
+		}
+	}
+	return len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits)
+}
+
+// luhnValid checks the Luhn checksum of a string of ASCII digits.
+func luhnValid(digits []byte) bool {
+	return luhnSum(digits)%10 == 0
+}
+
+// luhnSum returns the Luhn sum of a string of ASCII digits, the last one being the check digit.
+func luhnSum(digits []byte) int {
+	sum := 0
+	for i := range digits {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum
+}
+
+// matchesValue checks if the leaf value is matched by one of the value matchers.
+// value is invalid for null leaves.
+func (m *masker) matchesValue(value reflect.Value) bool {
+	if len(m.valueMatchers) == 0 {
+		return false
+	}
+	var v any
+	if value.IsValid() {
+		v = value.Interface()
+	}
+	for _, matcher := range m.valueMatchers {
+		if matcher(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCreditCard(t *testing.T) {
+	testTable := []struct {
+		name     string
+		value    any
+		expected bool
+	}{
+		{name: "visa", value: "4111111111111111", expected: true},
+		{name: "grouped with spaces", value: "4111 1111 1111 1111", expected: true},
+		{name: "grouped with dashes", value: "5500-0000-0000-0004", expected: true},
+		{name: "amex", value: "378282246310005", expected: true},
+		{name: "invalid checksum", value: "4111111111111112", expected: false},
+		{name: "too short", value: "411111111111", expected: false},
+		{name: "letters", value: "4111a11111111111", expected: false},
+		{name: "number", value: json.Number("4111111111111111"), expected: false},
+		{name: "null", value: nil, expected: false},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsCreditCard(tt.value))
+		})
+	}
+}
+
+func TestIsEmail(t *testing.T) {
+	assert.True(t, IsEmail("john.doe+tag@example.com"))
+	assert.False(t, IsEmail("john.doe"))
+	assert.False(t, IsEmail("john@localhost"))
+	assert.False(t, IsEmail(42))
+}
+
+func TestMask_valueMatcher(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		matchers  []func(value any) bool
+		expected  string
+	}{
+		{
+			name:     "Test with credit card outside the mask paths",
+			input:    `{"note":"4111 1111 1111 1111","items":[{"ref":"4242424242424242"},{"ref":"1234"}],"id":4111111111111111}`,
+			matchers: []func(value any) bool{IsCreditCard},
+			expected: `{"note":"[REDACTED]","items":[{"ref":"[REDACTED]"},{"ref":"1234"}],"id":4111111111111111}`,
+		},
+		{
+			name:      "Test with mask paths",
+			input:     `{"name":"John","contact":"john@example.com","card":"4111111111111111"}`,
+			maskPaths: []string{"$.name"},
+			matchers:  []func(value any) bool{IsEmail, IsCreditCard},
+			expected:  `{"name":"[REDACTED]","contact":"[REDACTED]","card":"[REDACTED]"}`,
+		},
+		{
+			name:  "Test with custom matcher",
+			input: `{"token":"eyJhbGciOiJIUzI1NiJ9.e30.abc","other":"x","none":null}`,
+			matchers: []func(value any) bool{func(value any) bool {
+				str, ok := value.(string)
+				return ok && strings.HasPrefix(str, "eyJ")
+			}},
+			expected: `{"token":"[REDACTED]","other":"x","none":null}`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []option
+			for _, matcher := range tt.matchers {
+				opts = append(opts, WithValueMatcher(matcher))
+			}
+			m := NewMasker(tt.maskPaths, opts...)
+			output, err := m.Mask(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			var out bytes.Buffer
+			assert.NoError(t, m.MaskReader(strings.NewReader(tt.input), &out, nil))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
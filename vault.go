@@ -0,0 +1,27 @@
+package masker
+
+// MaskWithVault masks the input JSON string like MaskWithPaths and additionally returns the original
+// values of the masked nodes by their concrete path, with their real array indexes, e.g. "$.items[3].card",
+// for privileged consumers to restore them. Values are as decoded from the input, json.Number for numbers
+// and map[string]any for objects. Masked keys are recorded under the path of their value with the ~ suffix,
+// e.g. "$.accounts.acc1~", the original key being the value.
+// The vault holds the data the masking hides and must be protected like the input.
+func (m *masker) MaskWithVault(input string, maskPaths []string) (string, map[string]any, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return "", nil, err
+	}
+	state.vault = make(map[string]any)
+	maskedBytes, err := m.mask([]byte(input), state)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(maskedBytes), state.vault, nil
+}
+
+// recordOriginal records the original value of a masked node for MaskWithVault.
+func (s *maskState) recordOriginal(path string, value any) {
+	if s.vault != nil {
+		s.vault[path] = value
+	}
+}
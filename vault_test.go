@@ -0,0 +1,130 @@
+package masker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// restore puts the original values of the vault back into the masked document.
+func restore(t *testing.T, masked string, vault map[string]any) string {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(masked))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	for path, value := range vault {
+		segments, err := parsePath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) == 1 {
+			doc = value
+			continue
+		}
+		parent := doc
+		for _, s := range segments[1 : len(segments)-1] {
+			if s.kind == indexSegment {
+				parent = parent.([]any)[s.index]
+			} else {
+				parent = parent.(map[string]any)[s.key]
+			}
+		}
+		if last := segments[len(segments)-1]; last.kind == indexSegment {
+			parent.([]any)[last.index] = value
+		} else {
+			parent.(map[string]any)[last.key] = value
+		}
+	}
+	restored, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(restored)
+}
+
+func TestMaskWithVault(t *testing.T) {
+	testTable := []struct {
+		name          string
+		input         string
+		maskPaths     []string
+		expected      string
+		expectedVault map[string]any
+	}{
+		{
+			name:          "Test with members",
+			input:         `{"name":"John","ssn":"123","age":30}`,
+			maskPaths:     []string{"$.ssn", "$.age"},
+			expected:      `{"name":"John","ssn":"[REDACTED]","age":"[REDACTED]"}`,
+			expectedVault: map[string]any{"$.ssn": "123", "$.age": json.Number("30")},
+		},
+		{
+			name:      "Test with nested arrays",
+			input:     `{"rows":[[{"v":1},{"v":2}],[{"v":3}]]}`,
+			maskPaths: []string{"$.rows[][].v"},
+			expected:  `{"rows":[[{"v":"[REDACTED]"},{"v":"[REDACTED]"}],[{"v":"[REDACTED]"}]]}`,
+			expectedVault: map[string]any{
+				"$.rows[0][0].v": json.Number("1"),
+				"$.rows[0][1].v": json.Number("2"),
+				"$.rows[1][0].v": json.Number("3"),
+			},
+		},
+		{
+			name:          "Test with object",
+			input:         `{"card":{"number":"4111","cvv":null}}`,
+			maskPaths:     []string{"$.card"},
+			expected:      `{"card":"[REDACTED]"}`,
+			expectedVault: map[string]any{"$.card": map[string]any{"number": "4111", "cvv": nil}},
+		},
+		{
+			name:          "Test with root",
+			input:         `[1,2]`,
+			maskPaths:     []string{"$"},
+			expected:      `"[REDACTED]"`,
+			expectedVault: map[string]any{"$": []any{json.Number("1"), json.Number("2")}},
+		},
+		{
+			name:          "Test without masked nodes",
+			input:         `{"name":"John"}`,
+			maskPaths:     []string{"$.ssn"},
+			expected:      `{"name":"John"}`,
+			expectedVault: map[string]any{},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masked, vault, err := NewMasker(nil).MaskWithVault(tt.input, tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, masked)
+			assert.Equal(t, tt.expectedVault, vault)
+			assert.JSONEq(t, tt.input, restore(t, masked, vault))
+		})
+	}
+
+	t.Run("Test with masked keys", func(t *testing.T) {
+		masked, vault, err := NewMasker(nil).MaskWithVault(`{"accounts":{"acc1":1}}`, []string{"$.accounts.*~"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"accounts":{"[REDACTED]":1}}`, masked)
+		assert.Equal(t, map[string]any{"$.accounts.acc1~": "acc1"}, vault)
+	})
+
+	t.Run("Test with concurrency", func(t *testing.T) {
+		input := largeArray(300)
+		masked, vault, err := NewMasker(nil, WithConcurrency(4)).MaskWithVault(input, []string{"$[].ssn"})
+		assert.NoError(t, err)
+		assert.Len(t, vault, 300)
+		assert.Equal(t, "299", vault["$[299].ssn"])
+		assert.JSONEq(t, input, restore(t, masked, vault))
+	})
+
+	t.Run("Test with invalid input", func(t *testing.T) {
+		_, vault, err := NewMasker(nil).MaskWithVault(`{`, []string{"$.a"})
+		assert.ErrorIs(t, err, ErrInvalidJSON)
+		assert.Nil(t, vault)
+	})
+}
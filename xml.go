@@ -0,0 +1,229 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// attributePrefix prefixes the attribute names in the paths of MaskXML, e.g. "$.user.@id".
+const attributePrefix = "@"
+
+var (
+	// xmlTextEscaper escapes character data, unlike xml.EscapeText keeping the whitespace between elements as it is.
+	xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	// xmlAttrEscaper escapes the values of attributes, written in double quotes.
+	xmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+)
+
+// xmlElement is an element of a document read by MaskXML, with its content in document order.
+type xmlElement struct {
+	start xml.StartElement
+	// content holds the xml.CharData, xml.Comment, xml.ProcInst, xml.Directive and *xmlElement of the element.
+	content []any
+}
+
+// MaskXML masks the input XML document based on the provided maskPaths, using the same paths
+// as for JSON documents: element names are keys, starting with the root element, e.g. "$.user.ssn"
+// for the ssn element of <user><ssn>123</ssn></user>, and the elements repeated among their siblings
+// are array elements, e.g. "$.users.user[].ssn" or "$.users.user[0].ssn", a single element being
+// matched without an index. Attributes are keys prefixed with "@", e.g. "$.user.@id".
+// Matched elements have their content, text and child elements alike, replaced with the masked text
+// content of the element, their attributes being kept unless a path matches them. Matched elements and
+// attributes are removed with WithDropMaskedFields.
+// Elements and attributes are matched by their name as written, with its namespace prefix, e.g. "soap:Body".
+// The document is written back as it is read, empty elements aside, which are written with a closing tag.
+// With WithDeepMaskSubtrees, the text of the elements below the matched elements is masked instead.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+func (m *masker) MaskXML(input []byte, maskPaths []string) ([]byte, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return nil, err
+	}
+	document, err := decodeXML(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := m.maskXMLContent(&buf, document.content, state, rootPath()); err != nil {
+		return nil, fmt.Errorf("failed to mask object: %w", err)
+	}
+	if err := state.unmatchedErr(); err != nil {
+		return nil, err
+	}
+	if err := m.checkOutputSize(buf.Len()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeXML reads the XML document into a tree, its top-level content being held by the returned element.
+func decodeXML(input []byte) (*xmlElement, error) {
+	dec := xml.NewDecoder(bytes.NewReader(input))
+	document := &xmlElement{}
+	stack := []*xmlElement{document}
+	for {
+		token, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{start: t.Copy()}
+			parent.content = append(parent.content, element)
+			stack = append(stack, element)
+		case xml.EndElement:
+			if len(stack) == 1 || t.Name != parent.start.Name {
+				return nil, fmt.Errorf("unexpected end element </%s>", xmlName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			parent.content = append(parent.content, xml.CopyToken(token))
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("element <%s> isn't closed", xmlName(stack[len(stack)-1].start.Name))
+	}
+	return document, nil
+}
+
+// maskXMLContent masks and writes the content of the element at path.
+func (m *masker) maskXMLContent(buf *bytes.Buffer, content []any, state *maskState, path nodePath) error {
+	counts := make(map[string]int)
+	for _, c := range content {
+		if element, ok := c.(*xmlElement); ok {
+			counts[xmlName(element.start.Name)]++
+		}
+	}
+	indexes := make(map[string]int)
+	for _, c := range content {
+		element, ok := c.(*xmlElement)
+		if !ok {
+			writeXMLToken(buf, c)
+			continue
+		}
+		name := xmlName(element.start.Name)
+		elementPath := path.key(name)
+		if counts[name] > 1 {
+			elementPath = elementPath.element(indexes[name], counts[name])
+			indexes[name]++
+		}
+		if err := m.maskXMLElement(buf, element, state, elementPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maskXMLElement masks and writes the element at path.
+func (m *masker) maskXMLElement(buf *bytes.Buffer, element *xmlElement, state *maskState, path nodePath) error {
+	m.log("Processing path", logActionProcess, path)
+	if err := checkDepth(path, m.maxDepth); err != nil {
+		return err
+	}
+	leaf := element.isLeaf()
+	isMasked := (state.matches(path) && (leaf || !m.isDeepMask)) ||
+		(leaf && m.masksLeaf(reflect.ValueOf(element.text()), state, path))
+	var masked any
+	if isMasked {
+		var err error
+		if masked, err = m.maskNode(reflect.ValueOf(element.text()), state, path); err != nil {
+			return err
+		}
+		if isDropped(masked) {
+			return nil
+		}
+	}
+
+	buf.WriteString("<" + xmlName(element.start.Name))
+	for _, attr := range element.start.Attr {
+		attrPath := path.key(attributePrefix + xmlName(attr.Name))
+		value := attr.Value
+		if state.matches(attrPath) || m.masksLeaf(reflect.ValueOf(value), state, attrPath) {
+			maskedAttr, err := m.maskNode(reflect.ValueOf(value), state, attrPath)
+			if err != nil {
+				return err
+			}
+			if isDropped(maskedAttr) {
+				continue
+			}
+			value = stringify(maskedAttr)
+		}
+		buf.WriteString(" " + xmlName(attr.Name) + `="`)
+		xmlAttrEscaper.WriteString(buf, value)
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+	if isMasked {
+		if masked != nil {
+			xmlTextEscaper.WriteString(buf, stringify(masked))
+		}
+	} else if err := m.maskXMLContent(buf, element.content, state, path); err != nil {
+		return err
+	}
+	buf.WriteString("</" + xmlName(element.start.Name) + ">")
+	return nil
+}
+
+// isLeaf checks if the element only holds text, for the leaves masked whatever the paths, see masksLeaves.
+func (e *xmlElement) isLeaf() bool {
+	for _, c := range e.content {
+		if _, ok := c.(*xmlElement); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// text returns the text content of the element and its descendants.
+func (e *xmlElement) text() string {
+	var sb strings.Builder
+	for _, c := range e.content {
+		switch t := c.(type) {
+		case xml.CharData:
+			sb.Write(t)
+		case *xmlElement:
+			sb.WriteString(t.text())
+		}
+	}
+	return sb.String()
+}
+
+// xmlName returns the name as written in the document, with its namespace prefix.
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// writeXMLToken writes a token other than an element as it is in the document.
+func writeXMLToken(buf *bytes.Buffer, token any) {
+	switch t := token.(type) {
+	case xml.CharData:
+		xmlTextEscaper.WriteString(buf, string(t))
+	case xml.Comment:
+		buf.WriteString("<!--")
+		buf.Write(t)
+		buf.WriteString("-->")
+	case xml.ProcInst:
+		buf.WriteString("<?" + t.Target)
+		if len(t.Inst) > 0 {
+			buf.WriteByte(' ')
+			buf.Write(t.Inst)
+		}
+		buf.WriteString("?>")
+	case xml.Directive:
+		buf.WriteString("<!")
+		buf.Write(t)
+		buf.WriteByte('>')
+	}
+}
@@ -0,0 +1,98 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskXML(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with nested element",
+			input:     "<?xml version=\"1.0\"?>\n<user>\n  <name>John</name>\n  <account><ssn>123-45</ssn></account>\n</user>",
+			maskPaths: []string{"$.user.account.ssn"},
+			expected:  "<?xml version=\"1.0\"?>\n<user>\n  <name>John</name>\n  <account><ssn>[REDACTED]</ssn></account>\n</user>",
+		},
+		{
+			name:      "Test with attribute",
+			input:     `<user id="42" role="admin"><name>John</name></user>`,
+			maskPaths: []string{"$.user.@id"},
+			expected:  `<user id="[REDACTED]" role="admin"><name>John</name></user>`,
+		},
+		{
+			name:      "Test with repeated elements",
+			input:     `<users><user><ssn>1</ssn></user><user><ssn>2</ssn></user><admin><ssn>3</ssn></admin></users>`,
+			maskPaths: []string{"$.users.user[].ssn", "$.users.admin.ssn"},
+			expected:  `<users><user><ssn>[REDACTED]</ssn></user><user><ssn>[REDACTED]</ssn></user><admin><ssn>[REDACTED]</ssn></admin></users>`,
+		},
+		{
+			name:      "Test with index",
+			input:     `<users><user>a</user><user>b</user></users>`,
+			maskPaths: []string{"$.users.user[1]"},
+			expected:  `<users><user>a</user><user>[REDACTED]</user></users>`,
+		},
+		{
+			name:      "Test with matched element holding elements",
+			input:     `<order><card type="visa"><number>4111</number><cvv>123</cvv></card></order>`,
+			maskPaths: []string{"$.order.card"},
+			expected:  `<order><card type="visa">[REDACTED]</card></order>`,
+		},
+		{
+			name:      "Test with recursive descent and namespaces",
+			input:     `<soap:Envelope xmlns:soap="urn:s"><soap:Body><token>t1</token><x><token>t2</token></x></soap:Body></soap:Envelope>`,
+			maskPaths: []string{"$..token"},
+			expected:  `<soap:Envelope xmlns:soap="urn:s"><soap:Body><token>[REDACTED]</token><x><token>[REDACTED]</token></x></soap:Body></soap:Envelope>`,
+		},
+		{
+			name:      "Test with escaping, comments and CDATA",
+			input:     `<a><!-- note --><b>x &amp; y</b><c><![CDATA[<secret>]]></c><d k="&quot;q&quot;"/></a>`,
+			maskPaths: []string{"$.a.c"},
+			opts:      []option{WithFixedMaskString("<masked>")},
+			expected:  `<a><!-- note --><b>x &amp; y</b><c>&lt;masked&gt;</c><d k="&quot;q&quot;"></d></a>`,
+		},
+		{
+			name:      "Test with dropped element and attribute",
+			input:     `<user id="1"><ssn>1</ssn><name>John</name></user>`,
+			maskPaths: []string{"$.user.ssn", "$.user.@id"},
+			opts:      []option{WithDropMaskedFields()},
+			expected:  `<user><name>John</name></user>`,
+		},
+		{
+			name:      "Test with deep masking",
+			input:     `<card><number>4111</number><cvv>123</cvv></card>`,
+			maskPaths: []string{"$.card"},
+			opts:      []option{WithDeepMaskSubtrees()},
+			expected:  `<card><number>[REDACTED]</number><cvv>[REDACTED]</cvv></card>`,
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			masked, err := NewMasker(tt.maskPaths, tt.opts...).MaskXML([]byte(tt.input), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(masked))
+		})
+	}
+
+	t.Run("Test with report", func(t *testing.T) {
+		masker := NewMasker(nil)
+		_, err := masker.MaskXML([]byte(`<a><b>1</b></a>`), []string{"$.a.c"})
+		assert.NoError(t, err)
+		_, err = NewMasker(nil, WithStrictPaths()).MaskXML([]byte(`<a><b>1</b></a>`), []string{"$.a.c"})
+		assert.EqualError(t, err, "mask paths matched nothing: $.a.c")
+	})
+
+	t.Run("Test with invalid XML", func(t *testing.T) {
+		_, err := NewMasker(nil).MaskXML([]byte(`<a><b></a>`), []string{"$.a"})
+		assert.EqualError(t, err, "failed to unmarshal input: unexpected end element </a>")
+		_, err = NewMasker(nil).MaskXML([]byte(`<a>`), []string{"$.a"})
+		assert.EqualError(t, err, "failed to unmarshal input: element <a> isn't closed")
+	})
+}
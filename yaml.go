@@ -0,0 +1,66 @@
+package masker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaskYAML masks the input YAML document based on the provided maskPaths, using the same
+// paths as for JSON documents, and returns the masked document encoded as YAML.
+// Mapping keys that aren't strings are matched like the non-string keys of Go maps,
+// e.g. "$.2" for the key 2. Mappings are masked like JSON objects, including by key mask paths,
+// WithConditionalMask and WithTypeTagRule. The masked document is indented with 2 spaces, its keys
+// are sorted and comments are dropped.
+// Every document of a stream of documents separated by "---" is masked, the masked documents
+// being separated the same way, and the paths of WithStrictPaths have to match in one of them.
+// A nil maskPaths falls back to the paths passed to NewMasker.
+func (m *masker) MaskYAML(input []byte, maskPaths []string) ([]byte, error) {
+	state, err := m.newMaskState(maskPaths)
+	if err != nil {
+		return nil, err
+	}
+	var documents []any
+	dec := yaml.NewDecoder(bytes.NewReader(input))
+	for {
+		var value any
+		if err := dec.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, withKind(ErrInvalidYAML, fmt.Errorf("failed to unmarshal input: %w", err))
+		}
+		documents = append(documents, value)
+	}
+	if documents == nil {
+		// an empty input is a null document
+		documents = []any{nil}
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, value := range documents {
+		masked, err := m.maskWithPaths(reflect.ValueOf(value), state, rootPath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to mask object: %w", err)
+		}
+		if isDropped(masked) {
+			masked = nil
+		}
+		if err := enc.Encode(masked); err != nil {
+			return nil, fmt.Errorf("failed to marshal masked object: %w", err)
+		}
+	}
+	if err := state.unmatchedErr(); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal masked object: %w", err)
+	}
+	if err := m.checkOutputSize(buf.Len()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
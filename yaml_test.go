@@ -0,0 +1,138 @@
+package masker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskYAML(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		expected  string
+	}{
+		{
+			name: "Test with nested field",
+			input: `database:
+  host: localhost
+  port: 5432
+  credentials:
+    user: admin
+    password: hunter2
+`,
+			maskPaths: []string{"$.database.credentials.password"},
+			expected: `database:
+  credentials:
+    password: '[REDACTED]'
+    user: admin
+  host: localhost
+  port: 5432
+`,
+		},
+		{
+			name: "Test with sequences",
+			input: `services:
+  - name: api
+    token: abc
+  - name: worker
+    token: def
+`,
+			maskPaths: []string{"$.services[].token"},
+			expected: `services:
+  - name: api
+    token: '[REDACTED]'
+  - name: worker
+    token: '[REDACTED]'
+`,
+		},
+		{
+			name: "Test with non-string keys",
+			input: `ports:
+  80: public
+  8080: admin
+flags:
+  true: enabled
+`,
+			maskPaths: []string{"$.ports.8080", "$.flags.true"},
+			expected: `flags:
+  true: '[REDACTED]'
+ports:
+  80: public
+  8080: '[REDACTED]'
+`,
+		},
+		{
+			name:      "Test with multiple documents",
+			input:     "token: a\nname: first\n---\ntoken: b\n---\n- token: c\n",
+			maskPaths: []string{"$.token", "$[].token"},
+			expected:  "name: first\ntoken: '[REDACTED]'\n---\ntoken: '[REDACTED]'\n---\n- token: '[REDACTED]'\n",
+		},
+		{
+			name:      "Test with empty input",
+			input:     "",
+			maskPaths: []string{"$.token"},
+			expected:  "null\n",
+		},
+		{
+			name:      "Test with recursive descent",
+			input:     "a:\n  secret: x\nb:\n  - secret: y\n",
+			maskPaths: []string{"$..secret"},
+			expected:  "a:\n  secret: '[REDACTED]'\nb:\n  - secret: '[REDACTED]'\n",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker(nil).MaskYAML([]byte(tt.input), tt.maskPaths)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+
+	t.Run("Test with invalid YAML", func(t *testing.T) {
+		_, err := NewMasker(nil).MaskYAML([]byte("a: [1"), []string{"$.a"})
+		assert.ErrorContains(t, err, "failed to unmarshal input: yaml:")
+		assert.ErrorIs(t, err, ErrInvalidYAML)
+	})
+}
+
+func TestMaskYAML_rules(t *testing.T) {
+	testTable := []struct {
+		name      string
+		input     string
+		maskPaths []string
+		opts      []option
+		expected  string
+	}{
+		{
+			name:      "Test with key paths",
+			input:     "accounts:\n  alice: 1\n  bob: 2\n",
+			maskPaths: []string{"$.accounts.alice~"},
+			expected:  "accounts:\n  '[REDACTED]': 1\n  bob: 2\n",
+		},
+		{
+			name:  "Test with conditional masks",
+			input: "payments:\n  - type: card\n    number: 4111\n  - type: bank\n    number: 12\n",
+			opts: []option{WithConditionalMask("$.payments[].number", func(node map[string]any) bool {
+				return node["type"] == "card"
+			})},
+			expected: "payments:\n  - number: '[REDACTED]'\n    type: card\n  - number: 12\n    type: bank\n",
+		},
+		{
+			name:     "Test with type tag rules",
+			input:    "items:\n  - type: card\n    data: 4111\n  - type: bank\n    data: 12\n",
+			opts:     []option{WithTypeTagRule("type", "card", "data")},
+			expected: "items:\n  - data: '[REDACTED]'\n    type: card\n  - data: 12\n    type: bank\n",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := NewMasker(tt.maskPaths, tt.opts...).MaskYAML([]byte(tt.input), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}